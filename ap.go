@@ -0,0 +1,533 @@
+//go:build linux
+// +build linux
+
+package wifi
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// IE IDs of the elements announcing a channel switch.
+const (
+	ieChannelSwitch         = 37
+	ieExtendedChannelSwitch = 60
+)
+
+// probeClientTimeout bounds how long ProbeClient waits for the kernel to
+// report whether its probe was acknowledged.
+const probeClientTimeout = 5 * time.Second
+
+// probeResponseIEOffset is the offset of the first IE in a probe response
+// frame: a 24 byte management header followed by the timestamp, beacon
+// interval and capability fields.
+const probeResponseIEOffset = 36
+
+// BeaconData holds the frames and IEs an AP interface beacons and responds
+// with, as built by the caller.
+type BeaconData struct {
+	// Head is the beacon frame up to the TIM element, and Tail the
+	// elements following it.
+	Head []byte
+	Tail []byte
+
+	// IEs are added to beacons, probe responses and association responses,
+	// and ProbeResponseIEs and AssocResponseIEs to only one of them.
+	IEs              []byte
+	ProbeResponseIEs []byte
+	AssocResponseIEs []byte
+
+	// ProbeResponse is an optional complete probe response frame, for
+	// drivers that offload probe responses.
+	ProbeResponse []byte
+}
+
+// attributes returns the NL80211_ATTR_BEACON_* and related attributes
+// describing b, omitting those that are empty.
+func (b *BeaconData) attributes() []AttributeEncoder {
+	fields := []struct {
+		typ uint16
+		val []byte
+	}{
+		{unix.NL80211_ATTR_BEACON_HEAD, b.Head},
+		{unix.NL80211_ATTR_BEACON_TAIL, b.Tail},
+		{unix.NL80211_ATTR_IE, b.IEs},
+		{unix.NL80211_ATTR_IE_PROBE_RESP, b.ProbeResponseIEs},
+		{unix.NL80211_ATTR_IE_ASSOC_RESP, b.AssocResponseIEs},
+		{unix.NL80211_ATTR_PROBE_RESP, b.ProbeResponse},
+	}
+
+	attrs := make([]AttributeEncoder, 0, len(fields))
+	for _, f := range fields {
+		if len(f.val) > 0 {
+			attrs = append(attrs, NewAttributeFactory[[]byte](f.typ)(f.val))
+		}
+	}
+	return attrs
+}
+
+// A HiddenSSID selects how an AP hides its SSID from its beacons.
+type HiddenSSID int
+
+const (
+	// HiddenSSIDOff broadcasts the SSID.
+	HiddenSSIDOff HiddenSSID = unix.NL80211_HIDDEN_SSID_NOT_IN_USE
+	// HiddenSSIDEmpty beacons an empty SSID.
+	HiddenSSIDEmpty HiddenSSID = unix.NL80211_HIDDEN_SSID_ZERO_LEN
+	// HiddenSSIDZeroed beacons an SSID of the right length made up of
+	// zero bytes, which some clients handle better.
+	HiddenSSIDZeroed HiddenSSID = unix.NL80211_HIDDEN_SSID_ZERO_CONTENTS
+)
+
+// String returns the string representation of a HiddenSSID.
+func (h HiddenSSID) String() string {
+	switch h {
+	case HiddenSSIDOff:
+		return "off"
+	case HiddenSSIDEmpty:
+		return "empty"
+	case HiddenSSIDZeroed:
+		return "zeroed"
+	default:
+		return fmt.Sprintf("unknown(%d)", h)
+	}
+}
+
+// beaconSSID returns the SSID element body beaconed for ssid.
+func (h HiddenSSID) beaconSSID(ssid string) []byte {
+	switch h {
+	case HiddenSSIDEmpty:
+		return nil
+	case HiddenSSIDZeroed:
+		return make([]byte, len(ssid))
+	default:
+		return []byte(ssid)
+	}
+}
+
+// Defaults of the APConfig fields left 0.
+const (
+	defaultBeaconInterval = 100
+	defaultDTIMPeriod     = 2
+)
+
+// APConfig describes the network an AP interface serves.
+type APConfig struct {
+	SSID       string
+	HiddenSSID HiddenSSID
+
+	// Channel is the channel to operate on.
+	Channel ChannelDefinition
+
+	// BeaconInterval is the time between beacons in TUs of 1024 µs, and
+	// DTIMPeriod the number of beacons between DTIMs. They default to 100
+	// and 2.
+	BeaconInterval int
+	DTIMPeriod     int
+
+	// Privacy is set for networks requiring encryption, whose RSN element
+	// must then be part of the beacon.
+	Privacy bool
+
+	// Rates and BasicRates are the rates in Mbps the built beacon
+	// advertises. They default to the legacy rates of the band of Channel.
+	Rates      []float64
+	BasicRates []float64
+
+	// Beacon is the beacon to send. When nil, it is built by BuildBeacon.
+	Beacon *BeaconData
+}
+
+// beaconInterval returns BeaconInterval, or its default if it is not set.
+func (cfg *APConfig) beaconInterval() int {
+	if cfg.BeaconInterval == 0 { return defaultBeaconInterval }
+	return cfg.BeaconInterval
+}
+
+// dtimPeriod returns DTIMPeriod, or its default if it is not set.
+func (cfg *APConfig) dtimPeriod() int {
+	if cfg.DTIMPeriod == 0 { return defaultDTIMPeriod }
+	return cfg.DTIMPeriod
+}
+
+// StartAP starts beaconing on the given interface, which must be of type
+// InterfaceTypeAP, serving the network described by cfg. Stations
+// authenticate with open system authentication.
+func (c *Client) StartAP(w *WifiInterface, cfg *APConfig) error {
+	attrs, err := startAPAttrs(w, cfg)
+	if err != nil { return fmt.Errorf("StartAP: %v", err) }
+
+	msg, err := NewNl80211Message(unix.NL80211_CMD_START_AP, attrs)
+	if err != nil { return fmt.Errorf("StartAP: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("StartAP: %w", err) }
+	return nil
+}
+
+// ErrAPNotRunning is returned by StopAP and SetBeacon when the interface
+// isn't beaconing.
+var ErrAPNotRunning = errors.New("AP not running")
+
+// StopAP stops beaconing on the given AP interface, disconnecting its
+// stations. If the interface isn't beaconing, the error wraps
+// ErrAPNotRunning.
+func (c *Client) StopAP(w *WifiInterface) error {
+	attrs := []AttributeEncoder{InterfaceIndexAttribute(w.Index)}
+	msg, err := NewNl80211Message(unix.NL80211_CMD_STOP_AP, attrs)
+	if err != nil { return fmt.Errorf("StopAP: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("StopAP: %w", apError(err)) }
+	return nil
+}
+
+// SetBeacon replaces the beacon and response IEs of a running AP without
+// restarting it, for instance to update a vendor-specific element. Only the
+// non-empty parts of b are changed. If the interface isn't beaconing, the
+// error wraps ErrAPNotRunning.
+func (c *Client) SetBeacon(w *WifiInterface, b *BeaconData) error {
+	beacon := b.attributes()
+	if len(beacon) == 0 { return errors.New("SetBeacon: empty beacon") }
+
+	attrs := append([]AttributeEncoder{InterfaceIndexAttribute(w.Index)}, beacon...)
+	msg, err := NewNl80211Message(unix.NL80211_CMD_SET_BEACON, attrs)
+	if err != nil { return fmt.Errorf("SetBeacon: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("SetBeacon: %w", apError(err)) }
+	return nil
+}
+
+// apError maps the errors the kernel returns for an interface that isn't
+// beaconing, which vary between kernel versions and drivers, to
+// ErrAPNotRunning.
+func apError(err error) error {
+	if errors.Is(err, unix.ENOTCONN) || errors.Is(err, unix.ENOENT) {
+		return fmt.Errorf("%w: %v", ErrAPNotRunning, err)
+	}
+	return err
+}
+
+// startAPAttrs returns the NL80211_CMD_START_AP attributes for cfg on the
+// given interface.
+func startAPAttrs(w *WifiInterface, cfg *APConfig) ([]AttributeEncoder, error) {
+	if err := cfg.Channel.validate(); err != nil { return nil, err }
+	if cfg.beaconInterval() < 0 || cfg.beaconInterval() > 0xffff { return nil, fmt.Errorf("invalid beacon interval %d", cfg.BeaconInterval) }
+	if cfg.dtimPeriod() < 1 || cfg.dtimPeriod() > 255 { return nil, fmt.Errorf("invalid DTIM period %d", cfg.DTIMPeriod) }
+
+	beacon := cfg.Beacon
+	if beacon == nil {
+		b, err := BuildBeacon(w.HardwareAddr, cfg)
+		if err != nil { return nil, err }
+		beacon = b
+	}
+	if len(beacon.Head) == 0 { return nil, errors.New("beacon has no head") }
+
+	attrs := []AttributeEncoder{
+		InterfaceIndexAttribute(w.Index),
+		SSIDAttribute([]byte(cfg.SSID)),
+		NewAttributeFactory[uint32](unix.NL80211_ATTR_HIDDEN_SSID)(uint32(cfg.HiddenSSID)),
+		NewAttributeFactory[uint32](unix.NL80211_ATTR_BEACON_INTERVAL)(uint32(cfg.beaconInterval())),
+		NewAttributeFactory[uint32](unix.NL80211_ATTR_DTIM_PERIOD)(uint32(cfg.dtimPeriod())),
+		AuthTypeAttribute(unix.NL80211_AUTHTYPE_OPEN_SYSTEM),
+	}
+	if cfg.Privacy {
+		attrs = append(attrs, PrivacyAttribute(true))
+	}
+	attrs = append(attrs, channelWidthEncoder(&cfg.Channel)...)
+	return append(attrs, beacon.attributes()...), nil
+}
+
+// ChannelSwitchOptions describe a channel switch of an AP interface.
+type ChannelSwitchOptions struct {
+	// Channel is the channel to switch to.
+	Channel ChannelDefinition
+
+	// Count is the number of beacons announcing the switch before it
+	// happens. With a count of 0 the switch happens immediately.
+	Count uint32
+
+	// BlockTx asks associated stations to stop transmitting until the
+	// switch is done.
+	BlockTx bool
+
+	// CSABeacon is beaconed while the switch is announced, and must carry
+	// a Channel Switch Announcement or Extended Channel Switch
+	// Announcement element in its Tail. Its switch count is updated by the
+	// kernel with every beacon.
+	CSABeacon BeaconData
+
+	// Beacon is beaconed on the new channel once the switch is done.
+	Beacon BeaconData
+}
+
+// SwitchChannel moves the given AP interface to a new channel, announcing
+// the switch in its beacons beforehand so that associated stations follow
+// it rather than disconnecting.
+func (c *Client) SwitchChannel(w *WifiInterface, opts *ChannelSwitchOptions) error {
+	attrs, err := channelSwitchAttrs(opts)
+	if err != nil { return fmt.Errorf("SwitchChannel: %v", err) }
+	attrs = append([]AttributeEncoder{InterfaceIndexAttribute(w.Index)}, attrs...)
+
+	msg, err := NewNl80211Message(unix.NL80211_CMD_CHANNEL_SWITCH, attrs)
+	if err != nil { return fmt.Errorf("SwitchChannel: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("SwitchChannel: %w", err) }
+	return nil
+}
+
+// channelSwitchAttrs returns the NL80211_CMD_CHANNEL_SWITCH attributes for
+// opts. The offsets of the switch counters the kernel must update are found
+// from the announcement elements in the CSA beacon.
+func channelSwitchAttrs(opts *ChannelSwitchOptions) ([]AttributeEncoder, error) {
+	if err := opts.Channel.validate(); err != nil { return nil, err }
+
+	attrs := channelWidthEncoder(&opts.Channel)
+	attrs = append(attrs, NewAttributeFactory[uint32](unix.NL80211_ATTR_CH_SWITCH_COUNT)(opts.Count))
+	if opts.BlockTx {
+		attrs = append(attrs, NewAttributeFactory[bool](unix.NL80211_ATTR_CH_SWITCH_BLOCK_TX)(true))
+	}
+	attrs = append(attrs, opts.Beacon.attributes()...)
+
+	if opts.Count == 0 { return attrs, nil }
+
+	beaconOffsets := csaCounterOffsets(opts.CSABeacon.Tail, 0)
+	if len(beaconOffsets) == 0 {
+		return nil, errors.New("CSA beacon tail has no channel switch announcement element")
+	}
+	attrs = append(attrs,
+		NestedAttribute(unix.NL80211_ATTR_CSA_IES, opts.CSABeacon.attributes()...),
+		NewAttributeFactory[[]byte](unix.NL80211_ATTR_CSA_C_OFF_BEACON)(counterOffsetBytes(beaconOffsets)),
+	)
+
+	if presp := opts.CSABeacon.ProbeResponse; len(presp) > probeResponseIEOffset {
+		prespOffsets := csaCounterOffsets(presp[probeResponseIEOffset:], probeResponseIEOffset)
+		if len(prespOffsets) > 0 {
+			attrs = append(attrs, NewAttributeFactory[[]byte](unix.NL80211_ATTR_CSA_C_OFF_PRESP)(counterOffsetBytes(prespOffsets)))
+		}
+	}
+	return attrs, nil
+}
+
+// csaCounterOffsets returns the offsets of the switch count fields of the
+// channel switch announcement elements in b, a sequence of IEs found at
+// offset base of its frame or template.
+func csaCounterOffsets(b []byte, base int) []uint16 {
+	var offsets []uint16
+	for i := 0; i+2 <= len(b); {
+		id, length := b[i], int(b[i+1])
+		if i+2+length > len(b) { break }
+
+		switch {
+		case id == ieChannelSwitch && length >= 3:
+			// Switch mode, new channel, switch count.
+			offsets = append(offsets, uint16(base+i+2+2))
+		case id == ieExtendedChannelSwitch && length >= 4:
+			// Switch mode, new operating class, new channel, switch count.
+			offsets = append(offsets, uint16(base+i+2+3))
+		}
+		i += 2 + length
+	}
+	return offsets
+}
+
+// counterOffsetBytes encodes counter offsets as the array of u16 values
+// nl80211 expects.
+func counterOffsetBytes(offsets []uint16) []byte {
+	b := make([]byte, 0, 2*len(offsets))
+	for _, o := range offsets {
+		b = append(b, nlenc.Uint16Bytes(o)...)
+	}
+	return b
+}
+
+// A ProbeClientEvent reports whether a station probed with ProbeClient
+// acknowledged the probe.
+type ProbeClientEvent struct {
+	InterfaceIndex uint32
+	WiphyIndex     uint32
+	HardwareAddr   net.HardwareAddr
+
+	// Cookie identifies the probe.
+	Cookie uint64
+
+	// Acked is set when the station acknowledged the probe.
+	Acked bool
+}
+
+// ProbeClient sends a null data frame to a station associated with the given
+// AP interface and reports whether the station acknowledged it, which shows
+// whether the station is still in range without waiting for its inactivity
+// timeout.
+func (c *Client) ProbeClient(w *WifiInterface, mac net.HardwareAddr) (bool, error) {
+	// The outcome is reported on the "mlme" group, which is joined first
+	// so that it can't be missed.
+	conn, err := c.eventConn("mlme")
+	if err != nil { return false, fmt.Errorf("ProbeClient: %v", err) }
+	defer c.closeEventConn(conn)
+
+	attrs := []AttributeEncoder{
+		InterfaceIndexAttribute(w.Index),
+		MacAttribute(mac),
+	}
+	msg, err := NewNl80211Message(unix.NL80211_CMD_PROBE_CLIENT, attrs)
+	if err != nil { return false, fmt.Errorf("ProbeClient: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request,
+	}
+	response, err := request.Response(c)
+	if err != nil { return false, fmt.Errorf("ProbeClient: %w", err) }
+
+	cookie, err := parseCookie(response)
+	if err != nil { return false, fmt.Errorf("ProbeClient: %v", err) }
+
+	ev, err := waitForProbeClient(conn, cookie)
+	if err != nil { return false, fmt.Errorf("ProbeClient: %w", err) }
+	return ev.Acked, nil
+}
+
+// waitForProbeClient waits for the NL80211_CMD_PROBE_CLIENT notification
+// reporting the outcome of the probe identified by cookie.
+func waitForProbeClient(conn *genetlink.Conn, cookie uint64) (*ProbeClientEvent, error) {
+	if err := conn.SetReadDeadline(time.Now().Add(probeClientTimeout)); err != nil { return nil, err }
+
+	for {
+		msgs, _, err := conn.Receive()
+		if err != nil { return nil, err }
+
+		for _, m := range msgs {
+			if m.Header.Command != unix.NL80211_CMD_PROBE_CLIENT { continue }
+
+			ev, err := parseProbeClientEvent(m)
+			if err != nil { return nil, err }
+			if ev.Cookie == cookie {
+				return ev, nil
+			}
+		}
+	}
+}
+
+// parseProbeClientEvent parses a NL80211_CMD_PROBE_CLIENT notification.
+func parseProbeClientEvent(m genetlink.Message) (*ProbeClientEvent, error) {
+	attrs, err := netlink.UnmarshalAttributes(m.Data)
+	if err != nil { return nil, fmt.Errorf("parseProbeClientEvent: %v", err) }
+
+	ev := &ProbeClientEvent{}
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_ATTR_IFINDEX:
+			ev.InterfaceIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_WIPHY:
+			ev.WiphyIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_MAC:
+			ev.HardwareAddr = net.HardwareAddr(a.Data)
+		case unix.NL80211_ATTR_COOKIE:
+			ev.Cookie = nlenc.Uint64(a.Data)
+		case unix.NL80211_ATTR_ACK:
+			ev.Acked = true
+		}
+	}
+	return ev, nil
+}
+
+// An ACLPolicy decides how an AP treats the stations listed in its MAC
+// access control list.
+type ACLPolicy int
+
+const (
+	// ACLPolicyDeny rejects the listed stations and accepts all others.
+	ACLPolicyDeny ACLPolicy = unix.NL80211_ACL_POLICY_ACCEPT_UNLESS_LISTED
+	// ACLPolicyAccept accepts only the listed stations.
+	ACLPolicyAccept ACLPolicy = unix.NL80211_ACL_POLICY_DENY_UNLESS_LISTED
+)
+
+// String returns the string representation of an ACLPolicy.
+func (p ACLPolicy) String() string {
+	switch p {
+	case ACLPolicyDeny:
+		return "deny"
+	case ACLPolicyAccept:
+		return "accept"
+	default:
+		return fmt.Sprintf("unknown(%d)", p)
+	}
+}
+
+// SetMACACL installs a MAC access control list on the given AP interface,
+// enforced by the driver. The number of addresses is limited by
+// Wiphy.MaxACLEntries, and drivers that report no limit don't support
+// access control lists. An empty list with ACLPolicyDeny clears the list,
+// accepting all stations.
+func (c *Client) SetMACACL(w *WifiInterface, policy ACLPolicy, macs []net.HardwareAddr) error {
+	wiphy, err := c.Wiphy(w)
+	if err != nil { return fmt.Errorf("SetMACACL: %v", err) }
+
+	attrs, err := macACLAttrs(w, policy, macs, wiphy.MaxACLEntries)
+	if err != nil { return fmt.Errorf("SetMACACL: %v", err) }
+
+	msg, err := NewNl80211Message(unix.NL80211_CMD_SET_MAC_ACL, attrs)
+	if err != nil { return fmt.Errorf("SetMACACL: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("SetMACACL: %w", err) }
+	return nil
+}
+
+// ClearMACACL removes the MAC access control list of the given AP
+// interface, accepting all stations.
+func (c *Client) ClearMACACL(w *WifiInterface) error {
+	return c.SetMACACL(w, ACLPolicyDeny, nil)
+}
+
+// macACLAttrs returns the NL80211_CMD_SET_MAC_ACL attributes for the given
+// policy and addresses, checking them against the maximum number of entries
+// the device supports. The kernel requires NL80211_ATTR_MAC_ADDRS even when
+// it's empty.
+func macACLAttrs(w *WifiInterface, policy ACLPolicy, macs []net.HardwareAddr, max int) ([]AttributeEncoder, error) {
+	if policy != ACLPolicyDeny && policy != ACLPolicyAccept { return nil, fmt.Errorf("invalid ACL policy %v", policy) }
+	if max == 0 { return nil, errors.New("driver does not support MAC access control lists") }
+	if len(macs) > max { return nil, fmt.Errorf("%d addresses exceed the maximum of %d", len(macs), max) }
+
+	addrs := make([]AttributeEncoder, 0, len(macs))
+	for i, mac := range macs {
+		if len(mac) != 6 { return nil, fmt.Errorf("invalid hardware address %v", mac) }
+		addrs = append(addrs, NewAttributeFactory[[]byte](uint16(i+1))([]byte(mac)))
+	}
+	return []AttributeEncoder{
+		InterfaceIndexAttribute(w.Index),
+		NewAttributeFactory[uint32](unix.NL80211_ATTR_ACL_POLICY)(uint32(policy)),
+		NestedAttribute(unix.NL80211_ATTR_MAC_ADDRS, addrs...),
+	}, nil
+}
@@ -0,0 +1,238 @@
+package wifi_test
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/bryancoxwell/wifi"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// TestChannelSwitchAttrs tests the attributes of a channel switch, in
+// particular the counter offsets found from the announcement elements.
+func TestChannelSwitchAttrs(t *testing.T) {
+	csaTail := []byte{
+		42, 1, 0x00, // ERP
+		37, 3, 0x01, 100, 10, // Channel Switch Announcement
+		60, 4, 0x01, 128, 100, 10, // Extended Channel Switch Announcement
+	}
+	presp := make([]byte, 36)
+	presp = append(presp, 0, 4, 'h', 'o', 'm', 'e')
+	presp = append(presp, 37, 3, 0x01, 100, 10)
+
+	opts := &wifi.ChannelSwitchOptions{
+		Channel: wifi.ChannelDefinition{Frequency: 5500, Width: wifi.ChannelWidth80, CenterFrequency1: 5530},
+		Count:   10,
+		BlockTx: true,
+		CSABeacon: wifi.BeaconData{
+			Head:          []byte{0x80, 0x00},
+			Tail:          csaTail,
+			ProbeResponse: presp,
+		},
+		Beacon: wifi.BeaconData{
+			Head: []byte{0x80, 0x00},
+			Tail: []byte{42, 1, 0x00},
+		},
+	}
+	encoders, err := wifi.ChannelSwitchAttrs(opts)
+	if err != nil {
+		t.Fatalf("ChannelSwitchAttrs: %v", err)
+	}
+	attrs := encodeAttributes(t, encoders)
+
+	expected := map[uint16][]byte{
+		unix.NL80211_ATTR_WIPHY_FREQ:         nlenc.Uint32Bytes(5500),
+		unix.NL80211_ATTR_CHANNEL_WIDTH:      nlenc.Uint32Bytes(unix.NL80211_CHAN_WIDTH_80),
+		unix.NL80211_ATTR_CENTER_FREQ1:       nlenc.Uint32Bytes(5530),
+		unix.NL80211_ATTR_CH_SWITCH_COUNT:    nlenc.Uint32Bytes(10),
+		unix.NL80211_ATTR_CH_SWITCH_BLOCK_TX: {},
+		unix.NL80211_ATTR_BEACON_HEAD:        {0x80, 0x00},
+		unix.NL80211_ATTR_BEACON_TAIL:        {42, 1, 0x00},
+		unix.NL80211_ATTR_CSA_C_OFF_BEACON:   append(nlenc.Uint16Bytes(7), nlenc.Uint16Bytes(13)...),
+		unix.NL80211_ATTR_CSA_C_OFF_PRESP:    nlenc.Uint16Bytes(46),
+	}
+	for typ, data := range expected {
+		got, ok := attrs[typ]
+		if !ok {
+			t.Errorf("missing attribute %d", typ)
+			continue
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("attribute %d = %v, expected %v", typ, got, data)
+		}
+	}
+
+	csa := decodeNested(t, attrs[unix.NL80211_ATTR_CSA_IES])
+	if !bytes.Equal(csa[unix.NL80211_ATTR_BEACON_TAIL], csaTail) || !bytes.Equal(csa[unix.NL80211_ATTR_PROBE_RESP], presp) {
+		t.Errorf("unexpected NL80211_ATTR_CSA_IES %v", csa)
+	}
+
+	opts.CSABeacon.Tail = []byte{42, 1, 0x00}
+	if _, err := wifi.ChannelSwitchAttrs(opts); err == nil {
+		t.Errorf("ChannelSwitchAttrs: expected error for a CSA beacon without announcement")
+	}
+}
+
+// decodeNested decodes nested attributes keyed by type.
+func decodeNested(t *testing.T, b []byte) map[uint16][]byte {
+	t.Helper()
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil {
+		t.Fatalf("failed to decode attributes: %v", err)
+	}
+	m := make(map[uint16][]byte, len(attrs))
+	for _, a := range attrs {
+		m[a.Type&^unix.NLA_F_NESTED] = a.Data
+	}
+	return m
+}
+
+// TestBuildBeacon tests the beacon built for a simple open 2.4 GHz AP.
+func TestBuildBeacon(t *testing.T) {
+	bssid := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x01, 0x00}
+	cfg := &wifi.APConfig{
+		SSID:    "setup",
+		Channel: wifi.ChannelDefinition{Frequency: 2437, Width: wifi.ChannelWidth20NoHT},
+	}
+	beacon, err := wifi.BuildBeacon(bssid, cfg)
+	if err != nil {
+		t.Fatalf("BuildBeacon: %v", err)
+	}
+
+	head := beacon.Head
+	if len(head) < 36 || head[0] != 0x80 || !bytes.Equal(head[16:22], bssid) {
+		t.Fatalf("unexpected beacon header: %x", head)
+	}
+	if interval := nlenc.Uint16(head[32:34]); interval != 100 {
+		t.Errorf("unexpected beacon interval %d", interval)
+	}
+
+	ies, err := wifi.ParseIEs(append(head[36:], beacon.Tail...))
+	if err != nil {
+		t.Fatalf("ParseIEs: %v", err)
+	}
+	bss := &wifi.BSS{Frequency: 2437, IEs: ies}
+	if ssid := string(ies[0].Data); ssid != "setup" {
+		t.Errorf("unexpected SSID %q", ssid)
+	}
+	if ch := bss.Channel(); ch != 6 {
+		t.Errorf("unexpected channel %d", ch)
+	}
+	if rates := bss.SupportedRates(); len(rates) != 12 {
+		t.Errorf("unexpected rates %v", rates)
+	}
+	if basic := bss.BasicRates(); !reflect.DeepEqual(basic, []float64{1, 2, 5.5, 11}) {
+		t.Errorf("unexpected basic rates %v", basic)
+	}
+
+	cfg.HiddenSSID = wifi.HiddenSSIDZeroed
+	beacon, err = wifi.BuildBeacon(bssid, cfg)
+	if err != nil {
+		t.Fatalf("BuildBeacon: %v", err)
+	}
+	if ssid := beacon.Head[36:43]; !bytes.Equal(ssid, []byte{0, 5, 0, 0, 0, 0, 0}) {
+		t.Errorf("unexpected hidden SSID element %v", ssid)
+	}
+}
+
+// TestStartAPAttrs tests the attributes starting an AP, with defaults
+// applied.
+func TestStartAPAttrs(t *testing.T) {
+	w := &wifi.WifiInterface{Index: 3, HardwareAddr: net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x01, 0x00}}
+	cfg := &wifi.APConfig{
+		SSID:    "setup",
+		Channel: wifi.ChannelDefinition{Frequency: 5180, Width: wifi.ChannelWidth20},
+		Beacon:  &wifi.BeaconData{Head: []byte{0x80, 0x00}, Tail: []byte{42, 1, 0x00}},
+	}
+	encoders, err := wifi.StartAPAttrs(w, cfg)
+	if err != nil {
+		t.Fatalf("StartAPAttrs: %v", err)
+	}
+	attrs := encodeAttributes(t, encoders)
+
+	expected := map[uint16][]byte{
+		unix.NL80211_ATTR_IFINDEX:         nlenc.Uint32Bytes(3),
+		unix.NL80211_ATTR_SSID:            []byte("setup"),
+		unix.NL80211_ATTR_HIDDEN_SSID:     nlenc.Uint32Bytes(unix.NL80211_HIDDEN_SSID_NOT_IN_USE),
+		unix.NL80211_ATTR_BEACON_INTERVAL: nlenc.Uint32Bytes(100),
+		unix.NL80211_ATTR_DTIM_PERIOD:     nlenc.Uint32Bytes(2),
+		unix.NL80211_ATTR_AUTH_TYPE:       nlenc.Uint32Bytes(unix.NL80211_AUTHTYPE_OPEN_SYSTEM),
+		unix.NL80211_ATTR_WIPHY_FREQ:      nlenc.Uint32Bytes(5180),
+		unix.NL80211_ATTR_BEACON_HEAD:     {0x80, 0x00},
+		unix.NL80211_ATTR_BEACON_TAIL:     {42, 1, 0x00},
+	}
+	for typ, data := range expected {
+		if got := attrs[typ]; !bytes.Equal(got, data) {
+			t.Errorf("attribute %d = %v, expected %v", typ, got, data)
+		}
+	}
+	if _, ok := attrs[unix.NL80211_ATTR_PRIVACY]; ok {
+		t.Errorf("unexpected NL80211_ATTR_PRIVACY for an open network")
+	}
+
+	cfg.DTIMPeriod = 256
+	if _, err := wifi.StartAPAttrs(w, cfg); err == nil {
+		t.Errorf("StartAPAttrs: expected error for an invalid DTIM period")
+	}
+}
+
+// TestAPError tests that the errors for an interface that isn't beaconing
+// wrap ErrAPNotRunning, and that other errors don't.
+func TestAPError(t *testing.T) {
+	for _, err := range []error{unix.ENOTCONN, unix.ENOENT} {
+		if !errors.Is(wifi.APError(err), wifi.ErrAPNotRunning) {
+			t.Errorf("APError(%v) does not wrap ErrAPNotRunning", err)
+		}
+	}
+	if errors.Is(wifi.APError(unix.EBUSY), wifi.ErrAPNotRunning) {
+		t.Errorf("APError(EBUSY) unexpectedly wraps ErrAPNotRunning")
+	}
+}
+
+// TestMacACLAttrs tests the encoding of a MAC access control list and its
+// validation against the device's maximum number of entries.
+func TestMacACLAttrs(t *testing.T) {
+	w := &wifi.WifiInterface{Index: 3, Type: wifi.InterfaceTypeAP}
+	macs := []net.HardwareAddr{
+		{0x02, 0x00, 0x00, 0x00, 0x01, 0x00},
+		{0x02, 0x00, 0x00, 0x00, 0x02, 0x00},
+	}
+
+	encoders, err := wifi.MacACLAttrs(w, wifi.ACLPolicyAccept, macs, 2)
+	if err != nil {
+		t.Fatalf("MacACLAttrs: %v", err)
+	}
+	attrs := encodeAttributes(t, encoders)
+	if got := nlenc.Uint32(attrs[unix.NL80211_ATTR_ACL_POLICY]); got != unix.NL80211_ACL_POLICY_DENY_UNLESS_LISTED {
+		t.Errorf("unexpected ACL policy %d", got)
+	}
+	addrs := decodeNested(t, attrs[unix.NL80211_ATTR_MAC_ADDRS])
+	if len(addrs) != 2 || !bytes.Equal(addrs[1], macs[0]) || !bytes.Equal(addrs[2], macs[1]) {
+		t.Errorf("unexpected addresses %v", addrs)
+	}
+
+	// Clearing the list still sends an empty NL80211_ATTR_MAC_ADDRS.
+	encoders, err = wifi.MacACLAttrs(w, wifi.ACLPolicyDeny, nil, 2)
+	if err != nil {
+		t.Fatalf("MacACLAttrs: %v", err)
+	}
+	attrs = encodeAttributes(t, encoders)
+	if got, ok := attrs[unix.NL80211_ATTR_MAC_ADDRS]; !ok || len(got) != 0 {
+		t.Errorf("expected an empty address list, got %v", got)
+	}
+
+	if _, err := wifi.MacACLAttrs(w, wifi.ACLPolicyAccept, macs, 1); err == nil {
+		t.Error("expected an error for too many addresses")
+	}
+	if _, err := wifi.MacACLAttrs(w, wifi.ACLPolicyAccept, nil, 0); err == nil {
+		t.Error("expected an error for a device without ACL support")
+	}
+	if _, err := wifi.MacACLAttrs(w, wifi.ACLPolicyAccept, []net.HardwareAddr{macs[0][:5]}, 2); err == nil {
+		t.Error("expected an error for a short hardware address")
+	}
+}
@@ -2,6 +2,7 @@ package wifi
 
 import (
 	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
 	"golang.org/x/sys/unix"
 )
 
@@ -96,4 +97,298 @@ func InterfaceNameAttribute(name string) *Attribute[string] {
 func WiphyAttribute(id uint32) *Attribute[uint32] {
 	factory := NewAttributeFactory[uint32](unix.NL80211_ATTR_WIPHY)
 	return factory(id)
-}
\ No newline at end of file
+}
+
+// PowerSaveStateAttribute returns a pointer to an *Attribute[uint32]
+// containing a valid NL80211_ATTR_PS_STATE value
+func PowerSaveStateAttribute(enabled bool) *Attribute[uint32] {
+	factory := NewAttributeFactory[uint32](unix.NL80211_ATTR_PS_STATE)
+	if enabled {
+		return factory(unix.NL80211_PS_ENABLED)
+	}
+	return factory(unix.NL80211_PS_DISABLED)
+}
+
+// SSIDAttribute returns a pointer to an *Attribute[[]byte]
+// containing a valid NL80211_ATTR_SSID value
+func SSIDAttribute(ssid []byte) *Attribute[[]byte] {
+	factory := NewAttributeFactory[[]byte](unix.NL80211_ATTR_SSID)
+	return factory(ssid)
+}
+
+// MeshIDAttribute returns a pointer to an *Attribute[[]byte]
+// containing a valid NL80211_ATTR_MESH_ID value
+func MeshIDAttribute(meshID []byte) *Attribute[[]byte] {
+	factory := NewAttributeFactory[[]byte](unix.NL80211_ATTR_MESH_ID)
+	return factory(meshID)
+}
+
+// AuthTypeAttribute returns a pointer to an *Attribute[uint32]
+// containing a valid NL80211_ATTR_AUTH_TYPE value
+func AuthTypeAttribute(authType uint32) *Attribute[uint32] {
+	factory := NewAttributeFactory[uint32](unix.NL80211_ATTR_AUTH_TYPE)
+	return factory(authType)
+}
+
+// WPAVersionsAttribute returns a pointer to an *Attribute[uint32]
+// containing a valid NL80211_ATTR_WPA_VERSIONS value
+func WPAVersionsAttribute(versions uint32) *Attribute[uint32] {
+	factory := NewAttributeFactory[uint32](unix.NL80211_ATTR_WPA_VERSIONS)
+	return factory(versions)
+}
+
+// AKMSuitesAttribute returns a pointer to an *Attribute[[]byte]
+// containing a valid NL80211_ATTR_AKM_SUITES value
+func AKMSuitesAttribute(suites ...uint32) *Attribute[[]byte] {
+	factory := NewAttributeFactory[[]byte](unix.NL80211_ATTR_AKM_SUITES)
+	return factory(suiteBytes(suites))
+}
+
+// PairwiseCipherSuitesAttribute returns a pointer to an *Attribute[[]byte]
+// containing a valid NL80211_ATTR_CIPHER_SUITES_PAIRWISE value
+func PairwiseCipherSuitesAttribute(suites ...uint32) *Attribute[[]byte] {
+	factory := NewAttributeFactory[[]byte](unix.NL80211_ATTR_CIPHER_SUITES_PAIRWISE)
+	return factory(suiteBytes(suites))
+}
+
+// GroupCipherSuiteAttribute returns a pointer to an *Attribute[uint32]
+// containing a valid NL80211_ATTR_CIPHER_SUITE_GROUP value
+func GroupCipherSuiteAttribute(suite uint32) *Attribute[uint32] {
+	factory := NewAttributeFactory[uint32](unix.NL80211_ATTR_CIPHER_SUITE_GROUP)
+	return factory(suite)
+}
+
+// PrivacyAttribute returns a pointer to an *Attribute[bool]
+// containing a valid NL80211_ATTR_PRIVACY flag
+func PrivacyAttribute(enabled bool) *Attribute[bool] {
+	factory := NewAttributeFactory[bool](unix.NL80211_ATTR_PRIVACY)
+	return factory(enabled)
+}
+
+// Want4WayHandshakeAttribute returns a pointer to an *Attribute[bool]
+// containing a valid NL80211_ATTR_WANT_1X_4WAY_HS flag
+func Want4WayHandshakeAttribute(enabled bool) *Attribute[bool] {
+	factory := NewAttributeFactory[bool](unix.NL80211_ATTR_WANT_1X_4WAY_HS)
+	return factory(enabled)
+}
+
+// PMKAttribute returns a pointer to an *Attribute[[]byte]
+// containing a valid NL80211_ATTR_PMK value
+func PMKAttribute(pmk []byte) *Attribute[[]byte] {
+	factory := NewAttributeFactory[[]byte](unix.NL80211_ATTR_PMK)
+	return factory(pmk)
+}
+
+// SAEPasswordAttribute returns a pointer to an *Attribute[[]byte]
+// containing a valid NL80211_ATTR_SAE_PASSWORD value
+func SAEPasswordAttribute(password []byte) *Attribute[[]byte] {
+	factory := NewAttributeFactory[[]byte](unix.NL80211_ATTR_SAE_PASSWORD)
+	return factory(password)
+}
+
+// MFPAttribute returns a pointer to an *Attribute[uint32]
+// containing a valid NL80211_ATTR_USE_MFP value
+func MFPAttribute(mfp uint32) *Attribute[uint32] {
+	factory := NewAttributeFactory[uint32](unix.NL80211_ATTR_USE_MFP)
+	return factory(mfp)
+}
+
+// SplitWiphyDumpAttribute returns a pointer to an *Attribute[bool]
+// containing a valid NL80211_ATTR_SPLIT_WIPHY_DUMP flag
+func SplitWiphyDumpAttribute(enabled bool) *Attribute[bool] {
+	factory := NewAttributeFactory[bool](unix.NL80211_ATTR_SPLIT_WIPHY_DUMP)
+	return factory(enabled)
+}
+
+// suiteBytes encodes a list of cipher or AKM suite selectors as the
+// array of u32 values nl80211 expects.
+func suiteBytes(suites []uint32) []byte {
+	b := make([]byte, 0, 4*len(suites))
+	for _, s := range suites {
+		b = append(b, nlenc.Uint32Bytes(s)...)
+	}
+	return b
+}
+
+// ScanSSIDsAttribute returns an AttributeEncoder for a valid
+// NL80211_ATTR_SCAN_SSIDS value. With no SSIDs, a single wildcard SSID is
+// encoded so that the scan is still active.
+func ScanSSIDsAttribute(ssids ...string) AttributeEncoder {
+	if len(ssids) == 0 {
+		ssids = []string{""}
+	}
+	return scanSSIDsAttribute(ssids)
+}
+
+// scanSSIDsAttribute encodes NL80211_ATTR_SCAN_SSIDS, a nested list of
+// SSIDs indexed from 0.
+type scanSSIDsAttribute []string
+
+func (a scanSSIDsAttribute) EncodeAttribute(ae *netlink.AttributeEncoder) {
+	ae.Nested(unix.NL80211_ATTR_SCAN_SSIDS, func(nae *netlink.AttributeEncoder) error {
+		for i, ssid := range a {
+			nae.Bytes(uint16(i), []byte(ssid))
+		}
+		return nil
+	})
+}
+
+// RegulatoryAlpha2Attribute returns a pointer to an *Attribute[string]
+// containing a valid NL80211_ATTR_REG_ALPHA2 value
+func RegulatoryAlpha2Attribute(alpha2 string) *Attribute[string] {
+	factory := NewAttributeFactory[string](unix.NL80211_ATTR_REG_ALPHA2)
+	return factory(alpha2)
+}
+
+// ChannelWidthAttribute returns a pointer to an *Attribute[uint32]
+// containing a valid NL80211_ATTR_CHANNEL_WIDTH value
+func ChannelWidthAttribute(width uint32) *Attribute[uint32] {
+	factory := NewAttributeFactory[uint32](unix.NL80211_ATTR_CHANNEL_WIDTH)
+	return factory(width)
+}
+
+// CenterFrequency1Attribute returns a pointer to an *Attribute[uint32]
+// containing a valid NL80211_ATTR_CENTER_FREQ1 value
+func CenterFrequency1Attribute(freq uint32) *Attribute[uint32] {
+	factory := NewAttributeFactory[uint32](unix.NL80211_ATTR_CENTER_FREQ1)
+	return factory(freq)
+}
+
+// CenterFrequency2Attribute returns a pointer to an *Attribute[uint32]
+// containing a valid NL80211_ATTR_CENTER_FREQ2 value
+func CenterFrequency2Attribute(freq uint32) *Attribute[uint32] {
+	factory := NewAttributeFactory[uint32](unix.NL80211_ATTR_CENTER_FREQ2)
+	return factory(freq)
+}
+
+// ChannelTypeAttribute returns a pointer to an *Attribute[uint32]
+// containing a valid NL80211_ATTR_WIPHY_CHANNEL_TYPE value
+func ChannelTypeAttribute(channelType uint32) *Attribute[uint32] {
+	factory := NewAttributeFactory[uint32](unix.NL80211_ATTR_WIPHY_CHANNEL_TYPE)
+	return factory(channelType)
+}
+
+// NestedAttribute returns an AttributeEncoder for an attribute of the given
+// type whose value is the nested attributes attrs.
+func NestedAttribute(typ uint16, attrs ...AttributeEncoder) AttributeEncoder {
+	return &nestedAttribute{typ: typ, attrs: attrs}
+}
+
+type nestedAttribute struct {
+	typ   uint16
+	attrs []AttributeEncoder
+}
+
+func (a *nestedAttribute) EncodeAttribute(ae *netlink.AttributeEncoder) {
+	ae.Nested(a.typ, func(nae *netlink.AttributeEncoder) error {
+		for _, attr := range a.attrs {
+			attr.EncodeAttribute(nae)
+		}
+		return nil
+	})
+}
+
+// NestedFlagsAttribute returns an AttributeEncoder for an attribute of the
+// given type holding a nested flag attribute for each of flags, such as
+// NL80211_ATTR_MNTR_FLAGS.
+func NestedFlagsAttribute(typ uint16, flags ...uint16) AttributeEncoder {
+	attrs := make([]AttributeEncoder, 0, len(flags))
+	for _, f := range flags {
+		attrs = append(attrs, NewAttributeFactory[bool](f)(true))
+	}
+	return NestedAttribute(typ, attrs...)
+}
+
+// FrameAttribute returns a pointer to an *Attribute[[]byte]
+// containing a valid NL80211_ATTR_FRAME value
+func FrameAttribute(frame []byte) *Attribute[[]byte] {
+	factory := NewAttributeFactory[[]byte](unix.NL80211_ATTR_FRAME)
+	return factory(frame)
+}
+
+// FrameTypeAttribute returns a pointer to an *Attribute[uint16]
+// containing a valid NL80211_ATTR_FRAME_TYPE value
+func FrameTypeAttribute(typ uint16) *Attribute[uint16] {
+	factory := NewAttributeFactory[uint16](unix.NL80211_ATTR_FRAME_TYPE)
+	return factory(typ)
+}
+
+// FrameMatchAttribute returns a pointer to an *Attribute[[]byte]
+// containing a valid NL80211_ATTR_FRAME_MATCH value
+func FrameMatchAttribute(match []byte) *Attribute[[]byte] {
+	factory := NewAttributeFactory[[]byte](unix.NL80211_ATTR_FRAME_MATCH)
+	return factory(match)
+}
+
+// ReasonCodeAttribute returns a pointer to an *Attribute[uint16]
+// containing a valid NL80211_ATTR_REASON_CODE value
+func ReasonCodeAttribute(reason uint16) *Attribute[uint16] {
+	factory := NewAttributeFactory[uint16](unix.NL80211_ATTR_REASON_CODE)
+	return factory(reason)
+}
+
+// MgmtSubtypeAttribute returns a pointer to an *Attribute[uint8]
+// containing a valid NL80211_ATTR_MGMT_SUBTYPE value
+func MgmtSubtypeAttribute(subtype uint8) *Attribute[uint8] {
+	factory := NewAttributeFactory[uint8](unix.NL80211_ATTR_MGMT_SUBTYPE)
+	return factory(subtype)
+}
+
+// PrevBSSIDAttribute returns a pointer to an *Attribute[[]byte]
+// containing a valid NL80211_ATTR_PREV_BSSID value
+func PrevBSSIDAttribute(bssid []byte) *Attribute[[]byte] {
+	factory := NewAttributeFactory[[]byte](unix.NL80211_ATTR_PREV_BSSID)
+	return factory(bssid)
+}
+
+// TxPowerSettingAttribute returns a pointer to an *Attribute[uint32]
+// containing a valid NL80211_ATTR_WIPHY_TX_POWER_SETTING value
+func TxPowerSettingAttribute(setting uint32) *Attribute[uint32] {
+	factory := NewAttributeFactory[uint32](unix.NL80211_ATTR_WIPHY_TX_POWER_SETTING)
+	return factory(setting)
+}
+
+// TxPowerLevelAttribute returns a pointer to an *Attribute[uint32]
+// containing a valid NL80211_ATTR_WIPHY_TX_POWER_LEVEL value, in mBm
+func TxPowerLevelAttribute(mBm uint32) *Attribute[uint32] {
+	factory := NewAttributeFactory[uint32](unix.NL80211_ATTR_WIPHY_TX_POWER_LEVEL)
+	return factory(mBm)
+}
+
+// RTSThresholdAttribute returns a pointer to an *Attribute[uint32]
+// containing a valid NL80211_ATTR_WIPHY_RTS_THRESHOLD value
+func RTSThresholdAttribute(threshold uint32) *Attribute[uint32] {
+	factory := NewAttributeFactory[uint32](unix.NL80211_ATTR_WIPHY_RTS_THRESHOLD)
+	return factory(threshold)
+}
+
+// FragmentationThresholdAttribute returns a pointer to an *Attribute[uint32]
+// containing a valid NL80211_ATTR_WIPHY_FRAG_THRESHOLD value
+func FragmentationThresholdAttribute(threshold uint32) *Attribute[uint32] {
+	factory := NewAttributeFactory[uint32](unix.NL80211_ATTR_WIPHY_FRAG_THRESHOLD)
+	return factory(threshold)
+}
+
+// FourAddrAttribute returns a pointer to an *Attribute[uint8]
+// containing a valid NL80211_ATTR_4ADDR value
+func FourAddrAttribute(enabled bool) *Attribute[uint8] {
+	factory := NewAttributeFactory[uint8](unix.NL80211_ATTR_4ADDR)
+	if enabled {
+		return factory(1)
+	}
+	return factory(0)
+}
+
+// RetryShortAttribute returns a pointer to an *Attribute[uint8]
+// containing a valid NL80211_ATTR_WIPHY_RETRY_SHORT value
+func RetryShortAttribute(limit uint8) *Attribute[uint8] {
+	factory := NewAttributeFactory[uint8](unix.NL80211_ATTR_WIPHY_RETRY_SHORT)
+	return factory(limit)
+}
+
+// RetryLongAttribute returns a pointer to an *Attribute[uint8]
+// containing a valid NL80211_ATTR_WIPHY_RETRY_LONG value
+func RetryLongAttribute(limit uint8) *Attribute[uint8] {
+	factory := NewAttributeFactory[uint8](unix.NL80211_ATTR_WIPHY_RETRY_LONG)
+	return factory(limit)
+}
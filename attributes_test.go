@@ -0,0 +1,35 @@
+package wifi_test
+
+import (
+	"testing"
+
+	"github.com/bryancoxwell/wifi"
+	"github.com/mdlayher/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// TestNestedFlagsAttribute tests the encoding of a nested list of flags, as
+// used for monitor flags.
+func TestNestedFlagsAttribute(t *testing.T) {
+	attrs := encodeAttributes(t, []wifi.AttributeEncoder{
+		wifi.NestedFlagsAttribute(unix.NL80211_ATTR_MNTR_FLAGS, unix.NL80211_MNTR_FLAG_CONTROL, unix.NL80211_MNTR_FLAG_OTHER_BSS),
+	})
+	b, ok := attrs[unix.NL80211_ATTR_MNTR_FLAGS]
+	if !ok {
+		t.Fatalf("missing NL80211_ATTR_MNTR_FLAGS in %v", attrs)
+	}
+
+	flags, err := netlink.UnmarshalAttributes(b)
+	if err != nil {
+		t.Fatalf("failed to decode flags: %v", err)
+	}
+	expected := []uint16{unix.NL80211_MNTR_FLAG_CONTROL, unix.NL80211_MNTR_FLAG_OTHER_BSS}
+	if len(flags) != len(expected) {
+		t.Fatalf("got %d flags, expected %d", len(flags), len(expected))
+	}
+	for i, f := range flags {
+		if f.Type != expected[i] || len(f.Data) != 0 {
+			t.Errorf("flag %d: got type %d with %d bytes, expected empty type %d", i, f.Type, len(f.Data), expected[i])
+		}
+	}
+}
@@ -0,0 +1,85 @@
+//go:build linux
+// +build linux
+
+package wifi
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// maxSupportedRates is the number of rates that fit in a Supported Rates
+// element; any further rates go in an Extended Supported Rates element.
+const maxSupportedRates = 8
+
+// Rates advertised by BuildBeacon when APConfig.Rates is empty.
+var (
+	defaultRates2GHz      = []float64{1, 2, 5.5, 11, 6, 9, 12, 18, 24, 36, 48, 54}
+	defaultBasicRates2GHz = []float64{1, 2, 5.5, 11}
+	defaultRates5GHz      = []float64{6, 9, 12, 18, 24, 36, 48, 54}
+	defaultBasicRates5GHz = []float64{6, 12, 24}
+)
+
+// BuildBeacon builds the beacon of a simple AP with the given BSSID from
+// cfg: a legacy (non-HT) beacon carrying the SSID, rates and, on 2.4 GHz,
+// the channel. Further elements, such as an RSN element for a protected
+// network, can be appended to the returned Tail.
+func BuildBeacon(bssid net.HardwareAddr, cfg *APConfig) (*BeaconData, error) {
+	if len(bssid) != 6 { return nil, fmt.Errorf("BuildBeacon: invalid BSSID %v", bssid) }
+	if len(cfg.SSID) == 0 || len(cfg.SSID) > 32 { return nil, fmt.Errorf("BuildBeacon: invalid SSID %q", cfg.SSID) }
+	if cfg.Channel.Frequency == 0 { return nil, errors.New("BuildBeacon: no channel") }
+
+	channel, band, err := FrequencyToChannel(int(cfg.Channel.Frequency))
+	if err != nil { return nil, fmt.Errorf("BuildBeacon: %v", err) }
+
+	rates, basic := cfg.Rates, cfg.BasicRates
+	if len(rates) == 0 {
+		rates, basic = defaultRates5GHz, defaultBasicRates5GHz
+		if band == Band2GHz {
+			rates, basic = defaultRates2GHz, defaultBasicRates2GHz
+		}
+	}
+
+	capabilities := uint16(1 << 0) // ESS
+	if cfg.Privacy {
+		capabilities |= 1 << 4
+	}
+
+	// Frame control, duration, DA, SA, BSSID and sequence control, then
+	// the timestamp the driver fills in.
+	head := make([]byte, 0, 128)
+	head = append(head, 0x80, 0x00, 0x00, 0x00)
+	head = append(head, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff)
+	head = append(head, bssid...)
+	head = append(head, bssid...)
+	head = append(head, 0x00, 0x00)
+	head = append(head, make([]byte, 8)...)
+	head = binary.LittleEndian.AppendUint16(head, uint16(cfg.beaconInterval()))
+	head = binary.LittleEndian.AppendUint16(head, capabilities)
+
+	head = appendIE(head, ieSSID, cfg.HiddenSSID.beaconSSID(cfg.SSID))
+	encoded := encodeRates(rates, basic)
+	extended := []byte(nil)
+	if len(encoded) > maxSupportedRates {
+		encoded, extended = encoded[:maxSupportedRates], encoded[maxSupportedRates:]
+	}
+	head = appendIE(head, ieSupportedRates, encoded)
+	if band == Band2GHz {
+		head = appendIE(head, ieDSParameterSet, []byte{byte(channel)})
+	}
+
+	// The kernel inserts the TIM element between the head and the tail.
+	var tail []byte
+	if len(extended) > 0 {
+		tail = appendIE(tail, ieExtendedRates, extended)
+	}
+	return &BeaconData{Head: head, Tail: tail}, nil
+}
+
+// appendIE appends an element with the given ID and body to b.
+func appendIE(b []byte, id byte, body []byte) []byte {
+	b = append(b, id, byte(len(body)))
+	return append(b, body...)
+}
@@ -0,0 +1,197 @@
+//go:build linux
+// +build linux
+
+package wifi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// Limits of the rates that can be selected in a RateMask.
+const (
+	maxHTMCS  = 76
+	maxVHTMCS = 9
+)
+
+// A GuardInterval selects the guard interval of HT and VHT transmissions.
+type GuardInterval int
+
+const (
+	GuardIntervalDefault GuardInterval = unix.NL80211_TXRATE_DEFAULT_GI
+	GuardIntervalShort   GuardInterval = unix.NL80211_TXRATE_FORCE_SGI
+	GuardIntervalLong    GuardInterval = unix.NL80211_TXRATE_FORCE_LGI
+)
+
+// String returns the string representation of a GuardInterval.
+func (gi GuardInterval) String() string {
+	switch gi {
+	case GuardIntervalDefault:
+		return "default"
+	case GuardIntervalShort:
+		return "short"
+	case GuardIntervalLong:
+		return "long"
+	default:
+		return fmt.Sprintf("unknown(%d)", gi)
+	}
+}
+
+// A RateMask restricts the rates a device transmits at, per band. Rate
+// types that aren't set for a band stay unrestricted. Its methods return
+// the mask so that calls can be chained, as in
+//
+//	NewRateMask().Legacy(Band5GHz, 6, 12, 24).HTMCS(Band5GHz, 7)
+//
+// Invalid rates are reported by SetTxBitrateMask.
+type RateMask struct {
+	bands map[BandType]*bandRateMask
+	err   error
+}
+
+type bandRateMask struct {
+	legacy []byte
+	ht     []byte
+	vht    *[unix.NL80211_VHT_NSS_MAX]uint16
+	gi     *GuardInterval
+}
+
+// NewRateMask returns an empty RateMask, which restores the default of
+// allowing every rate.
+func NewRateMask() *RateMask {
+	return &RateMask{bands: make(map[BandType]*bandRateMask)}
+}
+
+func (m *RateMask) band(band BandType) *bandRateMask {
+	b, ok := m.bands[band]
+	if !ok {
+		b = &bandRateMask{}
+		m.bands[band] = b
+	}
+	return b
+}
+
+func (m *RateMask) fail(format string, args ...interface{}) *RateMask {
+	if m.err == nil {
+		m.err = fmt.Errorf(format, args...)
+	}
+	return m
+}
+
+// Legacy allows the given legacy rates in Mbps on the band, such as 1, 5.5
+// or 54.
+func (m *RateMask) Legacy(band BandType, mbps ...float64) *RateMask {
+	b := m.band(band)
+	for _, r := range mbps {
+		// Rates are encoded in units of 500 kbps.
+		units := r * 2
+		if units <= 0 || units > math.MaxUint8 || units != math.Trunc(units) {
+			return m.fail("invalid legacy rate %g Mbps", r)
+		}
+		b.legacy = append(b.legacy, byte(units))
+	}
+	return m
+}
+
+// HTMCS allows the given HT MCS indices on the band.
+func (m *RateMask) HTMCS(band BandType, indices ...int) *RateMask {
+	b := m.band(band)
+	for _, i := range indices {
+		if i < 0 || i > maxHTMCS {
+			return m.fail("invalid HT MCS index %d", i)
+		}
+		b.ht = append(b.ht, byte(i))
+	}
+	return m
+}
+
+// VHTMCS allows VHT MCS 0 to maxMCS with nss spatial streams on the band.
+func (m *RateMask) VHTMCS(band BandType, nss, maxMCS int) *RateMask {
+	if nss < 1 || nss > unix.NL80211_VHT_NSS_MAX {
+		return m.fail("invalid number of spatial streams %d", nss)
+	}
+	if maxMCS < 0 || maxMCS > maxVHTMCS {
+		return m.fail("invalid VHT MCS %d", maxMCS)
+	}
+
+	b := m.band(band)
+	if b.vht == nil {
+		b.vht = new([unix.NL80211_VHT_NSS_MAX]uint16)
+	}
+	b.vht[nss-1] = 1<<(maxMCS+1) - 1
+	return m
+}
+
+// GuardInterval selects the guard interval of HT and VHT rates on the band.
+func (m *RateMask) GuardInterval(band BandType, gi GuardInterval) *RateMask {
+	m.band(band).gi = &gi
+	return m
+}
+
+// attributes returns the NL80211_ATTR_TX_RATES attribute encoding the
+// mask, or none for an empty mask.
+func (m *RateMask) attributes() ([]AttributeEncoder, error) {
+	if m == nil { return nil, nil }
+	if m.err != nil { return nil, m.err }
+	if len(m.bands) == 0 { return nil, nil }
+
+	bands := make([]BandType, 0, len(m.bands))
+	for band := range m.bands {
+		bands = append(bands, band)
+	}
+	sort.Slice(bands, func(i, j int) bool { return bands[i] < bands[j] })
+
+	attrs := make([]AttributeEncoder, 0, len(bands))
+	for _, band := range bands {
+		attrs = append(attrs, NestedAttribute(uint16(band), m.bands[band].attributes()...))
+	}
+	return []AttributeEncoder{NestedAttribute(unix.NL80211_ATTR_TX_RATES, attrs...)}, nil
+}
+
+// attributes returns the NL80211_TXRATE_* attributes of a band.
+func (b *bandRateMask) attributes() []AttributeEncoder {
+	var attrs []AttributeEncoder
+	if b.legacy != nil {
+		attrs = append(attrs, NewAttributeFactory[[]byte](unix.NL80211_TXRATE_LEGACY)(b.legacy))
+	}
+	if b.ht != nil {
+		attrs = append(attrs, NewAttributeFactory[[]byte](unix.NL80211_TXRATE_HT)(b.ht))
+	}
+	if b.vht != nil {
+		// struct nl80211_txrate_vht holds a MCS bitmap per spatial stream.
+		vht := make([]byte, 0, 2*len(b.vht))
+		for _, mcs := range b.vht {
+			vht = append(vht, nlenc.Uint16Bytes(mcs)...)
+		}
+		attrs = append(attrs, NewAttributeFactory[[]byte](unix.NL80211_TXRATE_VHT)(vht))
+	}
+	if b.gi != nil {
+		attrs = append(attrs, NewAttributeFactory[uint8](unix.NL80211_TXRATE_GI)(uint8(*b.gi)))
+	}
+	return attrs
+}
+
+// SetTxBitrateMask restricts the rates the given interface transmits at to
+// those allowed by mask. A nil or empty mask restores the default of
+// allowing every rate.
+func (c *Client) SetTxBitrateMask(w *WifiInterface, mask *RateMask) error {
+	rates, err := mask.attributes()
+	if err != nil { return fmt.Errorf("SetTxBitrateMask: %v", err) }
+
+	attrs := append([]AttributeEncoder{InterfaceIndexAttribute(w.Index)}, rates...)
+	msg, err := NewNl80211Message(unix.NL80211_CMD_SET_TX_BITRATE_MASK, attrs)
+	if err != nil { return fmt.Errorf("SetTxBitrateMask: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("SetTxBitrateMask: %w", err) }
+	return nil
+}
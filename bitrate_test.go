@@ -0,0 +1,75 @@
+package wifi_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bryancoxwell/wifi"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// TestRateMaskAttributes tests the nested encoding of a bitrate mask.
+func TestRateMaskAttributes(t *testing.T) {
+	mask := wifi.NewRateMask().
+		Legacy(wifi.Band2GHz, 1, 5.5, 54).
+		HTMCS(wifi.Band5GHz, 7).
+		VHTMCS(wifi.Band5GHz, 2, 7).
+		GuardInterval(wifi.Band5GHz, wifi.GuardIntervalShort)
+
+	encoders, err := mask.Attributes()
+	if err != nil {
+		t.Fatalf("Attributes: %v", err)
+	}
+	attrs := encodeAttributes(t, encoders)
+	bands := decodeNested(t, attrs[unix.NL80211_ATTR_TX_RATES])
+	if len(bands) != 2 {
+		t.Fatalf("got %d bands, expected 2", len(bands))
+	}
+
+	band2 := decodeNested(t, bands[unix.NL80211_BAND_2GHZ])
+	if !bytes.Equal(band2[unix.NL80211_TXRATE_LEGACY], []byte{2, 11, 108}) {
+		t.Errorf("got legacy rates %v, expected [2 11 108]", band2[unix.NL80211_TXRATE_LEGACY])
+	}
+	if _, ok := band2[unix.NL80211_TXRATE_HT]; ok {
+		t.Error("unexpected HT rates on 2.4 GHz")
+	}
+
+	band5 := decodeNested(t, bands[unix.NL80211_BAND_5GHZ])
+	if !bytes.Equal(band5[unix.NL80211_TXRATE_HT], []byte{7}) {
+		t.Errorf("got HT MCS %v, expected [7]", band5[unix.NL80211_TXRATE_HT])
+	}
+	vht := band5[unix.NL80211_TXRATE_VHT]
+	if len(vht) != 16 || nlenc.Uint16(vht[0:2]) != 0 || nlenc.Uint16(vht[2:4]) != 0xff {
+		t.Errorf("got VHT MCS map %x, expected MCS 0-7 on the second stream only", vht)
+	}
+	if !bytes.Equal(band5[unix.NL80211_TXRATE_GI], []byte{unix.NL80211_TXRATE_FORCE_SGI}) {
+		t.Errorf("got guard interval %v, expected short", band5[unix.NL80211_TXRATE_GI])
+	}
+}
+
+// TestRateMaskReset tests that an empty mask encodes no rates, restoring
+// the defaults.
+func TestRateMaskReset(t *testing.T) {
+	for _, mask := range []*wifi.RateMask{nil, wifi.NewRateMask()} {
+		encoders, err := mask.Attributes()
+		if err != nil || len(encoders) != 0 {
+			t.Errorf("got %d attributes and error %v, expected none", len(encoders), err)
+		}
+	}
+}
+
+// TestRateMaskInvalid tests that invalid rates are reported.
+func TestRateMaskInvalid(t *testing.T) {
+	tests := []*wifi.RateMask{
+		wifi.NewRateMask().Legacy(wifi.Band2GHz, 5.2),
+		wifi.NewRateMask().HTMCS(wifi.Band5GHz, 77),
+		wifi.NewRateMask().VHTMCS(wifi.Band5GHz, 0, 7),
+		wifi.NewRateMask().VHTMCS(wifi.Band5GHz, 1, 10),
+	}
+	for i, mask := range tests {
+		if _, err := mask.Attributes(); err == nil {
+			t.Errorf("test %d: expected an error", i)
+		}
+	}
+}
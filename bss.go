@@ -0,0 +1,380 @@
+package wifi
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// A BSS is an 802.11 basic service set, as reported by a scan.
+type BSS struct {
+	SSID string
+
+	// SSIDBytes holds the SSID exactly as advertised, for SSIDs that are
+	// not valid UTF-8 or that must be compared byte for byte.
+	SSIDBytes []byte
+
+	// Hidden is set when the BSS hides its SSID, by omitting the SSID
+	// element or advertising one that is empty or all zero bytes.
+	Hidden bool
+
+	BSSID          net.HardwareAddr
+	Frequency      uint32
+	BeaconInterval time.Duration
+
+	// TSF is the timing synchronization function timer of the BSS in
+	// microseconds, from its last beacon or probe response.
+	TSF uint64
+
+	// Capabilities is the capability information field of the BSS.
+	Capabilities CapabilityInfo
+
+	// ChannelWidth is the width the BSS was received on, which tells
+	// 20 MHz BSSs apart from 5 and 10 MHz and S1G ones. BSSs on 40 MHz
+	// and wider channels are reported as ChannelWidth20, since their
+	// beacons are sent on the 20 MHz primary channel; OperatingChannel
+	// gives their full width.
+	ChannelWidth ChannelWidth
+
+	// LastSeen is the time since the BSS was last seen by the interface.
+	LastSeen time.Duration
+
+	// Signal is the received signal strength of the BSS in dBm.
+	Signal int
+
+	// IEs holds the raw information elements advertised by the BSS.
+	IEs []IE
+
+	// Status is the relationship of the interface with the BSS, or
+	// BSSStatusNone if it has none.
+	Status BSSStatus
+}
+
+// A BSSStatus is the relationship of an interface with a BSS.
+type BSSStatus int
+
+const (
+	BSSStatusNone          BSSStatus = -1
+	BSSStatusAuthenticated BSSStatus = unix.NL80211_BSS_STATUS_AUTHENTICATED
+	BSSStatusAssociated    BSSStatus = unix.NL80211_BSS_STATUS_ASSOCIATED
+	BSSStatusIBSSJoined    BSSStatus = unix.NL80211_BSS_STATUS_IBSS_JOINED
+)
+
+// String returns the string representation of a BSSStatus.
+func (s BSSStatus) String() string {
+	switch s {
+	case BSSStatusNone:
+		return "none"
+	case BSSStatusAuthenticated:
+		return "authenticated"
+	case BSSStatusAssociated:
+		return "associated"
+	case BSSStatusIBSSJoined:
+		return "IBSS joined"
+	default:
+		return fmt.Sprintf("unknown(%d)", s)
+	}
+}
+
+// CapabilityInfo is the capability information field advertised in beacons
+// and probe responses.
+type CapabilityInfo uint16
+
+// ESS reports whether the BSS is an infrastructure network run by an AP.
+func (c CapabilityInfo) ESS() bool { return c&(1<<0) != 0 }
+
+// IBSS reports whether the BSS is an ad-hoc network.
+func (c CapabilityInfo) IBSS() bool { return c&(1<<1) != 0 }
+
+// Privacy reports whether the BSS requires encryption of data frames,
+// which is set for any WEP or WPA network.
+func (c CapabilityInfo) Privacy() bool { return c&(1<<4) != 0 }
+
+// ShortPreamble reports whether the BSS allows short PHY preambles.
+func (c CapabilityInfo) ShortPreamble() bool { return c&(1<<5) != 0 }
+
+// SpectrumManagement reports whether the BSS requires spectrum management,
+// as it does on DFS channels.
+func (c CapabilityInfo) SpectrumManagement() bool { return c&(1<<8) != 0 }
+
+// ShortSlotTime reports whether the BSS uses the short slot time.
+func (c CapabilityInfo) ShortSlotTime() bool { return c&(1<<10) != 0 }
+
+// RadioMeasurement reports whether the BSS supports 802.11k radio
+// measurement.
+func (c CapabilityInfo) RadioMeasurement() bool { return c&(1<<12) != 0 }
+
+// String returns a one line summary of the BSS for logging.
+func (b *BSS) String() string {
+	ssid := "\"" + b.SSID + "\""
+	if b.Hidden {
+		ssid = "<hidden>"
+	}
+	return fmt.Sprintf("BSS: SSID=%s, BSSID=%v, Channel=%d, Frequency=%d, Signal=%ddBm, Status=%v",
+		ssid, b.BSSID, b.Channel(), b.Frequency, b.Signal, b.Status)
+}
+
+// SupportedRates returns the rates in Mbps advertised by the BSS in its
+// Supported Rates and Extended Supported Rates elements, with the basic
+// rate flag stripped. Use BasicRates to find which of them are basic rates.
+func (b *BSS) SupportedRates() []float64 {
+	supported, _ := b.rates()
+	return supported
+}
+
+// BasicRates returns the subset of SupportedRates every station in the BSS
+// must support.
+func (b *BSS) BasicRates() []float64 {
+	_, basic := b.rates()
+	return basic
+}
+
+// rates parses the Supported Rates and Extended Supported Rates elements.
+func (b *BSS) rates() (supported, basic []float64) {
+	for _, ie := range b.IEs {
+		if ie.ID != ieSupportedRates && ie.ID != ieExtendedRates { continue }
+
+		s, bs := parseRates(ie.Data)
+		supported = append(supported, s...)
+		basic = append(basic, bs...)
+	}
+	return supported, basic
+}
+
+// Channel returns the channel number of the BSS. The DS Parameter Set
+// element is preferred because many 2.4 GHz drivers report the frequency
+// a frame was heard on rather than the channel the BSS operates on. If it
+// is absent the channel is derived from the BSS frequency, and 0 is
+// returned if the frequency is not a known channel.
+func (b *BSS) Channel() int {
+	if ie, ok := findIE(b.IEs, ieDSParameterSet); ok && len(ie.Data) == 1 {
+		return int(ie.Data[0])
+	}
+	ch, _, err := FrequencyToChannel(int(b.Frequency))
+	if err != nil { return 0 }
+	return ch
+}
+
+// HTCapabilities returns the parsed HT Capabilities element of the BSS, if
+// one was advertised.
+func (b *BSS) HTCapabilities() (*HTCapabilities, bool) {
+	ie, ok := findIE(b.IEs, ieHTCapabilities)
+	if !ok { return nil, false }
+
+	ht, err := parseHTCapabilities(ie.Data)
+	if err != nil { return nil, false }
+	return ht, true
+}
+
+// HTOperation returns the parsed HT Operation element of the BSS, if
+// one was advertised.
+func (b *BSS) HTOperation() (*HTOperation, bool) {
+	ie, ok := findIE(b.IEs, ieHTOperation)
+	if !ok { return nil, false }
+
+	ht, err := parseHTOperation(ie.Data)
+	if err != nil { return nil, false }
+	return ht, true
+}
+
+// VHTCapabilities returns the parsed VHT Capabilities element of the BSS, if
+// one was advertised.
+func (b *BSS) VHTCapabilities() (*VHTCapabilities, bool) {
+	ie, ok := findIE(b.IEs, ieVHTCapabilities)
+	if !ok { return nil, false }
+
+	vht, err := parseVHTCapabilities(ie.Data)
+	if err != nil { return nil, false }
+	return vht, true
+}
+
+// VHTOperation returns the parsed VHT Operation element of the BSS, if
+// one was advertised.
+func (b *BSS) VHTOperation() (*VHTOperation, bool) {
+	ie, ok := findIE(b.IEs, ieVHTOperation)
+	if !ok { return nil, false }
+
+	vht, err := parseVHTOperation(ie.Data)
+	if err != nil { return nil, false }
+	return vht, true
+}
+
+// HECapabilities returns the parsed HE Capabilities element of the BSS, if
+// one was advertised.
+func (b *BSS) HECapabilities() (*HECapabilities, bool) {
+	ie, ok := findExtensionIE(b.IEs, ieExtHECapabilities)
+	if !ok { return nil, false }
+
+	he, err := parseHECapabilities(ie.Data)
+	if err != nil { return nil, false }
+	return he, true
+}
+
+// HEOperation returns the parsed HE Operation element of the BSS, if
+// one was advertised. A BSS in the 6 GHz band reports its primary
+// channel through the SixGHz field.
+func (b *BSS) HEOperation() (*HEOperation, bool) {
+	ie, ok := findExtensionIE(b.IEs, ieExtHEOperation)
+	if !ok { return nil, false }
+
+	he, err := parseHEOperation(ie.Data)
+	if err != nil { return nil, false }
+	return he, true
+}
+
+// Country returns the parsed Country element of the BSS, if one was advertised.
+func (b *BSS) Country() (*Country, bool) {
+	ie, ok := findIE(b.IEs, ieCountry)
+	if !ok { return nil, false }
+
+	c, err := parseCountry(ie.Data)
+	if err != nil { return nil, false }
+	return c, true
+}
+
+// Load returns the parsed BSS Load element of the BSS, if one was
+// advertised with the expected length.
+func (b *BSS) Load() (*BSSLoad, bool) {
+	ie, ok := findIE(b.IEs, ieBSSLoad)
+	if !ok { return nil, false }
+
+	l, err := parseBSSLoad(ie.Data)
+	if err != nil { return nil, false }
+	return l, true
+}
+
+// ERP returns the parsed ERP element of the BSS, if one was advertised. Only
+// 802.11g and later APs on 2.4 GHz send it, so a 2.4 GHz BSS without one,
+// whose SupportedRates don't exceed 11 Mbps, is an 802.11b BSS.
+func (b *BSS) ERP() (*ERPInfo, bool) {
+	ie, ok := findIE(b.IEs, ieERP)
+	if !ok { return nil, false }
+
+	erp, err := parseERP(ie.Data)
+	if err != nil { return nil, false }
+	return erp, true
+}
+
+// VendorIEs returns the bodies of all vendor-specific elements (IE 221)
+// advertised by the BSS under the given OUI. When strip is set the 3-byte
+// OUI and 1-byte vendor type header is removed from each body, otherwise
+// the bodies are returned as is. Elements too short to hold the header
+// are skipped.
+func (b *BSS) VendorIEs(oui [3]byte, strip bool) [][]byte {
+	var vendor [][]byte
+	for _, ie := range b.IEs {
+		if ie.ID != ieVendor || len(ie.Data) < 4 { continue }
+		if [3]byte{ie.Data[0], ie.Data[1], ie.Data[2]} != oui { continue }
+
+		if strip {
+			vendor = append(vendor, ie.Data[4:])
+		} else {
+			vendor = append(vendor, ie.Data)
+		}
+	}
+	return vendor
+}
+
+// WPS returns the parsed WPS element of the BSS, if one was advertised.
+// Large WPS elements may be split over several vendor-specific elements,
+// in which case their bodies are concatenated before parsing.
+func (b *BSS) WPS() (*WPSInfo, bool) {
+	var body []byte
+	for _, v := range b.VendorIEs(ouiMicrosoft, false) {
+		if v[3] != vendorTypeWPS { continue }
+		body = append(body, v[4:]...)
+	}
+	if body == nil { return nil, false }
+
+	wps, err := parseWPS(body)
+	if err != nil { return nil, false }
+	return wps, true
+}
+
+// SupportsWMM reports whether the BSS advertises WMM, the Wi-Fi Alliance
+// QoS scheme prioritizing traffic by access category, through a WMM
+// Parameter or Information element.
+func (b *BSS) SupportsWMM() bool {
+	for _, v := range b.VendorIEs(ouiMicrosoft, false) {
+		if v[3] != vendorTypeWMM || len(v) < 5 { continue }
+		if v[4] == wmmSubtypeParameter || v[4] == wmmSubtypeInformation {
+			return true
+		}
+	}
+	return false
+}
+
+// OperatingChannel returns the width and center frequencies the BSS
+// operates on, derived from its HT and VHT Operation elements. A BSS
+// advertising neither is assumed to use a 20 MHz channel.
+func (b *BSS) OperatingChannel() *OperatingChannel {
+	ht, ok := b.HTOperation()
+	if !ok { return deriveOperatingChannel(b.Frequency, nil, nil) }
+
+	vht, _ := b.VHTOperation()
+	return deriveOperatingChannel(b.Frequency, ht, vht)
+}
+
+// parseBSS parses the nested NL80211_ATTR_BSS attribute of a
+// NL80211_CMD_GET_SCAN response.
+func parseBSS(b []byte) (*BSS, error) {
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil { return nil, fmt.Errorf("parseBSS: %v", err) }
+
+	bss := &BSS{Status: BSSStatusNone, ChannelWidth: ChannelWidth20}
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_BSS_BSSID:
+			bss.BSSID = net.HardwareAddr(a.Data)
+		case unix.NL80211_BSS_FREQUENCY:
+			bss.Frequency = nlenc.Uint32(a.Data)
+		case unix.NL80211_BSS_TSF:
+			bss.TSF = nlenc.Uint64(a.Data)
+		case unix.NL80211_BSS_CAPABILITY:
+			bss.Capabilities = CapabilityInfo(nlenc.Uint16(a.Data))
+		case unix.NL80211_BSS_CHAN_WIDTH:
+			bss.ChannelWidth = bssChannelWidth(nlenc.Uint32(a.Data))
+		case unix.NL80211_BSS_BEACON_INTERVAL:
+			// Beacon interval is reported in time units of 1024 µs.
+			bss.BeaconInterval = time.Duration(nlenc.Uint16(a.Data)) * 1024 * time.Microsecond
+		case unix.NL80211_BSS_SEEN_MS_AGO:
+			bss.LastSeen = time.Duration(nlenc.Uint32(a.Data)) * time.Millisecond
+		case unix.NL80211_BSS_SIGNAL_MBM:
+			bss.Signal = int(nlenc.Int32(a.Data)) / 100
+		case unix.NL80211_BSS_STATUS:
+			bss.Status = BSSStatus(nlenc.Uint32(a.Data))
+		case unix.NL80211_BSS_INFORMATION_ELEMENTS:
+			ies, err := parseIEs(a.Data)
+			if err != nil { return nil, fmt.Errorf("parseBSS: %v", err) }
+			bss.IEs = ies
+
+			if ssid, ok := findIE(ies, ieSSID); ok {
+				bss.SSID = DecodeSSID(ssid.Data)
+				bss.SSIDBytes = ssid.Data
+			}
+		}
+	}
+	bss.Hidden = isHiddenSSID(bss.SSIDBytes)
+	return bss, nil
+}
+
+// bssChannelWidth converts a NL80211_BSS_CHAN_WIDTH_* value to a
+// ChannelWidth.
+func bssChannelWidth(w uint32) ChannelWidth {
+	switch w {
+	case unix.NL80211_BSS_CHAN_WIDTH_10:
+		return ChannelWidth10
+	case unix.NL80211_BSS_CHAN_WIDTH_5:
+		return ChannelWidth5
+	case unix.NL80211_BSS_CHAN_WIDTH_1:
+		return ChannelWidth1
+	case unix.NL80211_BSS_CHAN_WIDTH_2:
+		return ChannelWidth2
+	default:
+		return ChannelWidth20
+	}
+}
@@ -0,0 +1,508 @@
+package wifi_test
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/bryancoxwell/wifi"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// TestBSSHTOperation tests the HTOperation method of the BSS type.
+// An HT40+ BSS on channel 36 should report a secondary channel above the primary.
+func TestBSSHTOperation(t *testing.T) {
+	bss := &wifi.BSS{
+		IEs: []wifi.IE{{
+			ID: 61,
+			Data: []byte{
+				36, 0x05, 0x00, 0x00, 0x00, 0x00,
+				0xff, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			},
+		}},
+	}
+
+	op, ok := bss.HTOperation()
+	if !ok {
+		t.Fatalf("HTOperation: expected element to be present")
+	}
+	expected := &wifi.HTOperation{
+		PrimaryChannel:         36,
+		SecondaryChannelOffset: wifi.SecondaryChannelAbove,
+		AnyChannelWidth:        true,
+		BasicMCS:               []int{0, 1, 2, 3, 4, 5, 6, 7},
+	}
+	if !reflect.DeepEqual(expected, op) {
+		t.Errorf("HTOperation mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", expected, op)
+	}
+}
+
+// TestBSSHTCapabilities tests the HTCapabilities method of the BSS type.
+func TestBSSHTCapabilities(t *testing.T) {
+	bss := &wifi.BSS{
+		IEs: []wifi.IE{{
+			ID: 45,
+			Data: []byte{
+				0x6e, 0x01, 0x03,
+				0xff, 0xff, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x2c, 0x01, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			},
+		}},
+	}
+
+	ht, ok := bss.HTCapabilities()
+	if !ok {
+		t.Fatalf("HTCapabilities: expected element to be present")
+	}
+	if !ht.ChannelWidth40 || !ht.ShortGI20 || !ht.ShortGI40 {
+		t.Errorf("HTCapabilities: unexpected capability flags: %+v", ht)
+	}
+	if ht.MaxAMPDULength != 65535 {
+		t.Errorf("HTCapabilities: expected max A-MPDU length 65535, got %d", ht.MaxAMPDULength)
+	}
+	if len(ht.RxMCS) != 16 || ht.RxHighestRate != 300 {
+		t.Errorf("HTCapabilities: unexpected MCS set: %v (highest %d)", ht.RxMCS, ht.RxHighestRate)
+	}
+
+	if _, ok := (&wifi.BSS{}).HTCapabilities(); ok {
+		t.Errorf("HTCapabilities: expected no element on an empty BSS")
+	}
+}
+
+// vhtBSS returns a BSS on channel 36 (HT40+) with the given VHT Operation element.
+func vhtBSS(vhtOperation []byte) *wifi.BSS {
+	return &wifi.BSS{
+		Frequency: 5180,
+		IEs: []wifi.IE{
+			{
+				ID: 61,
+				Data: []byte{
+					0x24, 0x05, 0x00, 0x00, 0x00, 0x00,
+					0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+					0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				},
+			},
+			{ID: 192, Data: vhtOperation},
+		},
+	}
+}
+
+// TestBSSOperatingChannel tests the OperatingChannel method of the BSS type
+// using HT and VHT Operation elements captured from 80 and 160 MHz beacons.
+func TestBSSOperatingChannel(t *testing.T) {
+	tests := []struct {
+		name     string
+		bss      *wifi.BSS
+		expected wifi.OperatingChannel
+	}{
+		{
+			name:     "20 MHz, no HT",
+			bss:      &wifi.BSS{Frequency: 2437},
+			expected: wifi.OperatingChannel{Width: 20, CenterFrequency1: 2437},
+		},
+		{
+			name:     "40 MHz, VHT width 0",
+			bss:      vhtBSS([]byte{0x00, 0x00, 0x00, 0xfc, 0xff}),
+			expected: wifi.OperatingChannel{Width: 40, CenterFrequency1: 5190},
+		},
+		{
+			name:     "80 MHz",
+			bss:      vhtBSS([]byte{0x01, 0x2a, 0x00, 0xfc, 0xff}),
+			expected: wifi.OperatingChannel{Width: 80, CenterFrequency1: 5210},
+		},
+		{
+			name:     "160 MHz",
+			bss:      vhtBSS([]byte{0x01, 0x2a, 0x32, 0xfc, 0xff}),
+			expected: wifi.OperatingChannel{Width: 160, CenterFrequency1: 5250},
+		},
+		{
+			name:     "80+80 MHz",
+			bss:      vhtBSS([]byte{0x01, 0x2a, 0x9b, 0xfc, 0xff}),
+			expected: wifi.OperatingChannel{Width: 160, CenterFrequency1: 5210, CenterFrequency2: 5775},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.bss.OperatingChannel(); *got != tt.expected {
+				t.Errorf("OperatingChannel mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", tt.expected, *got)
+			}
+		})
+	}
+}
+
+// TestBSSHEOperation tests extension element parsing and the HEOperation
+// method of the BSS type on a 6 GHz BSS, including a truncated element.
+func TestBSSHEOperation(t *testing.T) {
+	ies, err := wifi.ParseIEs([]byte{
+		// HE Operation, 6 GHz operation information present: primary
+		// channel 37, 80 MHz, CCFS0 39.
+		0xff, 0x0c, 0x24, 0x00, 0x00, 0x02, 0x05, 0xfc, 0xff, 0x25, 0x02, 0x27, 0x00, 0x06,
+		// A truncated extension element with no extension ID.
+		0xff, 0x00,
+	})
+	if err != nil {
+		t.Fatalf("ParseIEs: %v", err)
+	}
+	if len(ies) != 2 || ies[0].ExtensionID != 36 || ies[1].ExtensionID != 0 {
+		t.Fatalf("ParseIEs: unexpected elements: %+v", ies)
+	}
+
+	he, ok := (&wifi.BSS{IEs: ies}).HEOperation()
+	if !ok {
+		t.Fatalf("HEOperation: expected element to be present")
+	}
+	expected := wifi.HE6GHzOperation{
+		PrimaryChannel:          37,
+		Width:                   80,
+		CenterFrequencySegment0: 39,
+		MinimumRate:             6,
+	}
+	if he.BSSColor != 5 || he.SixGHz == nil || *he.SixGHz != expected {
+		t.Errorf("HEOperation mismatch.\nExpected: \t%+v\nGot:\t\t%+v %+v\n", expected, he, he.SixGHz)
+	}
+
+	// The 6 GHz subfield is announced but missing.
+	truncated := []wifi.IE{{ID: 255, ExtensionID: 36, Data: []byte{0x00, 0x00, 0x02, 0x05, 0xfc, 0xff, 0x25}}}
+	if _, ok := (&wifi.BSS{IEs: truncated}).HEOperation(); ok {
+		t.Errorf("HEOperation: expected truncated element to be reported as absent")
+	}
+}
+
+// TestBSSWPS tests the WPS method of the BSS type using a probe response
+// WPS element that has been split over two vendor-specific elements.
+func TestBSSWPS(t *testing.T) {
+	ies, err := wifi.ParseIEs([]byte{
+		0xdd, 0x2c, 0x00, 0x50, 0xf2, 0x04, 0x10, 0x4a, 0x00, 0x01, 0x10, 0x10,
+		0x44, 0x00, 0x01, 0x02, 0x10, 0x41, 0x00, 0x01, 0x01, 0x10, 0x12, 0x00,
+		0x02, 0x00, 0x04, 0x10, 0x53, 0x00, 0x02, 0x26, 0x88, 0x10, 0x3b, 0x00,
+		0x01, 0x03, 0x10, 0x47, 0x00, 0x10, 0x00, 0x01, 0x02, 0x03,
+		0xdd, 0x55, 0x00, 0x50, 0xf2, 0x04, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09,
+		0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10, 0x21, 0x00, 0x07, 0x54, 0x50,
+		0x2d, 0x4c, 0x49, 0x4e, 0x4b, 0x10, 0x23, 0x00, 0x09, 0x41, 0x72, 0x63,
+		0x68, 0x65, 0x72, 0x20, 0x43, 0x37, 0x10, 0x24, 0x00, 0x03, 0x35, 0x2e,
+		0x30, 0x10, 0x42, 0x00, 0x03, 0x31, 0x2e, 0x30, 0x10, 0x54, 0x00, 0x08,
+		0x00, 0x06, 0x00, 0x50, 0xf2, 0x04, 0x00, 0x01, 0x10, 0x11, 0x00, 0x09,
+		0x41, 0x72, 0x63, 0x68, 0x65, 0x72, 0x20, 0x43, 0x37, 0x10, 0x08, 0x00,
+		0x02, 0x20, 0x08,
+	})
+	if err != nil {
+		t.Fatalf("ParseIEs: %v", err)
+	}
+
+	wps, ok := (&wifi.BSS{IEs: ies}).WPS()
+	if !ok {
+		t.Fatalf("WPS: expected element to be present")
+	}
+	expected := &wifi.WPSInfo{
+		Version:           0x10,
+		State:             wifi.WPSStateConfigured,
+		SelectedRegistrar: true,
+		ConfigMethods:     0x2008,
+		DeviceName:        "Archer C7",
+		Manufacturer:      "TP-LINK",
+		Model:             "Archer C7",
+		ModelNumber:       "5.0",
+		SerialNumber:      "1.0",
+	}
+	if !reflect.DeepEqual(expected, wps) {
+		t.Errorf("WPS mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", expected, wps)
+	}
+
+	// A WPA vendor element shares the OUI but not the type.
+	wpa := []wifi.IE{{ID: 221, Data: []byte{0x00, 0x50, 0xf2, 0x01, 0x01, 0x00}}}
+	if _, ok := (&wifi.BSS{IEs: wpa}).WPS(); ok {
+		t.Errorf("WPS: expected no element on a BSS advertising only WPA")
+	}
+}
+
+// TestBSSVendorIEs tests the VendorIEs method of the BSS type, including
+// vendor-specific elements too short to carry an OUI and type.
+func TestBSSVendorIEs(t *testing.T) {
+	wfa := [3]byte{0x50, 0x6f, 0x9a}
+	bss := &wifi.BSS{
+		IEs: []wifi.IE{
+			{ID: 221, Data: []byte{0x50, 0x6f, 0x9a, 0x10, 0x14}},
+			{ID: 221, Data: []byte{0x50, 0x6f}},
+			{ID: 221, Data: []byte{0x00, 0x50, 0xf2, 0x02, 0x00, 0x01}},
+			{ID: 221, Data: []byte{0x50, 0x6f, 0x9a, 0x09}},
+		},
+	}
+
+	expected := [][]byte{{0x50, 0x6f, 0x9a, 0x10, 0x14}, {0x50, 0x6f, 0x9a, 0x09}}
+	if got := bss.VendorIEs(wfa, false); !reflect.DeepEqual(expected, got) {
+		t.Errorf("VendorIEs mismatch.\nExpected: \t%v\nGot:\t\t%v\n", expected, got)
+	}
+
+	expected = [][]byte{{0x14}, {}}
+	if got := bss.VendorIEs(wfa, true); !reflect.DeepEqual(expected, got) {
+		t.Errorf("VendorIEs mismatch.\nExpected: \t%v\nGot:\t\t%v\n", expected, got)
+	}
+}
+
+// TestBSSCountry tests the Country method of the BSS type with a padded
+// 2.4 GHz element and a 5 GHz element using operating triplets.
+func TestBSSCountry(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected *wifi.Country
+	}{
+		{
+			name: "2.4 GHz with padding",
+			data: []byte{'U', 'S', ' ', 0x01, 0x0b, 0x1e, 0x00},
+			expected: &wifi.Country{
+				Code:        "US",
+				Environment: ' ',
+				Subbands:    []wifi.CountrySubband{{FirstChannel: 1, NumChannels: 11, MaxTxPower: 30}},
+			},
+		},
+		{
+			name: "5 GHz with operating triplets",
+			data: []byte{
+				'D', 'E', 0x04,
+				0x24, 0x04, 0x17,
+				0xc9, 0x79, 0x00,
+				0x64, 0x0b, 0x1e,
+			},
+			expected: &wifi.Country{
+				Code:        "DE",
+				Environment: 0x04,
+				Subbands: []wifi.CountrySubband{
+					{FirstChannel: 36, NumChannels: 4, MaxTxPower: 23},
+					{FirstChannel: 100, NumChannels: 11, MaxTxPower: 30, OperatingClass: 121},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, ok := (&wifi.BSS{IEs: []wifi.IE{{ID: 7, Data: tt.data}}}).Country()
+			if !ok {
+				t.Fatalf("Country: expected element to be present")
+			}
+			if !reflect.DeepEqual(tt.expected, c) {
+				t.Errorf("Country mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", tt.expected, c)
+			}
+		})
+	}
+}
+
+// TestBSSRatesAndChannel tests the SupportedRates, BasicRates and Channel
+// methods of the BSS type on an 802.11g BSS heard on an adjacent channel.
+func TestBSSRatesAndChannel(t *testing.T) {
+	bss := &wifi.BSS{
+		Frequency: 2442,
+		IEs: []wifi.IE{
+			{ID: 1, Data: []byte{0x82, 0x84, 0x8b, 0x96, 0x0c, 0x12, 0x18, 0x24}},
+			{ID: 3, Data: []byte{0x06}},
+			{ID: 50, Data: []byte{0x30, 0x48, 0x60, 0x6c, 0xff}},
+		},
+	}
+
+	supported := []float64{1, 2, 5.5, 11, 6, 9, 12, 18, 24, 36, 48, 54}
+	if got := bss.SupportedRates(); !reflect.DeepEqual(supported, got) {
+		t.Errorf("SupportedRates mismatch.\nExpected: \t%v\nGot:\t\t%v\n", supported, got)
+	}
+	basic := []float64{1, 2, 5.5, 11}
+	if got := bss.BasicRates(); !reflect.DeepEqual(basic, got) {
+		t.Errorf("BasicRates mismatch.\nExpected: \t%v\nGot:\t\t%v\n", basic, got)
+	}
+
+	if ch := bss.Channel(); ch != 6 {
+		t.Errorf("Channel: expected 6 from the DS Parameter Set, got %d", ch)
+	}
+	if ch := (&wifi.BSS{Frequency: 2442}).Channel(); ch != 7 {
+		t.Errorf("Channel: expected 7 from the frequency, got %d", ch)
+	}
+}
+
+// TestBSSLoad tests the Load method of the BSS type, which should ignore
+// BSS Load elements of the wrong length.
+func TestBSSLoad(t *testing.T) {
+	bss := &wifi.BSS{IEs: []wifi.IE{{ID: 11, Data: []byte{0x03, 0x00, 0x80, 0x00, 0x12}}}}
+	l, ok := bss.Load()
+	if !ok {
+		t.Fatalf("Load: expected element to be present")
+	}
+	if l.StationCount != 3 || l.ChannelUtilization != 128 || l.AvailableAdmissionCapacity != 0x1200 {
+		t.Errorf("Load: unexpected element: %+v", l)
+	}
+	if pct := l.ChannelUtilizationPercent(); pct < 50.1 || pct > 50.2 {
+		t.Errorf("ChannelUtilizationPercent: expected 50.2, got %v", pct)
+	}
+
+	short := &wifi.BSS{IEs: []wifi.IE{{ID: 11, Data: []byte{0x03, 0x00, 0x80}}}}
+	if _, ok := short.Load(); ok {
+		t.Errorf("Load: expected short element to be reported as absent")
+	}
+}
+
+// TestBSSERP tests the ERP method of the BSS type.
+func TestBSSERP(t *testing.T) {
+	bss := &wifi.BSS{IEs: []wifi.IE{{ID: 42, Data: []byte{0x03}}}}
+	erp, ok := bss.ERP()
+	if !ok {
+		t.Fatalf("ERP: expected element to be present")
+	}
+	expected := wifi.ERPInfo{NonERPPresent: true, UseProtection: true}
+	if *erp != expected {
+		t.Errorf("ERP: unexpected element: %+v", erp)
+	}
+
+	if _, ok := (&wifi.BSS{IEs: []wifi.IE{{ID: 42}}}).ERP(); ok {
+		t.Errorf("ERP: expected empty element to be reported as absent")
+	}
+}
+
+// TestBSSSupportsWMM tests the detection of the WMM vendor-specific element,
+// which should not be confused with the other Microsoft elements.
+func TestBSSSupportsWMM(t *testing.T) {
+	tests := []struct {
+		name     string
+		ies      []wifi.IE
+		expected bool
+	}{
+		{
+			name:     "parameter element",
+			ies:      []wifi.IE{{ID: 221, Data: []byte{0x00, 0x50, 0xf2, 0x02, 0x01, 0x01, 0x80}}},
+			expected: true,
+		},
+		{
+			name:     "information element",
+			ies:      []wifi.IE{{ID: 221, Data: []byte{0x00, 0x50, 0xf2, 0x02, 0x00, 0x01, 0x00}}},
+			expected: true,
+		},
+		{
+			name: "WPA element only",
+			ies:  []wifi.IE{{ID: 221, Data: []byte{0x00, 0x50, 0xf2, 0x01, 0x01, 0x00}}},
+		},
+		{
+			name: "no subtype",
+			ies:  []wifi.IE{{ID: 221, Data: []byte{0x00, 0x50, 0xf2, 0x02}}},
+		},
+	}
+	for _, tt := range tests {
+		if got := (&wifi.BSS{IEs: tt.ies}).SupportsWMM(); got != tt.expected {
+			t.Errorf("%s: SupportsWMM() = %v, expected %v", tt.name, got, tt.expected)
+		}
+	}
+}
+
+// TestBSSString tests the one line summary of a BSS.
+func TestBSSString(t *testing.T) {
+	tests := []struct {
+		bss      *wifi.BSS
+		expected string
+	}{
+		{
+			bss: &wifi.BSS{
+				SSID:      "home",
+				BSSID:     net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x01, 0x00},
+				Frequency: 5180,
+				Signal:    -42,
+				Status:    wifi.BSSStatusAssociated,
+			},
+			expected: `BSS: SSID="home", BSSID=02:00:00:00:01:00, Channel=36, Frequency=5180, Signal=-42dBm, Status=associated`,
+		},
+		{
+			bss: &wifi.BSS{
+				Hidden:    true,
+				BSSID:     net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x02, 0x00},
+				Frequency: 2412,
+				Signal:    -70,
+				Status:    wifi.BSSStatusNone,
+			},
+			expected: `BSS: SSID=<hidden>, BSSID=02:00:00:00:02:00, Channel=1, Frequency=2412, Signal=-70dBm, Status=none`,
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.bss.String(); got != tt.expected {
+			t.Errorf("String mismatch.\nExpected: \t%s\nGot:\t\t%s\n", tt.expected, got)
+		}
+	}
+}
+
+// TestParseBSSStatus tests that the BSS status is parsed from
+// NL80211_BSS_STATUS, and is BSSStatusNone when the attribute is absent.
+func TestParseBSSStatus(t *testing.T) {
+	tests := []struct {
+		attrs    []netlink.Attribute
+		expected wifi.BSSStatus
+		str      string
+	}{
+		{nil, wifi.BSSStatusNone, "none"},
+		{[]netlink.Attribute{{Type: unix.NL80211_BSS_STATUS, Data: nlenc.Uint32Bytes(unix.NL80211_BSS_STATUS_AUTHENTICATED)}}, wifi.BSSStatusAuthenticated, "authenticated"},
+		{[]netlink.Attribute{{Type: unix.NL80211_BSS_STATUS, Data: nlenc.Uint32Bytes(unix.NL80211_BSS_STATUS_ASSOCIATED)}}, wifi.BSSStatusAssociated, "associated"},
+		{[]netlink.Attribute{{Type: unix.NL80211_BSS_STATUS, Data: nlenc.Uint32Bytes(unix.NL80211_BSS_STATUS_IBSS_JOINED)}}, wifi.BSSStatusIBSSJoined, "IBSS joined"},
+	}
+	for _, tt := range tests {
+		attrs := append([]netlink.Attribute{
+			{Type: unix.NL80211_BSS_FREQUENCY, Data: nlenc.Uint32Bytes(2412)},
+		}, tt.attrs...)
+		bss, err := wifi.ParseBSS(mustMarshalAttributes(t, attrs))
+		if err != nil {
+			t.Fatalf("ParseBSS: %v", err)
+		}
+		if bss.Status != tt.expected || bss.Status.String() != tt.str {
+			t.Errorf("Status = %v, expected %v", bss.Status, tt.str)
+		}
+	}
+}
+
+// TestParseBSSCapabilities tests the parsing of the TSF and capability
+// information of a BSS.
+func TestParseBSSCapabilities(t *testing.T) {
+	// ESS, privacy, short slot time.
+	const capability = 0x0411
+	bss, err := wifi.ParseBSS(mustMarshalAttributes(t, []netlink.Attribute{
+		{Type: unix.NL80211_BSS_TSF, Data: nlenc.Uint64Bytes(123456789)},
+		{Type: unix.NL80211_BSS_CAPABILITY, Data: nlenc.Uint16Bytes(capability)},
+	}))
+	if err != nil {
+		t.Fatalf("ParseBSS: %v", err)
+	}
+	if bss.TSF != 123456789 {
+		t.Errorf("TSF = %d, expected 123456789", bss.TSF)
+	}
+	if bss.Capabilities != capability {
+		t.Errorf("Capabilities = %#04x, expected %#04x", uint16(bss.Capabilities), capability)
+	}
+
+	c := bss.Capabilities
+	if !c.ESS() || c.IBSS() || !c.Privacy() || c.ShortPreamble() || c.SpectrumManagement() || !c.ShortSlotTime() || c.RadioMeasurement() {
+		t.Errorf("unexpected capability bits in %#04x", uint16(c))
+	}
+}
+
+// TestParseBSSChannelWidth tests the conversion of NL80211_BSS_CHAN_WIDTH
+// to a ChannelWidth.
+func TestParseBSSChannelWidth(t *testing.T) {
+	tests := []struct {
+		attrs    []netlink.Attribute
+		expected wifi.ChannelWidth
+	}{
+		{nil, wifi.ChannelWidth20},
+		{[]netlink.Attribute{{Type: unix.NL80211_BSS_CHAN_WIDTH, Data: nlenc.Uint32Bytes(unix.NL80211_BSS_CHAN_WIDTH_20)}}, wifi.ChannelWidth20},
+		{[]netlink.Attribute{{Type: unix.NL80211_BSS_CHAN_WIDTH, Data: nlenc.Uint32Bytes(unix.NL80211_BSS_CHAN_WIDTH_10)}}, wifi.ChannelWidth10},
+		{[]netlink.Attribute{{Type: unix.NL80211_BSS_CHAN_WIDTH, Data: nlenc.Uint32Bytes(unix.NL80211_BSS_CHAN_WIDTH_5)}}, wifi.ChannelWidth5},
+		{[]netlink.Attribute{{Type: unix.NL80211_BSS_CHAN_WIDTH, Data: nlenc.Uint32Bytes(unix.NL80211_BSS_CHAN_WIDTH_2)}}, wifi.ChannelWidth2},
+	}
+	for _, tt := range tests {
+		attrs := append([]netlink.Attribute{
+			{Type: unix.NL80211_BSS_FREQUENCY, Data: nlenc.Uint32Bytes(5180)},
+		}, tt.attrs...)
+		bss, err := wifi.ParseBSS(mustMarshalAttributes(t, attrs))
+		if err != nil {
+			t.Fatalf("ParseBSS: %v", err)
+		}
+		if bss.ChannelWidth != tt.expected {
+			t.Errorf("ChannelWidth = %v, expected %v", bss.ChannelWidth, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+//go:build linux
+// +build linux
+
+package wifi
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultInterfaceCacheTTL is how long InterfaceByNameCached reuses an
+// interface lookup unless SetInterfaceCacheTTL says otherwise.
+const defaultInterfaceCacheTTL = 5 * time.Second
+
+// cachedInterface is an interface looked up by InterfaceByNameCached.
+type cachedInterface struct {
+	iface   WifiInterface
+	expires time.Time
+}
+
+// InterfaceByNameCached returns the named interface like InterfaceByName,
+// but reuses the result of an earlier lookup for up to the cache TTL, 5
+// seconds unless set with SetInterfaceCacheTTL. It is meant for tight
+// polling loops that only need the index, name and wiphy of an interface:
+// fields that change while the interface is up, such as Frequency, may be
+// stale. The cache is cleared when interfaces are created, deleted or
+// change type through the Client, and can be cleared explicitly with
+// InvalidateInterfaceCache, for instance on an InterfaceEvent.
+func (c *Client) InterfaceByNameCached(name string) (*WifiInterface, error) {
+	c.mu.Lock()
+	cached, ok := c.interfaceCache[name]
+	c.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		iface := cached.iface
+		return &iface, nil
+	}
+
+	iface, err := c.InterfaceByName(name)
+	if err != nil { return nil, fmt.Errorf("InterfaceByNameCached: %w", err) }
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.interfaceCache == nil {
+		c.interfaceCache = make(map[string]cachedInterface)
+	}
+	ttl := c.interfaceCacheTTL
+	if ttl == 0 {
+		ttl = defaultInterfaceCacheTTL
+	}
+	c.interfaceCache[name] = cachedInterface{iface: *iface, expires: time.Now().Add(ttl)}
+	return iface, nil
+}
+
+// SetInterfaceCacheTTL sets how long InterfaceByNameCached reuses a lookup.
+// A ttl of 0 restores the default of 5 seconds. Entries already cached
+// keep their expiry.
+func (c *Client) SetInterfaceCacheTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interfaceCacheTTL = ttl
+}
+
+// InvalidateInterfaceCache clears the lookups cached by
+// InterfaceByNameCached.
+func (c *Client) InvalidateInterfaceCache() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interfaceCache = nil
+}
+
+// connectWiphy returns the wiphy of w, used by Connect to check the ciphers
+// and offloads it supports. These don't change, and the kernel never reuses
+// a wiphy index, so each wiphy is only looked up once.
+func (c *Client) connectWiphy(w *WifiInterface) (*Wiphy, error) {
+	c.mu.Lock()
+	wiphy, ok := c.connectWiphys[w.Phy]
+	c.mu.Unlock()
+	if ok { return wiphy, nil }
+
+	wiphy, err := c.Wiphy(w)
+	if err != nil { return nil, err }
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.connectWiphys == nil {
+		c.connectWiphys = make(map[uint32]*Wiphy)
+	}
+	c.connectWiphys[w.Phy] = wiphy
+	return wiphy, nil
+}
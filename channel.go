@@ -0,0 +1,261 @@
+package wifi
+
+import (
+	"fmt"
+
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// A BandType identifies a frequency band.
+type BandType int
+
+const (
+	Band2GHz  BandType = unix.NL80211_BAND_2GHZ
+	Band5GHz  BandType = unix.NL80211_BAND_5GHZ
+	Band60GHz BandType = unix.NL80211_BAND_60GHZ
+	Band6GHz  BandType = unix.NL80211_BAND_6GHZ
+	BandS1GHz BandType = unix.NL80211_BAND_S1GHZ
+)
+
+// String returns the string representation of a BandType.
+func (b BandType) String() string {
+	switch b {
+	case Band2GHz:
+		return "2.4 GHz"
+	case Band5GHz:
+		return "5 GHz"
+	case Band60GHz:
+		return "60 GHz"
+	case Band6GHz:
+		return "6 GHz"
+	case BandS1GHz:
+		return "sub-1 GHz"
+	default:
+		return fmt.Sprintf("unknown(%d)", b)
+	}
+}
+
+// ChannelToFrequency returns the center frequency in MHz of the given
+// channel in the given band, following IEEE 802.11 channel numbering.
+func ChannelToFrequency(channel int, band BandType) (int, error) {
+	switch band {
+	case Band2GHz:
+		switch {
+		case channel == 14:
+			return 2484, nil
+		case channel >= 1 && channel <= 13:
+			return 2407 + channel*5, nil
+		}
+	case Band5GHz:
+		switch {
+		case channel >= 182 && channel <= 196:
+			// 4.9 GHz channels used in Japan.
+			return 4000 + channel*5, nil
+		case channel >= 1 && channel <= 181:
+			return 5000 + channel*5, nil
+		}
+	case Band6GHz:
+		switch {
+		case channel == 2:
+			return 5935, nil
+		case channel >= 1 && channel <= 233:
+			return 5950 + channel*5, nil
+		}
+	case Band60GHz:
+		if channel >= 1 && channel <= 6 {
+			return 56160 + channel*2160, nil
+		}
+	}
+	return 0, fmt.Errorf("ChannelToFrequency: invalid channel %d in band %v", channel, band)
+}
+
+// FrequencyToChannel returns the channel number and band of the given
+// center frequency in MHz, following IEEE 802.11 channel numbering.
+func FrequencyToChannel(freq int) (int, BandType, error) {
+	switch {
+	case freq == 2484:
+		return 14, Band2GHz, nil
+	case freq >= 2412 && freq <= 2472 && (freq-2407)%5 == 0:
+		return (freq - 2407) / 5, Band2GHz, nil
+	case freq >= 4910 && freq <= 4980 && freq%5 == 0:
+		return (freq - 4000) / 5, Band5GHz, nil
+	case freq == 5935:
+		return 2, Band6GHz, nil
+	case freq >= 5955 && freq <= 7115 && freq%5 == 0:
+		return (freq - 5950) / 5, Band6GHz, nil
+	case freq >= 5005 && freq <= 5905 && freq%5 == 0:
+		return (freq - 5000) / 5, Band5GHz, nil
+	case freq >= 58320 && freq <= 69120 && (freq-56160)%2160 == 0:
+		return (freq - 56160) / 2160, Band60GHz, nil
+	}
+	return 0, 0, fmt.Errorf("FrequencyToChannel: invalid frequency %d MHz", freq)
+}
+
+// A ChannelWidth is the width of a channel, matching nl80211_chan_width.
+type ChannelWidth int
+
+const (
+	ChannelWidth20NoHT ChannelWidth = unix.NL80211_CHAN_WIDTH_20_NOHT
+	ChannelWidth20     ChannelWidth = unix.NL80211_CHAN_WIDTH_20
+	ChannelWidth40     ChannelWidth = unix.NL80211_CHAN_WIDTH_40
+	ChannelWidth80     ChannelWidth = unix.NL80211_CHAN_WIDTH_80
+	ChannelWidth80P80  ChannelWidth = unix.NL80211_CHAN_WIDTH_80P80
+	ChannelWidth160    ChannelWidth = unix.NL80211_CHAN_WIDTH_160
+	ChannelWidth5      ChannelWidth = unix.NL80211_CHAN_WIDTH_5
+	ChannelWidth10     ChannelWidth = unix.NL80211_CHAN_WIDTH_10
+
+	// ChannelWidth1 and ChannelWidth2 are S1G (sub-1 GHz) channel widths.
+	ChannelWidth1 ChannelWidth = unix.NL80211_CHAN_WIDTH_1
+	ChannelWidth2 ChannelWidth = unix.NL80211_CHAN_WIDTH_2
+)
+
+// String returns the string representation of a ChannelWidth.
+func (cw ChannelWidth) String() string {
+	switch cw {
+	case ChannelWidth20NoHT:
+		return "20 MHz (no HT)"
+	case ChannelWidth20:
+		return "20 MHz"
+	case ChannelWidth40:
+		return "40 MHz"
+	case ChannelWidth80:
+		return "80 MHz"
+	case ChannelWidth80P80:
+		return "80+80 MHz"
+	case ChannelWidth160:
+		return "160 MHz"
+	case ChannelWidth5:
+		return "5 MHz"
+	case ChannelWidth10:
+		return "10 MHz"
+	case ChannelWidth1:
+		return "1 MHz"
+	case ChannelWidth2:
+		return "2 MHz"
+	default:
+		return fmt.Sprintf("unknown(%d)", cw)
+	}
+}
+
+// MHz returns the width in MHz of the segment(s) centered on the center
+// frequencies, so 80 for ChannelWidth80P80.
+func (cw ChannelWidth) MHz() int {
+	switch cw {
+	case ChannelWidth20NoHT, ChannelWidth20:
+		return 20
+	case ChannelWidth40:
+		return 40
+	case ChannelWidth80, ChannelWidth80P80:
+		return 80
+	case ChannelWidth160:
+		return 160
+	case ChannelWidth5:
+		return 5
+	case ChannelWidth10:
+		return 10
+	case ChannelWidth1:
+		return 1
+	case ChannelWidth2:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// A ChannelDefinition is a channel of any width: its primary 20 MHz
+// channel, its width, and the center frequencies of its segments, all
+// in MHz.
+type ChannelDefinition struct {
+	// Frequency is the frequency of the primary channel.
+	Frequency uint32
+	Width     ChannelWidth
+
+	// CenterFrequency1 is the center of the channel, or of its first
+	// segment for ChannelWidth80P80. It may be left 0 for 20, 10 and 5
+	// MHz channels, whose center is the primary frequency.
+	CenterFrequency1 uint32
+
+	// CenterFrequency2 is the center of the second segment of a
+	// ChannelWidth80P80 channel, and 0 otherwise.
+	CenterFrequency2 uint32
+}
+
+// validate reports an error if the center frequencies of the definition are
+// inconsistent with its primary frequency and width.
+func (def *ChannelDefinition) validate() error {
+	if def.Width.MHz() == 0 { return fmt.Errorf("invalid channel width %v", def.Width) }
+
+	cf1 := def.centerFrequency1()
+	offset := int(def.Frequency) - int(cf1)
+	if offset < 0 {
+		offset = -offset
+	}
+	// The primary channel is one of the 20 MHz channels making up the
+	// segment centered on cf1, so it is an odd multiple of 10 MHz away
+	// from it, or centered on it for channels of 20 MHz or less.
+	switch def.Width {
+	case ChannelWidth20NoHT, ChannelWidth20, ChannelWidth5, ChannelWidth10:
+		if offset != 0 { return fmt.Errorf("center frequency %d MHz differs from primary %d MHz on a %v channel", cf1, def.Frequency, def.Width) }
+	default:
+		if offset%20 != 10 || offset >= def.Width.MHz()/2 {
+			return fmt.Errorf("center frequency %d MHz does not contain primary %d MHz on a %v channel", cf1, def.Frequency, def.Width)
+		}
+	}
+
+	if def.Width != ChannelWidth80P80 {
+		if def.CenterFrequency2 != 0 { return fmt.Errorf("second center frequency given for a %v channel", def.Width) }
+		return nil
+	}
+	gap := int(def.CenterFrequency2) - int(cf1)
+	if gap < 0 {
+		gap = -gap
+	}
+	if def.CenterFrequency2 == 0 || gap <= 80 {
+		return fmt.Errorf("invalid second center frequency %d MHz for an 80+80 MHz channel centered on %d MHz", def.CenterFrequency2, cf1)
+	}
+	return nil
+}
+
+// centerFrequency1 returns CenterFrequency1, defaulting to the primary
+// frequency when it is not set.
+func (def *ChannelDefinition) centerFrequency1() uint32 {
+	if def.CenterFrequency1 == 0 {
+		return def.Frequency
+	}
+	return def.CenterFrequency1
+}
+
+// channelWidthEncoder returns the attributes describing the channel def.
+func channelWidthEncoder(def *ChannelDefinition) []AttributeEncoder {
+	attrs := []AttributeEncoder{
+		WiphyFrequencyAttribute(def.Frequency),
+		ChannelWidthAttribute(uint32(def.Width)),
+		CenterFrequency1Attribute(def.centerFrequency1()),
+	}
+	if def.CenterFrequency2 != 0 {
+		attrs = append(attrs, CenterFrequency2Attribute(def.CenterFrequency2))
+	}
+	return attrs
+}
+
+// parseChannelDefinition parses the attributes written by
+// channelWidthEncoder, as found in channel switch and radar notifications.
+// Attributes of any other type are ignored.
+func parseChannelDefinition(attrs []netlink.Attribute) ChannelDefinition {
+	var def ChannelDefinition
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_ATTR_WIPHY_FREQ:
+			def.Frequency = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_CHANNEL_WIDTH:
+			def.Width = ChannelWidth(nlenc.Uint32(a.Data))
+		case unix.NL80211_ATTR_CENTER_FREQ1:
+			def.CenterFrequency1 = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_CENTER_FREQ2:
+			def.CenterFrequency2 = nlenc.Uint32(a.Data)
+		}
+	}
+	return def
+}
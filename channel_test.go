@@ -0,0 +1,102 @@
+package wifi_test
+
+import (
+	"testing"
+
+	"github.com/bryancoxwell/wifi"
+)
+
+// TestChannelFrequencyConversion tests the conversion of channels to
+// frequencies and back in every band, including the special cases of
+// 2.4 GHz channel 14 and 6 GHz channel 2.
+func TestChannelFrequencyConversion(t *testing.T) {
+	tests := []struct {
+		channel int
+		band    wifi.BandType
+		freq    int
+	}{
+		{1, wifi.Band2GHz, 2412},
+		{13, wifi.Band2GHz, 2472},
+		{14, wifi.Band2GHz, 2484},
+		{36, wifi.Band5GHz, 5180},
+		{165, wifi.Band5GHz, 5825},
+		{177, wifi.Band5GHz, 5885},
+		{184, wifi.Band5GHz, 4920},
+		{1, wifi.Band6GHz, 5955},
+		{2, wifi.Band6GHz, 5935},
+		{37, wifi.Band6GHz, 6135},
+		{233, wifi.Band6GHz, 7115},
+		{2, wifi.Band60GHz, 60480},
+	}
+	for _, tt := range tests {
+		freq, err := wifi.ChannelToFrequency(tt.channel, tt.band)
+		if err != nil || freq != tt.freq {
+			t.Errorf("ChannelToFrequency(%d, %v) = %d, %v, expected %d", tt.channel, tt.band, freq, err, tt.freq)
+		}
+		channel, band, err := wifi.FrequencyToChannel(tt.freq)
+		if err != nil || channel != tt.channel || band != tt.band {
+			t.Errorf("FrequencyToChannel(%d) = %d, %v, %v, expected %d, %v", tt.freq, channel, band, err, tt.channel, tt.band)
+		}
+	}
+
+	invalid := []struct {
+		channel int
+		band    wifi.BandType
+	}{
+		{0, wifi.Band2GHz},
+		{15, wifi.Band2GHz},
+		{234, wifi.Band6GHz},
+		{1, wifi.BandS1GHz},
+	}
+	for _, tt := range invalid {
+		if _, err := wifi.ChannelToFrequency(tt.channel, tt.band); err == nil {
+			t.Errorf("ChannelToFrequency(%d, %v): expected error", tt.channel, tt.band)
+		}
+	}
+	for _, freq := range []int{0, 2413, 2480, 5937, 7120} {
+		if _, _, err := wifi.FrequencyToChannel(freq); err == nil {
+			t.Errorf("FrequencyToChannel(%d): expected error", freq)
+		}
+	}
+
+	if ch := (&wifi.BSS{Frequency: 5955}).Channel(); ch != 1 {
+		t.Errorf("BSS.Channel at 5955 MHz = %d, expected 1", ch)
+	}
+	if ch := (&wifi.WifiInterface{Frequency: 5180}).Channel(); ch != 36 {
+		t.Errorf("WifiInterface.Channel at 5180 MHz = %d, expected 36", ch)
+	}
+}
+
+// TestChannelDefinitionValidate tests the validation of center frequencies
+// against the primary frequency and width of a channel.
+func TestChannelDefinitionValidate(t *testing.T) {
+	tests := []struct {
+		def   wifi.ChannelDefinition
+		valid bool
+	}{
+		{wifi.ChannelDefinition{Frequency: 2412, Width: wifi.ChannelWidth20NoHT}, true},
+		{wifi.ChannelDefinition{Frequency: 2412, Width: wifi.ChannelWidth20, CenterFrequency1: 2412}, true},
+		{wifi.ChannelDefinition{Frequency: 2412, Width: wifi.ChannelWidth20, CenterFrequency1: 2422}, false},
+		{wifi.ChannelDefinition{Frequency: 5180, Width: wifi.ChannelWidth40, CenterFrequency1: 5190}, true},
+		{wifi.ChannelDefinition{Frequency: 5200, Width: wifi.ChannelWidth40, CenterFrequency1: 5190}, true},
+		{wifi.ChannelDefinition{Frequency: 5180, Width: wifi.ChannelWidth40, CenterFrequency1: 5210}, false},
+		{wifi.ChannelDefinition{Frequency: 5180, Width: wifi.ChannelWidth40}, false},
+		{wifi.ChannelDefinition{Frequency: 5180, Width: wifi.ChannelWidth80, CenterFrequency1: 5210}, true},
+		{wifi.ChannelDefinition{Frequency: 5240, Width: wifi.ChannelWidth80, CenterFrequency1: 5210}, true},
+		{wifi.ChannelDefinition{Frequency: 5260, Width: wifi.ChannelWidth80, CenterFrequency1: 5210}, false},
+		{wifi.ChannelDefinition{Frequency: 5180, Width: wifi.ChannelWidth80, CenterFrequency1: 5200}, false},
+		{wifi.ChannelDefinition{Frequency: 5320, Width: wifi.ChannelWidth160, CenterFrequency1: 5250}, true},
+		{wifi.ChannelDefinition{Frequency: 5340, Width: wifi.ChannelWidth160, CenterFrequency1: 5250}, false},
+		{wifi.ChannelDefinition{Frequency: 5180, Width: wifi.ChannelWidth80P80, CenterFrequency1: 5210, CenterFrequency2: 5530}, true},
+		{wifi.ChannelDefinition{Frequency: 5180, Width: wifi.ChannelWidth80P80, CenterFrequency1: 5210, CenterFrequency2: 5290}, false},
+		{wifi.ChannelDefinition{Frequency: 5180, Width: wifi.ChannelWidth80P80, CenterFrequency1: 5210}, false},
+		{wifi.ChannelDefinition{Frequency: 5180, Width: wifi.ChannelWidth80, CenterFrequency1: 5210, CenterFrequency2: 5530}, false},
+		{wifi.ChannelDefinition{Frequency: 5180, Width: wifi.ChannelWidth(42)}, false},
+	}
+	for _, tt := range tests {
+		err := tt.def.Validate()
+		if (err == nil) != tt.valid {
+			t.Errorf("Validate(%+v) = %v, expected valid=%v", tt.def, err, tt.valid)
+		}
+	}
+}
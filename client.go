@@ -4,8 +4,12 @@
 package wifi
 
 import (
+	"errors"
 	"fmt"
 	"net"
+	"os"
+	"sync"
+	"time"
 
 	"github.com/mdlayher/genetlink"
 	"github.com/mdlayher/netlink"
@@ -17,31 +21,127 @@ import (
 type Client struct {
 	c             *genetlink.Conn
 	familyID      uint16
+
+	// groups maps nl80211 multicast group names to their IDs.
+	groups        map[string]uint32
+
+	// eventConns are the connections opened to receive multicast
+	// events, closed along with the Client.
+	mu            sync.Mutex
+	eventConns    []*genetlink.Conn
+
+	// subscriptions are the eventConns opened by SubscribeEvents, and
+	// joined the groups added to them by JoinGroup, which later
+	// subscriptions join as well.
+	subscriptions map[*genetlink.Conn]bool
+	joined        []string
+
+	// connections holds the options of the last connection requested
+	// on each interface, by interface index, for use by Roam.
+	connections   map[uint32]*ConnectOptions
+
+	// interfaceCache holds the interfaces looked up by
+	// InterfaceByNameCached, by name.
+	interfaceCache    map[string]cachedInterface
+	interfaceCacheTTL time.Duration
+
+	// connectWiphys holds the wiphys looked up by Connect to check its
+	// options, by wiphy index.
+	connectWiphys map[uint32]*Wiphy
+
+	// opts configures the netlink sockets the Client opens.
+	opts Options
+}
+
+// Options configures the netlink sockets of a Client created by
+// NewClientWithOptions.
+type Options struct {
+	// ReadBufferSize is the size in bytes of the receive buffer of each
+	// socket, or 0 to keep the system default. Large scan dumps in a dense
+	// RF environment can overflow the default buffer and fail with
+	// ENOBUFS.
+	ReadBufferSize int
+
+	// NoENOBUFS sets NETLINK_NO_ENOBUFS, so that a socket whose receive
+	// buffer overflows drops the messages that didn't fit instead of
+	// reporting ENOBUFS. Lost events are then not noticed at all.
+	NoENOBUFS bool
 }
 
 // NewClient opens a generic netlink connection and sets the nl80211 family ID
 func NewClient() (*Client, error) {
-	c, err := genetlink.Dial(nil)
-	if err != nil { return nil, fmt.Errorf("failed to open generic netlink connection: %v", err )}
+	return NewClientWithOptions(Options{})
+}
+
+// NewClientWithOptions is like NewClient, configuring its netlink sockets,
+// including those opened later to receive events, with opts.
+func NewClientWithOptions(opts Options) (*Client, error) {
+	c, err := dialConn(opts)
+	if err != nil { return nil, err }
 	
 	family, err := c.GetFamily(unix.NL80211_GENL_NAME)
 	if err != nil {
 		c.Close()
 		return nil, fmt.Errorf("failed to get nl80211 netlink family ID: %v", err)
 	}
-	return &Client { c: c, familyID: family.ID }, nil
+
+	groups := make(map[string]uint32, len(family.Groups))
+	for _, g := range family.Groups {
+		groups[g.Name] = g.ID
+	}
+	return &Client { c: c, familyID: family.ID, groups: groups, opts: opts }, nil
+}
+
+// dialConn opens a generic netlink connection configured with opts. It is a
+// variable so that tests can replace the connection.
+var dialConn = func(opts Options) (*genetlink.Conn, error) {
+	c, err := genetlink.Dial(nil)
+	if err != nil { return nil, fmt.Errorf("failed to open generic netlink connection: %v", err )}
+
+	if err := opts.apply(c); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// socketOptioner is the part of a netlink connection configured by
+// Options, implemented by *genetlink.Conn.
+type socketOptioner interface {
+	SetReadBuffer(bytes int) error
+	SetOption(option netlink.ConnOption, enable bool) error
+}
+
+// apply configures conn with the options.
+func (opts Options) apply(conn socketOptioner) error {
+	if opts.ReadBufferSize > 0 {
+		if err := conn.SetReadBuffer(opts.ReadBufferSize); err != nil { return fmt.Errorf("failed to set netlink read buffer size: %v", err) }
+	}
+	if opts.NoENOBUFS {
+		if err := conn.SetOption(netlink.NoENOBUFS, true); err != nil { return fmt.Errorf("failed to set NETLINK_NO_ENOBUFS: %v", err) }
+	}
+	return nil
 }
 
-// Close closes the client's generic netlink connection.
+// Close closes the client's generic netlink connection, along with any
+// connections opened to receive events.
 func (c *Client) Close() error {
+	c.mu.Lock()
+	for _, conn := range c.eventConns {
+		conn.Close()
+	}
+	c.eventConns = nil
+	c.subscriptions = nil
+	c.mu.Unlock()
+
 	return c.c.Close() 
 }
 
 // Reset closes and reopens the Client's netlink connection
 func (c *Client) Reset() error {
-	err := c.Close()
+	err := c.c.Close()
 	if err != nil { return fmt.Errorf("Reset: %v", err) }
-	newConn, err := genetlink.Dial(nil)
+	newConn, err := dialConn(c.opts)
 	if err != nil { return fmt.Errorf("Reset: %v", err) }
 	c.c = newConn
 	return nil
@@ -63,6 +163,40 @@ func (c *Client) DumpInterfaces() ([]*WifiInterface, error) {
 	return c.parseGetInterfaceResponse(response)
 }
 
+// InterfacesByPhy returns the wifi interfaces of the wiphy with the given
+// index. The kernel is asked to filter the dump; interfaces of other wiphys
+// are also dropped here for kernels that ignore the filter.
+func (c *Client) InterfacesByPhy(phy int) ([]*WifiInterface, error) {
+	attrs := []AttributeEncoder{
+		WiphyAttribute(uint32(phy)),
+	}
+	msg, err := NewNl80211Message(unix.NL80211_CMD_GET_INTERFACE, attrs)
+	if err != nil { return nil, fmt.Errorf("InterfacesByPhy: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Dump,
+	}
+	response, err := request.Response(c)
+	if err != nil { return nil, fmt.Errorf("InterfacesByPhy: %v", err) }
+
+	wifis, err := c.parseGetInterfaceResponse(response)
+	if err != nil { return nil, fmt.Errorf("InterfacesByPhy: %v", err) }
+	return filterInterfacesByPhy(wifis, uint32(phy)), nil
+}
+
+// filterInterfacesByPhy returns the interfaces of the wiphy with the given
+// index.
+func filterInterfacesByPhy(wifis []*WifiInterface, phy uint32) []*WifiInterface {
+	filtered := wifis[:0]
+	for _, w := range wifis {
+		if w.Phy == phy {
+			filtered = append(filtered, w)
+		}
+	}
+	return filtered
+}
+
 // InterfaceById returns the interface that matches the given interface index.
 func (c *Client) InterfaceById(ifindex uint32) (*WifiInterface, error) {
 	attrs := []AttributeEncoder{
@@ -96,18 +230,106 @@ func (c *Client) InterfaceByName(name string) (*WifiInterface, error) {
 	return c.InterfaceById(uint32(iface.Index))
 }
 
-// SetChannel sets the wifi channel of a given interface
+// channelFrequency returns the frequency in MHz of a channel given to
+// SetChannel. Channel numbers are ambiguous across bands; as before, 1-14
+// are taken to be 2.4 GHz channels and the rest 5 GHz channels.
+func channelFrequency(channel int) (uint32, error) {
+	band := Band5GHz
+	if channel <= 14 {
+		band = Band2GHz
+	}
+	freq, err := ChannelToFrequency(channel, band)
+	if err != nil { return 0, err }
+	return uint32(freq), nil
+}
+
+// CurrentChannel reads back the channel the given interface operates on
+// from the kernel, returning its number, frequency in MHz and width, and
+// updates the Frequency and ChannelWidth of w. The channel number is 0 if
+// the frequency is not a known channel. An interface that is not on a
+// channel, such as a station that isn't connected, returns an error.
+func (c *Client) CurrentChannel(w *WifiInterface) (channel int, freq int, width ChannelWidth, err error) {
+	current, err := c.InterfaceById(w.Index)
+	if err != nil { return 0, 0, 0, fmt.Errorf("CurrentChannel: %v", err) }
+	if current.Frequency == 0 { return 0, 0, 0, fmt.Errorf("CurrentChannel: %s is not on a channel", w.Name) }
+
+	w.Frequency = current.Frequency
+	w.ChannelWidth = current.ChannelWidth
+	return current.Channel(), int(current.Frequency), current.ChannelWidth, nil
+}
+
+// SetChannel sets the wifi channel of a given interface. When the bands of
+// the interface's wiphy can be read, the channel is checked against them
+// first, so that channels the radio or regulatory domain don't allow are
+// reported clearly; otherwise the kernel validates it.
 func (c *Client) SetChannel(w *WifiInterface, channel int) error {
-	ch, ok := WifiChannel[channel]
-	if !ok { return fmt.Errorf("SetChannel: invalid channel provided: %v", channel) }
+	ch, err := channelFrequency(channel)
+	if err != nil { return fmt.Errorf("SetChannel: invalid channel provided: %v", channel) }
+
+	if wiphy, err := c.Wiphy(w); err == nil && len(wiphy.Bands) > 0 {
+		if err := wiphy.checkChannel(channel, ch); err != nil { return fmt.Errorf("SetChannel: %v", err) }
+	}
+
+	return c.setWiphy(w, []AttributeEncoder{WiphyFrequencyAttribute(ch)})
+}
+
+// A ChannelType is a legacy HT channel type, understood by older drivers
+// that don't support NL80211_ATTR_CHANNEL_WIDTH.
+type ChannelType int
+
+const (
+	ChannelTypeNoHT      ChannelType = unix.NL80211_CHAN_NO_HT
+	ChannelTypeHT20      ChannelType = unix.NL80211_CHAN_HT20
+	ChannelTypeHT40Minus ChannelType = unix.NL80211_CHAN_HT40MINUS
+	ChannelTypeHT40Plus  ChannelType = unix.NL80211_CHAN_HT40PLUS
+)
+
+// A ChannelOption configures the channel set by SetFrequency.
+type ChannelOption func(*channelOptions)
+
+type channelOptions struct {
+	channelType *ChannelType
+}
+
+// WithChannelType sets the legacy HT channel type of the channel.
+func WithChannelType(t ChannelType) ChannelOption {
+	return func(o *channelOptions) {
+		o.channelType = &t
+	}
+}
+
+// SetFrequency sets the given interface to the channel with the given
+// frequency in MHz. Unlike SetChannel, it can reach every band, including
+// 6 GHz. The frequency is checked against the interface's wiphy when the
+// wiphy can be read.
+func (c *Client) SetFrequency(w *WifiInterface, freq int, opts ...ChannelOption) error {
+	var o channelOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if freq <= 0 { return fmt.Errorf("SetFrequency: invalid frequency %d MHz", freq) }
+
+	if wiphy, err := c.Wiphy(w); err == nil && len(wiphy.Bands) > 0 {
+		if err := wiphy.checkFrequency(uint32(freq)); err != nil { return fmt.Errorf("SetFrequency: %v", err) }
+	}
 
 	attrs := []AttributeEncoder{
-		InterfaceIndexAttribute(w.Index),
-		WiphyFrequencyAttribute(ch),
+		WiphyFrequencyAttribute(uint32(freq)),
 	}
+	if o.channelType != nil {
+		attrs = append(attrs, ChannelTypeAttribute(uint32(*o.channelType)))
+	}
+	if err := c.setWiphy(w, attrs); err != nil { return fmt.Errorf("SetFrequency: %w", err) }
+	return nil
+}
+
+// setWiphy sends a NL80211_CMD_SET_WIPHY request for the given interface
+// with the given attributes, such as those of a channel.
+func (c *Client) setWiphy(w *WifiInterface, attrs []AttributeEncoder) error {
+	attrs = append([]AttributeEncoder{InterfaceIndexAttribute(w.Index)}, attrs...)
 
 	msg, err := NewNl80211Message(unix.NL80211_CMD_SET_WIPHY, attrs)
-	if err != nil {return fmt.Errorf("SetChannel: %v", err)}
+	if err != nil { return err }
 
 	request := &Nl80211Request{
 		RequestMessage: msg,
@@ -118,15 +340,219 @@ func (c *Client) SetChannel(w *WifiInterface, channel int) error {
 	return err
 }
 
-// SetInterfaceType sets the interface type of the given interface
-func (c *Client) SetInterfaceType(w *WifiInterface, iftype InterfaceType) error {
+// SetChannelDefinition puts the given interface, typically a monitor or AP
+// interface, on a channel of any width, such as an HT40 or 80 MHz channel.
+// The definition is validated before it is sent, and its frequency checked
+// against the interface's wiphy when the bands of the wiphy can be read.
+func (c *Client) SetChannelDefinition(w *WifiInterface, def *ChannelDefinition) error {
+	if err := def.validate(); err != nil { return fmt.Errorf("SetChannelDefinition: %v", err) }
+
+	if wiphy, err := c.Wiphy(w); err == nil && len(wiphy.Bands) > 0 {
+		if err := wiphy.checkFrequency(def.Frequency); err != nil { return fmt.Errorf("SetChannelDefinition: %v", err) }
+	}
+
+	if err := c.setWiphy(w, channelWidthEncoder(def)); err != nil { return fmt.Errorf("SetChannelDefinition: %w", err) }
+	return nil
+}
+
+// SetInterfaceType sets the interface type of the given interface. Monitor
+// interfaces accept WithMonitorFlags, station and AP-VLAN interfaces
+// WithFourAddr.
+func (c *Client) SetInterfaceType(w *WifiInterface, iftype InterfaceType, opts ...InterfaceOption) error {
+	o := newInterfaceOptions(opts)
+	if err := c.checkInterfaceOptions(w.Phy, iftype, o); err != nil { return fmt.Errorf("SetInterfaceType: %v", err) }
+
 	attrs := []AttributeEncoder{
 		InterfaceIndexAttribute(w.Index),
 		InterfaceTypeAttribute(uint32(iftype)),
 	}
+	attrs = append(attrs, o.attributes()...)
 	msg, err := NewNl80211Message(unix.NL80211_CMD_SET_INTERFACE, attrs)
 	if err != nil { return fmt.Errorf("SetInterfaceType: %v", err)}
 
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	c.InvalidateInterfaceCache()
+	if !o.linkDown {
+		_, err = request.Response(c)
+		return err
+	}
+
+	conn, err := dialRoute()
+	if err != nil { return fmt.Errorf("SetInterfaceType: %v", err) }
+	defer conn.Close()
+
+	return withLinkDown(conn, w, func() error {
+		_, err := request.Response(c)
+		return err
+	})
+}
+
+// Set4Addr enables or disables the 4-address (WDS) frame format on the given
+// station or AP-VLAN interface. Most drivers only allow the change while the
+// interface is down. CreateInterface accepts WithFourAddr to set it when the
+// interface is created.
+func (c *Client) Set4Addr(w *WifiInterface, enabled bool) error {
+	o := newInterfaceOptions([]InterfaceOption{WithFourAddr(enabled)})
+	if err := o.validate(w.Type); err != nil { return fmt.Errorf("Set4Addr: %v", err) }
+
+	attrs := []AttributeEncoder{InterfaceIndexAttribute(w.Index)}
+	attrs = append(attrs, o.attributes()...)
+	msg, err := NewNl80211Message(unix.NL80211_CMD_SET_INTERFACE, attrs)
+	if err != nil { return fmt.Errorf("Set4Addr: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	c.InvalidateInterfaceCache()
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("Set4Addr: %w", err) }
+	return nil
+}
+
+// An InterfaceOption configures an interface created by CreateInterface or
+// changed by SetInterfaceType.
+type InterfaceOption func(*interfaceOptions)
+
+type interfaceOptions struct {
+	monitorFlags *MonitorFlags
+	linkDown     bool
+	fourAddr     *bool
+}
+
+// WithMonitorFlags sets the monitor flags of a monitor interface. It may
+// only be used with InterfaceTypeMonitor.
+func WithMonitorFlags(flags MonitorFlags) InterfaceOption {
+	return func(o *interfaceOptions) {
+		o.monitorFlags = &flags
+	}
+}
+
+// WithLinkDown makes SetInterfaceType bring the interface down for the
+// change, as most drivers require, and back up afterwards if it was up. It
+// doesn't apply to CreateInterface, whose new interfaces start down.
+func WithLinkDown() InterfaceOption {
+	return func(o *interfaceOptions) {
+		o.linkDown = true
+	}
+}
+
+// WithFourAddr enables or disables the 4-address (WDS) frame format, which
+// lets a station interface be bridged or an AP-VLAN interface serve such a
+// station. It may only be used with InterfaceTypeStation and
+// InterfaceTypeAPVLAN.
+func WithFourAddr(enabled bool) InterfaceOption {
+	return func(o *interfaceOptions) {
+		o.fourAddr = &enabled
+	}
+}
+
+func newInterfaceOptions(opts []InterfaceOption) *interfaceOptions {
+	o := &interfaceOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// validate reports an error if the options don't apply to an interface of
+// the given type.
+func (o *interfaceOptions) validate(iftype InterfaceType) error {
+	if o.monitorFlags != nil && iftype != InterfaceTypeMonitor {
+		return fmt.Errorf("monitor flags given for %v interface", iftype)
+	}
+	if o.fourAddr != nil && iftype != InterfaceTypeStation && iftype != InterfaceTypeAPVLAN {
+		return fmt.Errorf("4-address mode given for %v interface", iftype)
+	}
+	return nil
+}
+
+// checkInterfaceOptions validates o for an interface of the given type on
+// the wiphy with index phy. Active monitoring needs driver support, which
+// is checked when the wiphy can be read.
+func (c *Client) checkInterfaceOptions(phy uint32, iftype InterfaceType, o *interfaceOptions) error {
+	if err := o.validate(iftype); err != nil { return err }
+	if o.monitorFlags == nil || *o.monitorFlags&MonitorActive == 0 { return nil }
+
+	wiphy, err := c.wiphyByIndex(phy)
+	if err != nil { return nil }
+	return wiphy.checkMonitorFlags(*o.monitorFlags)
+}
+
+// attributes returns the attributes encoding the options.
+func (o *interfaceOptions) attributes() []AttributeEncoder {
+	var attrs []AttributeEncoder
+	if o.monitorFlags != nil {
+		attrs = append(attrs, NestedFlagsAttribute(unix.NL80211_ATTR_MNTR_FLAGS, o.monitorFlags.monitorFlagTypes()...))
+	}
+	if o.fourAddr != nil {
+		attrs = append(attrs, FourAddrAttribute(*o.fourAddr))
+	}
+	return attrs
+}
+
+// MonitorFlags select which frames a monitor interface captures.
+type MonitorFlags uint32
+
+const (
+	// MonitorFCSFail passes frames with a bad FCS.
+	MonitorFCSFail MonitorFlags = 1 << unix.NL80211_MNTR_FLAG_FCSFAIL
+
+	// MonitorPLCPFail passes frames with a bad PLCP header.
+	MonitorPLCPFail MonitorFlags = 1 << unix.NL80211_MNTR_FLAG_PLCPFAIL
+
+	// MonitorControl passes control frames.
+	MonitorControl MonitorFlags = 1 << unix.NL80211_MNTR_FLAG_CONTROL
+
+	// MonitorOtherBSS disables BSSID filtering.
+	MonitorOtherBSS MonitorFlags = 1 << unix.NL80211_MNTR_FLAG_OTHER_BSS
+
+	// MonitorActive makes the interface acknowledge unicast frames sent
+	// to its address.
+	MonitorActive MonitorFlags = 1 << unix.NL80211_MNTR_FLAG_ACTIVE
+)
+
+// monitorFlagTypes returns the NL80211_MNTR_FLAG_* attribute types of the
+// flags that are set.
+func (f MonitorFlags) monitorFlagTypes() []uint16 {
+	var types []uint16
+	for typ := uint16(1); typ <= unix.NL80211_MNTR_FLAG_MAX; typ++ {
+		if f&(1<<typ) != 0 {
+			types = append(types, typ)
+		}
+	}
+	return types
+}
+
+// SetMonitorMode sets the given interface to monitor mode, capturing the
+// frames selected by flags.
+func (c *Client) SetMonitorMode(w *WifiInterface, flags MonitorFlags) error {
+	err := c.SetInterfaceType(w, InterfaceTypeMonitor, WithMonitorFlags(flags))
+	if err != nil { return fmt.Errorf("SetMonitorMode: %w", err) }
+	return nil
+}
+
+// checkMonitorFlags reports an error if the device doesn't support the
+// given monitor flags.
+func (w *Wiphy) checkMonitorFlags(flags MonitorFlags) error {
+	if flags&MonitorActive != 0 && !w.SupportsFeature(unix.NL80211_FEATURE_ACTIVE_MONITOR) {
+		return fmt.Errorf("active monitor not supported on phy %d", w.Index)
+	}
+	return nil
+}
+
+// SetPowerSave enables or disables power save mode on the given interface
+func (c *Client) SetPowerSave(w *WifiInterface, enabled bool) error {
+	attrs := []AttributeEncoder{
+		InterfaceIndexAttribute(w.Index),
+		PowerSaveStateAttribute(enabled),
+	}
+	msg, err := NewNl80211Message(unix.NL80211_CMD_SET_POWER_SAVE, attrs)
+	if err != nil { return fmt.Errorf("SetPowerSave: %v", err)}
+
 	request := &Nl80211Request{
 		RequestMessage: msg,
 		Flags: netlink.Request | netlink.Acknowledge,
@@ -135,25 +561,153 @@ func (c *Client) SetInterfaceType(w *WifiInterface, iftype InterfaceType) error
 	return err
 }
 
+// A TxPowerSetting selects how the transmit power of a device is set.
+type TxPowerSetting int
+
+const (
+	// TxPowerAutomatic lets the driver choose the transmit power.
+	TxPowerAutomatic TxPowerSetting = unix.NL80211_TX_POWER_AUTOMATIC
+
+	// TxPowerLimited caps the transmit power at the given level.
+	TxPowerLimited TxPowerSetting = unix.NL80211_TX_POWER_LIMITED
+
+	// TxPowerFixed fixes the transmit power at the given level.
+	TxPowerFixed TxPowerSetting = unix.NL80211_TX_POWER_FIXED
+)
+
+// String returns the string representation of a TxPowerSetting.
+func (s TxPowerSetting) String() string {
+	switch s {
+	case TxPowerAutomatic:
+		return "automatic"
+	case TxPowerLimited:
+		return "limited"
+	case TxPowerFixed:
+		return "fixed"
+	default:
+		return fmt.Sprintf("unknown(%d)", s)
+	}
+}
+
+// SetTxPower sets the transmit power of the wiphy underlying the given
+// interface to dBm, which is ignored for TxPowerAutomatic. Levels the
+// device or regulatory domain don't allow are rejected by the kernel, in
+// which case the error includes the maximum for the current channel when
+// the wiphy reports it. Levels that are allowed may still be clamped by the
+// driver, so the power actually applied should be read with GetTxPower.
+func (c *Client) SetTxPower(w *WifiInterface, setting TxPowerSetting, dBm int) error {
+	attrs := []AttributeEncoder{
+		TxPowerSettingAttribute(uint32(setting)),
+	}
+	if setting != TxPowerAutomatic {
+		attrs = append(attrs, TxPowerLevelAttribute(uint32(int32(dBm*100))))
+	}
+
+	err := c.setWiphy(w, attrs)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, unix.EINVAL):
+		if wiphy, werr := c.Wiphy(w); werr == nil {
+			if freq, ok := wiphy.Frequency(w.Frequency); ok {
+				return fmt.Errorf("SetTxPower: %d dBm not allowed, maximum on %d MHz is %g dBm: %w", dBm, w.Frequency, freq.MaxTxPower, err)
+			}
+		}
+		return fmt.Errorf("SetTxPower: %d dBm not allowed: %w", dBm, err)
+	default:
+		return fmt.Errorf("SetTxPower: %w", err)
+	}
+}
+
+// GetTxPower returns the transmit power currently applied on the given
+// interface in dBm, which can differ from the level requested with
+// SetTxPower once regulatory and device limits are applied, including
+// fractions of a dBm. It is 0 if the driver doesn't report it.
+func (c *Client) GetTxPower(w *WifiInterface) (float64, error) {
+	wifi, err := c.InterfaceById(w.Index)
+	if err != nil { return 0, fmt.Errorf("GetTxPower: %w", err) }
+	return wifi.TxPower, nil
+}
+
+// PowerSave reports whether power save mode is enabled on the given interface
+func (c *Client) PowerSave(w *WifiInterface) (bool, error) {
+	attrs := []AttributeEncoder{
+		InterfaceIndexAttribute(w.Index),
+	}
+	msg, err := NewNl80211Message(unix.NL80211_CMD_GET_POWER_SAVE, attrs)
+	if err != nil { return false, fmt.Errorf("PowerSave: %v", err)}
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request,
+	}
+	response, err := request.Response(c)
+	if err != nil { return false, fmt.Errorf("PowerSave: %v", err)}
+
+	return c.parseGetPowerSaveResponse(response)
+}
+
+var (
+	// ErrInterfaceExists is returned by CreateInterface when an interface
+	// with the requested name already exists.
+	ErrInterfaceExists = errors.New("interface already exists")
+
+	// ErrInterfaceTypeNotSupported is returned by CreateInterface when the
+	// wiphy can't add an interface of the requested type, either because
+	// it doesn't support the type or because its interface combinations
+	// don't allow another one alongside the existing interfaces.
+	ErrInterfaceTypeNotSupported = errors.New("interface type not supported")
+)
+
 // NewInterface creates a new wifi interface using the underlying PHY of the provided interface
 func (c *Client) NewInterface(w *WifiInterface, ifname string, iftype InterfaceType) error {
+	_, err := c.CreateInterface(int(w.Phy), ifname, iftype)
+	return err
+}
+
+// CreateInterface creates a new virtual interface of the given type and name
+// on the wiphy with index phy, returning the interface as reported by the
+// kernel. Monitor interfaces accept WithMonitorFlags, station and AP-VLAN
+// interfaces WithFourAddr.
+func (c *Client) CreateInterface(phy int, name string, typ InterfaceType, opts ...InterfaceOption) (*WifiInterface, error) {
+	o := newInterfaceOptions(opts)
+	if o.linkDown { return nil, errors.New("CreateInterface: WithLinkDown only applies to SetInterfaceType") }
+	if err := c.checkInterfaceOptions(uint32(phy), typ, o); err != nil { return nil, fmt.Errorf("CreateInterface: %v", err) }
+
 	attrs := []AttributeEncoder{
-		InterfaceTypeAttribute(uint32(InterfaceTypeMonitor)),
-		InterfaceNameAttribute(ifname),
-		WiphyAttribute(w.Phy),
+		WiphyAttribute(uint32(phy)),
+		InterfaceNameAttribute(name),
+		InterfaceTypeAttribute(uint32(typ)),
 	}
+	attrs = append(attrs, o.attributes()...)
 	msg, err := NewNl80211Message(unix.NL80211_CMD_NEW_INTERFACE, attrs)
-	if err != nil { return fmt.Errorf("NewInterface: %v", err)}
+	if err != nil { return nil, fmt.Errorf("CreateInterface: %v", err) }
 
+	// The kernel answers with the attributes of the new interface, which
+	// also serves as the acknowledgement.
 	request := &Nl80211Request{
 		RequestMessage: msg,
-		Flags: netlink.Request | netlink.Acknowledge,
+		Flags: netlink.Request,
 	}
-	_, err = request.Response(c)
-	return err
+	c.InvalidateInterfaceCache()
+	response, err := request.Response(c)
+	switch {
+	case errors.Is(err, unix.EEXIST):
+		return nil, fmt.Errorf("CreateInterface: %q: %w", name, ErrInterfaceExists)
+	case errors.Is(err, unix.EOPNOTSUPP):
+		return nil, fmt.Errorf("CreateInterface: %v on phy %d: %w", typ, phy, ErrInterfaceTypeNotSupported)
+	case err != nil:
+		return nil, fmt.Errorf("CreateInterface: %w", err)
+	}
+
+	wifis, err := c.parseGetInterfaceResponse(response)
+	if err != nil { return nil, fmt.Errorf("CreateInterface: %v", err) }
+	if len(wifis) == 0 { return nil, fmt.Errorf("CreateInterface: no interface in response") }
+	return wifis[0], nil
 }
 
-// DeleteInterface deletes a wireless interface
+// DeleteInterface deletes a wireless interface. If the interface no longer
+// exists, the error wraps os.ErrNotExist so that cleanup code can ignore it.
 func (c *Client) DeleteInterface(w *WifiInterface) error {
 	attrs := []AttributeEncoder{
 		InterfaceIndexAttribute(w.Index),
@@ -165,8 +719,292 @@ func (c *Client) DeleteInterface(w *WifiInterface) error {
 		RequestMessage: msg,
 		Flags: netlink.Request | netlink.Acknowledge,
 	}
+	c.InvalidateInterfaceCache()
 	_, err = request.Response(c)
-	return err
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, unix.ENODEV):
+		return fmt.Errorf("DeleteInterface: %s: %w", w.Name, os.ErrNotExist)
+	default:
+		return fmt.Errorf("DeleteInterface: %w", err)
+	}
+}
+
+// ScanResults returns the BSSs currently held in the scan cache of the given interface.
+// If some BSSs can't be parsed, the others are returned along with an error
+// wrapping a *PartialResultsError.
+func (c *Client) ScanResults(w *WifiInterface) ([]*BSS, error) {
+	attrs := []AttributeEncoder{
+		InterfaceIndexAttribute(w.Index),
+	}
+	msg, err := NewNl80211Message(unix.NL80211_CMD_GET_SCAN, attrs)
+	if err != nil { return nil, fmt.Errorf("ScanResults: %v", err)}
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Dump,
+	}
+
+	response, err := request.Response(c)
+	if err != nil { return nil, fmt.Errorf("ScanResults: %v", err)}
+
+	bsss, err := c.parseGetScanResponse(response)
+	if err != nil { return bsss, fmt.Errorf("ScanResults: %w", err) }
+	return bsss, nil
+}
+
+// ConnectedBSS returns the BSS the given interface is associated with, or
+// the IBSS it has joined. If there is none, the error wraps os.ErrNotExist.
+func (c *Client) ConnectedBSS(w *WifiInterface) (*BSS, error) {
+	bsss, err := c.ScanResults(w)
+	if err != nil && !isPartialResults(err) { return nil, fmt.Errorf("ConnectedBSS: %v", err) }
+
+	for _, bss := range bsss {
+		if bss.Status == BSSStatusAssociated || bss.Status == BSSStatusIBSSJoined {
+			return bss, nil
+		}
+	}
+	return nil, fmt.Errorf("ConnectedBSS: %s is not connected: %w", w.Name, os.ErrNotExist)
+}
+
+// CurrentSSID returns the SSID of the network the given interface is
+// connected to. If it isn't connected, the error wraps os.ErrNotExist.
+func (c *Client) CurrentSSID(w *WifiInterface) (string, error) {
+	bss, err := c.ConnectedBSS(w)
+	if err != nil { return "", fmt.Errorf("CurrentSSID: %w", err) }
+	return bss.SSID, nil
+}
+
+// DumpStations returns information about every station associated with the given interface.
+// If some stations can't be parsed, the others are returned along with an
+// error wrapping a *PartialResultsError.
+func (c *Client) DumpStations(w *WifiInterface) ([]*StationInfo, error) {
+	attrs := []AttributeEncoder{
+		InterfaceIndexAttribute(w.Index),
+	}
+	msg, err := NewNl80211Message(unix.NL80211_CMD_GET_STATION, attrs)
+	if err != nil { return nil, fmt.Errorf("DumpStations: %v", err)}
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Dump,
+	}
+
+	response, err := request.Response(c)
+	if err != nil { return nil, fmt.Errorf("DumpStations: %v", err)}
+
+	stations, err := c.parseGetStationResponse(response)
+	if err != nil { return stations, fmt.Errorf("DumpStations: %w", err) }
+	return stations, nil
+}
+
+// mgmtSubtypeDeauth is the 802.11 management frame subtype of a
+// deauthentication frame.
+const mgmtSubtypeDeauth = 12
+
+// Deauthenticate deauthenticates the station with the given hardware
+// address, sending it a deauthentication frame with the given IEEE 802.11
+// reason code. On interfaces serving stations, such as an AP, this kicks
+// the client off; on a station interface mac is the BSSID of the AP to
+// leave.
+func (c *Client) Deauthenticate(w *WifiInterface, mac net.HardwareAddr, reason uint16) error {
+	cmd, attrs, err := deauthAttrs(w, mac, reason)
+	if err != nil { return fmt.Errorf("Deauthenticate: %v", err) }
+
+	msg, err := NewNl80211Message(cmd, attrs)
+	if err != nil { return fmt.Errorf("Deauthenticate: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("Deauthenticate: %w", err) }
+	return nil
+}
+
+// deauthAttrs returns the command and attributes deauthenticating mac from
+// w. Interfaces that serve stations remove them with NL80211_CMD_DEL_STATION,
+// while a client leaves its AP with NL80211_CMD_DEAUTHENTICATE.
+func deauthAttrs(w *WifiInterface, mac net.HardwareAddr, reason uint16) (int, []AttributeEncoder, error) {
+	if len(mac) != 6 { return 0, nil, fmt.Errorf("invalid hardware address: %v", mac) }
+
+	attrs := []AttributeEncoder{
+		InterfaceIndexAttribute(w.Index),
+		MacAttribute(mac),
+		ReasonCodeAttribute(reason),
+	}
+	switch w.Type {
+	case InterfaceTypeAP, InterfaceTypeAPVLAN, InterfaceTypeP2PGroupOwner, InterfaceTypeMeshPoint:
+		attrs = append(attrs, MgmtSubtypeAttribute(mgmtSubtypeDeauth))
+		return unix.NL80211_CMD_DEL_STATION, attrs, nil
+	case InterfaceTypeStation, InterfaceTypeP2PClient:
+		return unix.NL80211_CMD_DEAUTHENTICATE, attrs, nil
+	default:
+		return 0, nil, fmt.Errorf("can't deauthenticate on %v interface %s", w.Type, w.Name)
+	}
+}
+
+// settableStationFlags are the station flags SetStationFlags can change.
+const settableStationFlags = StationFlagAuthorized | StationFlagShortPreamble | StationFlagWME | StationFlagMFP | StationFlagAuthenticated | StationFlagAssociated
+
+// SetStationFlags updates the flags of the station with the given hardware
+// address: the flags in mask are set if they are also in set, and cleared
+// otherwise. An AP whose stations are authenticated by an external
+// authenticator uses this to authorize a station once its 4-way handshake
+// has completed:
+//
+//	c.SetStationFlags(w, mac, wifi.StationFlagAuthorized, wifi.StationFlagAuthorized)
+func (c *Client) SetStationFlags(w *WifiInterface, mac net.HardwareAddr, set, mask StationFlags) error {
+	attrs, err := stationFlagsAttrs(w, mac, set, mask)
+	if err != nil { return fmt.Errorf("SetStationFlags: %v", err) }
+
+	msg, err := NewNl80211Message(unix.NL80211_CMD_SET_STATION, attrs)
+	if err != nil { return fmt.Errorf("SetStationFlags: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("SetStationFlags: %w", err) }
+	return nil
+}
+
+// stationFlagsAttrs returns the NL80211_CMD_SET_STATION attributes updating
+// the flags of mac.
+func stationFlagsAttrs(w *WifiInterface, mac net.HardwareAddr, set, mask StationFlags) ([]AttributeEncoder, error) {
+	if len(mac) != 6 { return nil, fmt.Errorf("invalid hardware address: %v", mac) }
+	if mask == 0 { return nil, errors.New("no station flags to update") }
+	if mask&^settableStationFlags != 0 { return nil, fmt.Errorf("station flags %v can't be changed", mask&^settableStationFlags) }
+	if set&^mask != 0 { return nil, fmt.Errorf("station flags %v are not in the mask", set&^mask) }
+
+	return []AttributeEncoder{
+		InterfaceIndexAttribute(w.Index),
+		MacAttribute(mac),
+		stationFlagsAttribute(set, mask),
+	}, nil
+}
+
+// stationFlagsAttribute returns the NL80211_ATTR_STA_FLAGS2 attribute
+// setting the flags of set in mask and clearing the others. It holds a
+// struct nl80211_sta_flag_update: the mask followed by the flags to set.
+func stationFlagsAttribute(set, mask StationFlags) AttributeEncoder {
+	update := append(nlenc.Uint32Bytes(uint32(mask)), nlenc.Uint32Bytes(uint32(set))...)
+	return NewAttributeFactory[[]byte](unix.NL80211_ATTR_STA_FLAGS2)(update)
+}
+
+// A PartialResultsError is returned, along with the entries that could be
+// parsed, when some messages of a dump couldn't be. Errors holds an error
+// for each message that was skipped.
+type PartialResultsError struct {
+	Errors []error
+}
+
+// Error implements error.
+func (e *PartialResultsError) Error() string {
+	if len(e.Errors) == 1 {
+		return fmt.Sprintf("skipped 1 message: %v", e.Errors[0])
+	}
+	return fmt.Sprintf("skipped %d messages, first: %v", len(e.Errors), e.Errors[0])
+}
+
+// isPartialResults reports whether err only reports entries of a dump that
+// were skipped, so that the others can still be used.
+func isPartialResults(err error) bool {
+	var perr *PartialResultsError
+	return errors.As(err, &perr)
+}
+
+// partialResults returns a *PartialResultsError holding errs, or nil if
+// there are none.
+func partialResults(errs []error) error {
+	if len(errs) == 0 { return nil }
+	return &PartialResultsError{Errors: errs}
+}
+
+// parseGetStationResponse parses the responses to a NL80211_CMD_GET_STATION
+// request. Messages that fail to parse are skipped and reported in a
+// *PartialResultsError.
+func (c *Client) parseGetStationResponse(msgs []genetlink.Message) ([]*StationInfo, error) {
+	stations := make([]*StationInfo, 0, len(msgs))
+	var errs []error
+	for _, m := range msgs {
+		info, err := parseStation(m)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		stations = append(stations, info)
+	}
+	return stations, partialResults(errs)
+}
+
+// parseStation parses a single message of a NL80211_CMD_GET_STATION
+// response.
+func parseStation(m genetlink.Message) (*StationInfo, error) {
+	attrs, err := netlink.UnmarshalAttributes(m.Data)
+	if err != nil {
+		return nil, fmt.Errorf("parseGetStationResponse: failed to unpack attributes: %v", err)
+	}
+	info := &StationInfo{}
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_ATTR_MAC:
+			info.HardwareAddr = net.HardwareAddr(a.Data)
+		case unix.NL80211_ATTR_STA_INFO:
+			nattrs, err := netlink.UnmarshalAttributes(a.Data)
+			if err != nil { return nil, fmt.Errorf("parseGetStationResponse: %v", err) }
+
+			if err := info.parseAttributes(nattrs); err != nil {
+				return nil, fmt.Errorf("parseGetStationResponse: %s: %v", info.HardwareAddr, err)
+			}
+		}
+	}
+	return info, nil
+}
+
+// parseGetPowerSaveResponse parses the response to a NL80211_CMD_GET_POWER_SAVE request
+func (c *Client) parseGetPowerSaveResponse(msgs []genetlink.Message) (bool, error) {
+	for _, m := range msgs {
+		attrs, err := netlink.UnmarshalAttributes(m.Data)
+		if err != nil {
+			return false, fmt.Errorf("parseGetPowerSaveResponse: failed to unpack attributes: %v", err)
+		}
+		for _, a := range attrs {
+			if a.Type == unix.NL80211_ATTR_PS_STATE {
+				return nlenc.Uint32(a.Data) == unix.NL80211_PS_ENABLED, nil
+			}
+		}
+	}
+	return false, fmt.Errorf("parseGetPowerSaveResponse: no power save state in response")
+}
+
+// parseGetScanResponse parses the responses to a NL80211_CMD_GET_SCAN
+// request. Messages that fail to parse are skipped and reported in a
+// *PartialResultsError.
+func (c *Client) parseGetScanResponse(msgs []genetlink.Message) ([]*BSS, error) {
+	bsss := make([]*BSS, 0, len(msgs))
+	var errs []error
+	for _, m := range msgs {
+		attrs, err := netlink.UnmarshalAttributes(m.Data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("parseGetScanResponse: failed to unpack attributes: %v", err))
+			continue
+		}
+		for _, a := range attrs {
+			if a.Type != unix.NL80211_ATTR_BSS { continue }
+
+			bss, err := parseBSS(a.Data)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("parseGetScanResponse: %v", err))
+				continue
+			}
+			bsss = append(bsss, bss)
+		}
+	}
+	return bsss, partialResults(errs)
 }
 
 // parseGetInterfaceResponse parses the responses to a NL80211_CMD_GET_INTERFACE request
@@ -177,30 +1015,43 @@ func (c *Client) parseGetInterfaceResponse(msgs []genetlink.Message) ([]*WifiInt
 		if err != nil { 
 			return nil, fmt.Errorf("parseGetInterfaceResponse: failed to unpack attributes: %v", err) 
 		}
-		wifi := &WifiInterface{}
-		for _, a := range attrs {
-			switch a.Type {
-			case unix.NL80211_ATTR_IFINDEX:
-				wifi.Index = nlenc.Uint32(a.Data)
-			case unix.NL80211_ATTR_IFNAME:
-				wifi.Name = nlenc.String(a.Data) 
-			case unix.NL80211_ATTR_MAC:
-				wifi.HardwareAddr = net.HardwareAddr(a.Data)
-			case unix.NL80211_ATTR_WIPHY:
-				wifi.Phy = nlenc.Uint32(a.Data)
-			case unix.NL80211_ATTR_IFTYPE:
-				wifi.Type = InterfaceType(nlenc.Uint32(a.Data)) 
-			case unix.NL80211_ATTR_WDEV:
-				wifi.Device = nlenc.Uint64(a.Data)
-			case unix.NL80211_ATTR_WIPHY_FREQ:
-				wifi.Frequency = nlenc.Uint32(a.Data)
-			}
-		}
-		wifis = append(wifis, wifi)
+		wifis = append(wifis, parseInterface(attrs))
 	}
 	return wifis, nil
 }
 
+// parseInterface parses the attributes describing an interface, as found in
+// NL80211_CMD_GET_INTERFACE responses and NL80211_CMD_NEW_INTERFACE
+// notifications.
+func parseInterface(attrs []netlink.Attribute) *WifiInterface {
+	wifi := &WifiInterface{}
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_ATTR_IFINDEX:
+			wifi.Index = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_IFNAME:
+			wifi.Name = nlenc.String(a.Data) 
+		case unix.NL80211_ATTR_MAC:
+			wifi.HardwareAddr = net.HardwareAddr(a.Data)
+		case unix.NL80211_ATTR_WIPHY:
+			wifi.Phy = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_IFTYPE:
+			wifi.Type = InterfaceType(nlenc.Uint32(a.Data)) 
+		case unix.NL80211_ATTR_WDEV:
+			wifi.Device = nlenc.Uint64(a.Data)
+		case unix.NL80211_ATTR_WIPHY_FREQ:
+			wifi.Frequency = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_CHANNEL_WIDTH:
+			wifi.ChannelWidth = ChannelWidth(nlenc.Uint32(a.Data))
+		case unix.NL80211_ATTR_WIPHY_TX_POWER_LEVEL:
+			wifi.TxPower = float64(int32(nlenc.Uint32(a.Data))) / 100
+		case unix.NL80211_ATTR_4ADDR:
+			wifi.FourAddr = len(a.Data) == 1 && a.Data[0] != 0
+		}
+	}
+	return wifi
+}
+
 // NewNl80211Message takes a command and a list of attributes and returns 
 // a generic netlink message containing the encoded attributes. 
 func NewNl80211Message(cmd int, lst []AttributeEncoder) (*genetlink.Message, error) {
@@ -233,14 +1084,16 @@ func (r Nl80211Request) Response(c *Client) ([]genetlink.Message, error){
 	if r.err != nil { return nil, r.err }
 
 	_, err := c.c.Send(*r.RequestMessage, c.familyID, r.Flags)
-	if err != nil { return nil, fmt.Errorf("Response: %v", err) }
+	if err != nil { return nil, fmt.Errorf("Response: %w", err) }
 
 	msgs, nlmsgs, err := c.c.Receive()
-	if err != nil { return nil, fmt.Errorf("Response: %v", err) }
+	if err != nil { return nil, fmt.Errorf("Response: %w", err) }
 
 	// At this point, since err is nil we should be able to assume
 	// any message of type Error is an ACK response and drop it.
-	if nlmsgs[0].Header.Type == netlink.Error {
+	// An empty dump leaves no messages at all once netlink drops the
+	// multipart done message.
+	if len(nlmsgs) > 0 && nlmsgs[0].Header.Type == netlink.Error {
 		return msgs[1:], nil
 	}
 
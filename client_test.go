@@ -1,11 +1,16 @@
 package wifi_test
 
 import (
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/bryancoxwell/wifi"
 	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"github.com/mdlayher/netlink/nltest"
 	"golang.org/x/sys/unix"
 )
 
@@ -113,3 +118,335 @@ func TestNewNl80211MessageSetInterface(t *testing.T) {
 		t.Errorf(packetMismatchMessage, expectedMessage, *msg)
 	}
 }
+
+// TestDeauthAttrs tests that deauthentication picks the command matching
+// the interface type.
+func TestDeauthAttrs(t *testing.T) {
+	mac := []byte{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}
+	tests := []struct {
+		iftype     wifi.InterfaceType
+		cmd        int
+		subtype    bool
+		shouldFail bool
+	}{
+		{iftype: wifi.InterfaceTypeAP, cmd: unix.NL80211_CMD_DEL_STATION, subtype: true},
+		{iftype: wifi.InterfaceTypeP2PGroupOwner, cmd: unix.NL80211_CMD_DEL_STATION, subtype: true},
+		{iftype: wifi.InterfaceTypeStation, cmd: unix.NL80211_CMD_DEAUTHENTICATE},
+		{iftype: wifi.InterfaceTypeMonitor, shouldFail: true},
+	}
+	for _, tt := range tests {
+		w := &wifi.WifiInterface{Index: 3, Name: "wlan0", Type: tt.iftype}
+		cmd, encoders, err := wifi.DeauthAttrs(w, mac, 3)
+		if tt.shouldFail {
+			if err == nil {
+				t.Errorf("%v: expected an error", tt.iftype)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", tt.iftype, err)
+		}
+		if cmd != tt.cmd {
+			t.Errorf("%v: got command %d, expected %d", tt.iftype, cmd, tt.cmd)
+		}
+
+		attrs := encodeAttributes(t, encoders)
+		if got := attrs[unix.NL80211_ATTR_REASON_CODE]; len(got) != 2 || got[0] != 3 {
+			t.Errorf("%v: got reason code %v, expected 3", tt.iftype, got)
+		}
+		if _, ok := attrs[unix.NL80211_ATTR_MGMT_SUBTYPE]; ok != tt.subtype {
+			t.Errorf("%v: got management subtype %v, expected %v", tt.iftype, ok, tt.subtype)
+		}
+	}
+
+	w := &wifi.WifiInterface{Index: 3, Type: wifi.InterfaceTypeAP}
+	if _, _, err := wifi.DeauthAttrs(w, mac[:5], 3); err == nil {
+		t.Error("expected an error for a short hardware address")
+	}
+}
+
+// TestStationFlagsAttrs tests the encoding of a station flag update.
+func TestStationFlagsAttrs(t *testing.T) {
+	w := &wifi.WifiInterface{Index: 3, Type: wifi.InterfaceTypeAP}
+	mac := []byte{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}
+
+	mask := wifi.StationFlagAuthorized | wifi.StationFlagWME
+	encoders, err := wifi.StationFlagsAttrs(w, mac, wifi.StationFlagAuthorized, mask)
+	if err != nil {
+		t.Fatalf("StationFlagsAttrs: %v", err)
+	}
+	attrs := encodeAttributes(t, encoders)
+	update := attrs[unix.NL80211_ATTR_STA_FLAGS2]
+	if len(update) != 8 {
+		t.Fatalf("got %d bytes of flag update, expected 8", len(update))
+	}
+	if got := nlenc.Uint32(update[:4]); got != 1<<unix.NL80211_STA_FLAG_AUTHORIZED|1<<unix.NL80211_STA_FLAG_WME {
+		t.Errorf("unexpected mask %#x", got)
+	}
+	if got := nlenc.Uint32(update[4:]); got != 1<<unix.NL80211_STA_FLAG_AUTHORIZED {
+		t.Errorf("unexpected flags %#x", got)
+	}
+
+	if _, err := wifi.StationFlagsAttrs(w, mac, wifi.StationFlagWME, wifi.StationFlagAuthorized); err == nil {
+		t.Error("expected an error for flags outside the mask")
+	}
+	if _, err := wifi.StationFlagsAttrs(w, mac, 0, wifi.StationFlagTDLSPeer); err == nil {
+		t.Error("expected an error for a flag that can't be changed")
+	}
+	if got := mask.String(); got != "authorized|WME" {
+		t.Errorf("String() = %q, expected %q", got, "authorized|WME")
+	}
+}
+
+// TestParseGetInterfaceResponseChannel tests the parsing of the transmit
+// power of an interface, converted from mBm to dBm, and its channel.
+func TestParseGetInterfaceResponseChannel(t *testing.T) {
+	msg := genetlink.Message{
+		Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_ATTR_IFINDEX, Data: nlenc.Uint32Bytes(3)},
+			{Type: unix.NL80211_ATTR_WIPHY_TX_POWER_LEVEL, Data: nlenc.Uint32Bytes(1950)},
+			{Type: unix.NL80211_ATTR_WIPHY_FREQ, Data: nlenc.Uint32Bytes(5180)},
+			{Type: unix.NL80211_ATTR_CHANNEL_WIDTH, Data: nlenc.Uint32Bytes(unix.NL80211_CHAN_WIDTH_80)},
+		}),
+	}
+	wifis, err := (&wifi.Client{}).ParseGetInterfaceResponse([]genetlink.Message{msg})
+	if err != nil {
+		t.Fatalf("ParseGetInterfaceResponse: %v", err)
+	}
+	if len(wifis) != 1 {
+		t.Fatalf("got %d interfaces, expected 1", len(wifis))
+	}
+	w := wifis[0]
+	if w.TxPower != 19.5 {
+		t.Errorf("TxPower = %g, expected 19.5 dBm", w.TxPower)
+	}
+	if w.Frequency != 5180 || w.Channel() != 36 || w.ChannelWidth != wifi.ChannelWidth80 {
+		t.Errorf("got channel %d at %d MHz with width %v, expected channel 36 at 5180 MHz with width 80 MHz", w.Channel(), w.Frequency, w.ChannelWidth)
+	}
+}
+
+// TestFourAddr tests the encoding of the 4-address mode of an interface, the
+// interface types accepting it, and its parsing.
+func TestFourAddr(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		encoders, err := wifi.InterfaceOptionAttrs(wifi.InterfaceTypeAPVLAN, wifi.WithFourAddr(enabled))
+		if err != nil {
+			t.Fatalf("InterfaceOptionAttrs: %v", err)
+		}
+		got, ok := encodeAttributes(t, encoders)[unix.NL80211_ATTR_4ADDR]
+		if !ok || len(got) != 1 || (got[0] != 0) != enabled {
+			t.Errorf("unexpected NL80211_ATTR_4ADDR %v for enabled=%v", got, enabled)
+		}
+	}
+	if _, err := wifi.InterfaceOptionAttrs(wifi.InterfaceTypeStation, wifi.WithFourAddr(true)); err != nil {
+		t.Errorf("unexpected error for a station interface: %v", err)
+	}
+	if _, err := wifi.InterfaceOptionAttrs(wifi.InterfaceTypeMonitor, wifi.WithFourAddr(true)); err == nil {
+		t.Error("expected an error for a monitor interface")
+	}
+
+	msg := genetlink.Message{
+		Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_ATTR_IFINDEX, Data: nlenc.Uint32Bytes(3)},
+			{Type: unix.NL80211_ATTR_4ADDR, Data: []byte{1}},
+		}),
+	}
+	wifis, err := (&wifi.Client{}).ParseGetInterfaceResponse([]genetlink.Message{msg})
+	if err != nil {
+		t.Fatalf("ParseGetInterfaceResponse: %v", err)
+	}
+	if len(wifis) != 1 || !wifis[0].FourAddr {
+		t.Errorf("expected an interface in 4-address mode, got %v", wifis)
+	}
+
+	msg.Data = mustMarshalAttributes(t, []netlink.Attribute{
+		{Type: unix.NL80211_ATTR_IFINDEX, Data: nlenc.Uint32Bytes(3)},
+		{Type: unix.NL80211_ATTR_4ADDR},
+	})
+	wifis, err = (&wifi.Client{}).ParseGetInterfaceResponse([]genetlink.Message{msg})
+	if err != nil {
+		t.Fatalf("ParseGetInterfaceResponse: %v", err)
+	}
+	if len(wifis) != 1 || wifis[0].FourAddr {
+		t.Errorf("expected an empty NL80211_ATTR_4ADDR to be ignored, got %v", wifis)
+	}
+}
+
+// TestParseGetPowerSaveResponse tests the parsing of the power save state
+// of an interface.
+func TestParseGetPowerSaveResponse(t *testing.T) {
+	for _, state := range []uint32{unix.NL80211_PS_DISABLED, unix.NL80211_PS_ENABLED} {
+		msg := genetlink.Message{
+			Data: mustMarshalAttributes(t, []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_PS_STATE, Data: nlenc.Uint32Bytes(state)},
+			}),
+		}
+		enabled, err := (&wifi.Client{}).ParseGetPowerSaveResponse([]genetlink.Message{msg})
+		if err != nil {
+			t.Fatalf("ParseGetPowerSaveResponse: %v", err)
+		}
+		if enabled != (state == unix.NL80211_PS_ENABLED) {
+			t.Errorf("state %d: got enabled=%v", state, enabled)
+		}
+	}
+
+	if _, err := (&wifi.Client{}).ParseGetPowerSaveResponse(nil); err == nil {
+		t.Error("expected an error for a response without a power save state")
+	}
+}
+
+// TestParseDumpPartialResults tests that messages of a dump that fail to
+// parse are skipped and reported without discarding the others.
+func TestParseDumpPartialResults(t *testing.T) {
+	mac := []byte{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}
+	bad := genetlink.Message{Data: []byte{0xff}}
+
+	stations, err := (&wifi.Client{}).ParseGetStationResponse([]genetlink.Message{
+		{Data: mustMarshalAttributes(t, []netlink.Attribute{{Type: unix.NL80211_ATTR_MAC, Data: mac}})},
+		bad,
+	})
+	var perr *wifi.PartialResultsError
+	if !errors.As(err, &perr) || len(perr.Errors) != 1 {
+		t.Fatalf("ParseGetStationResponse: expected a partial results error, got %v", err)
+	}
+	if len(stations) != 1 || stations[0].HardwareAddr.String() != "02:00:00:00:00:01" {
+		t.Errorf("unexpected stations %v", stations)
+	}
+
+	bsss, err := (&wifi.Client{}).ParseGetScanResponse([]genetlink.Message{
+		bad,
+		{Data: mustMarshalAttributes(t, []netlink.Attribute{{Type: unix.NL80211_ATTR_BSS, Data: []byte{0xff}}})},
+		{Data: mustMarshalAttributes(t, []netlink.Attribute{{Type: unix.NL80211_ATTR_BSS, Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_BSS_BSSID, Data: mac},
+		})}})},
+	})
+	if !errors.As(err, &perr) || len(perr.Errors) != 2 {
+		t.Fatalf("ParseGetScanResponse: expected a partial results error, got %v", err)
+	}
+	if len(bsss) != 1 || bsss[0].BSSID.String() != "02:00:00:00:00:01" {
+		t.Errorf("unexpected BSSs %v", bsss)
+	}
+
+	if _, err := (&wifi.Client{}).ParseGetScanResponse(nil); err != nil {
+		t.Errorf("ParseGetScanResponse: unexpected error for an empty dump: %v", err)
+	}
+}
+
+// TestResponseEmptyDump tests that a dump answered with nothing but the
+// multipart done message yields no messages rather than a panic.
+func TestResponseEmptyDump(t *testing.T) {
+	conn := genetlink.NewConn(nltest.Dial(func(reqs []netlink.Message) ([]netlink.Message, error) {
+		return []netlink.Message{{
+			Header: netlink.Header{
+				Type:     netlink.Done,
+				Flags:    netlink.Multi,
+				Sequence: reqs[0].Header.Sequence,
+				PID:      reqs[0].Header.PID,
+			},
+			Data: nlenc.Int32Bytes(0),
+		}}, nil
+	}))
+	c := wifi.NewClientConn(conn, 0x1c)
+	defer c.Close()
+
+	msg, err := wifi.NewNl80211Message(unix.NL80211_CMD_GET_SCAN, nil)
+	if err != nil {
+		t.Fatalf("NewNl80211Message: %v", err)
+	}
+	request := wifi.Nl80211Request{RequestMessage: msg, Flags: netlink.Request | netlink.Dump}
+	msgs, err := request.Response(c)
+	if err != nil {
+		t.Fatalf("Response: %v", err)
+	}
+	if len(msgs) != 0 {
+		t.Errorf("got %d messages, expected none", len(msgs))
+	}
+}
+
+// TestFilterInterfacesByPhy tests that interfaces of other wiphys are
+// dropped.
+func TestFilterInterfacesByPhy(t *testing.T) {
+	wifis := []*wifi.WifiInterface{
+		{Index: 3, Name: "wlan0", Phy: 0},
+		{Index: 4, Name: "wlan1", Phy: 1},
+		{Index: 5, Name: "mon0", Phy: 0},
+	}
+	got := wifi.FilterInterfacesByPhy(wifis, 0)
+	if len(got) != 2 || got[0].Name != "wlan0" || got[1].Name != "mon0" {
+		t.Errorf("got %v, expected wlan0 and mon0", got)
+	}
+}
+
+// TestInterfaceByNameCached tests that cached interfaces are returned until
+// they expire or the cache is invalidated.
+func TestInterfaceByNameCached(t *testing.T) {
+	c := &wifi.Client{}
+	c.CacheInterface(&wifi.WifiInterface{Index: 3, Name: "wifitest0", Phy: 1}, time.Now().Add(time.Minute))
+
+	w, err := c.InterfaceByNameCached("wifitest0")
+	if err != nil {
+		t.Fatalf("InterfaceByNameCached: %v", err)
+	}
+	if w.Index != 3 || w.Phy != 1 {
+		t.Errorf("unexpected interface %v", w)
+	}
+
+	// The interface doesn't exist, so lookups that miss the cache fail.
+	c.InvalidateInterfaceCache()
+	if _, err := c.InterfaceByNameCached("wifitest0"); err == nil {
+		t.Errorf("InterfaceByNameCached: expected an error after invalidation")
+	}
+
+	c.CacheInterface(&wifi.WifiInterface{Index: 3, Name: "wifitest0"}, time.Now().Add(-time.Second))
+	if _, err := c.InterfaceByNameCached("wifitest0"); err == nil {
+		t.Errorf("InterfaceByNameCached: expected an error for an expired entry")
+	}
+}
+
+// socketOptioner records the socket options set on a connection.
+type socketOptioner struct {
+	readBuffer int
+	options    map[netlink.ConnOption]bool
+	err        error
+}
+
+func (c *socketOptioner) SetReadBuffer(bytes int) error {
+	c.readBuffer = bytes
+	return c.err
+}
+
+func (c *socketOptioner) SetOption(option netlink.ConnOption, enable bool) error {
+	if c.options == nil {
+		c.options = make(map[netlink.ConnOption]bool)
+	}
+	c.options[option] = enable
+	return c.err
+}
+
+// TestOptionsApply tests that the options of NewClientWithOptions are set
+// on the connections it dials, and that the defaults leave them alone.
+func TestOptionsApply(t *testing.T) {
+	conn := &socketOptioner{}
+	if err := (wifi.Options{ReadBufferSize: 1 << 20, NoENOBUFS: true}).Apply(conn); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if conn.readBuffer != 1<<20 {
+		t.Errorf("got read buffer size %d, expected %d", conn.readBuffer, 1<<20)
+	}
+	if !conn.options[netlink.NoENOBUFS] {
+		t.Error("NETLINK_NO_ENOBUFS not set")
+	}
+
+	conn = &socketOptioner{}
+	if err := (wifi.Options{}).Apply(conn); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if conn.readBuffer != 0 || len(conn.options) != 0 {
+		t.Errorf("default options changed the connection: %+v", conn)
+	}
+
+	conn = &socketOptioner{err: errors.New("not permitted")}
+	if err := (wifi.Options{ReadBufferSize: 1 << 20}).Apply(conn); err == nil {
+		t.Error("expected an error when the read buffer can't be set")
+	}
+}
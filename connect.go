@@ -0,0 +1,431 @@
+//go:build linux
+// +build linux
+
+package wifi
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/mdlayher/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// IEEE 802.11 AKM suite selectors, an OUI followed by a suite type.
+const (
+	akmSuitePSK = 0x000fac02
+	akmSuiteSAE = 0x000fac08
+)
+
+// A CipherSuite is an IEEE 802.11 cipher suite selector.
+type CipherSuite uint32
+
+const (
+	CipherWEP40   CipherSuite = 0x000fac01
+	CipherTKIP    CipherSuite = 0x000fac02
+	CipherCCMP    CipherSuite = 0x000fac04
+	CipherWEP104  CipherSuite = 0x000fac05
+	CipherGCMP    CipherSuite = 0x000fac08
+	CipherGCMP256 CipherSuite = 0x000fac09
+	CipherCCMP256 CipherSuite = 0x000fac0a
+)
+
+// String returns the string representation of a CipherSuite.
+func (c CipherSuite) String() string {
+	switch c {
+	case CipherWEP40:
+		return "WEP-40"
+	case CipherTKIP:
+		return "TKIP"
+	case CipherCCMP:
+		return "CCMP"
+	case CipherWEP104:
+		return "WEP-104"
+	case CipherGCMP:
+		return "GCMP"
+	case CipherGCMP256:
+		return "GCMP-256"
+	case CipherCCMP256:
+		return "CCMP-256"
+	default:
+		return fmt.Sprintf("unknown(%08x)", uint32(c))
+	}
+}
+
+// WPAVersion is a set of WPA versions, which may be combined for networks
+// running in mixed mode.
+type WPAVersion uint32
+
+const (
+	WPAVersion1 WPAVersion = unix.NL80211_WPA_VERSION_1
+	WPAVersion2 WPAVersion = unix.NL80211_WPA_VERSION_2
+	WPAVersion3 WPAVersion = unix.NL80211_WPA_VERSION_3
+)
+
+// A SecurityMode selects how a connection is authenticated.
+type SecurityMode int
+
+const (
+	// SecurityOpen connects to a network without encryption.
+	SecurityOpen SecurityMode = iota
+
+	// SecurityPSK connects to a WPA-Personal network, by default using
+	// WPA2. The PMK is derived from the passphrase and handed to the
+	// driver, which must support offloading the 4-way handshake.
+	SecurityPSK
+
+	// SecurityWPA3SAE connects to a WPA3-Personal network. The
+	// passphrase is handed to the driver, which must support offloading
+	// SAE authentication.
+	SecurityWPA3SAE
+)
+
+// SecurityWPA2PSK is the name SecurityPSK had before the WPA version became
+// configurable.
+const SecurityWPA2PSK = SecurityPSK
+
+// String returns the string representation of a SecurityMode.
+func (m SecurityMode) String() string {
+	switch m {
+	case SecurityOpen:
+		return "open"
+	case SecurityPSK:
+		return "PSK"
+	case SecurityWPA3SAE:
+		return "WPA3-SAE"
+	default:
+		return fmt.Sprintf("unknown(%d)", m)
+	}
+}
+
+// ConnectOptions describe the network to connect to.
+type ConnectOptions struct {
+	SSID       string
+	Security   SecurityMode
+	Passphrase string
+
+	// WPAVersions selects the WPA versions of a SecurityPSK connection,
+	// defaulting to WPAVersion2. SecurityWPA3SAE always uses WPAVersion3.
+	WPAVersions WPAVersion
+
+	// PairwiseCiphers and GroupCipher select the cipher suites. They
+	// default to CCMP, except that a connection allowing WPAVersion1
+	// defaults to a TKIP group cipher, and WPA1 alone to TKIP throughout.
+	PairwiseCiphers []CipherSuite
+	GroupCipher     CipherSuite
+
+	// Frequency optionally restricts the connection to a frequency in MHz.
+	Frequency uint32
+
+	// BSSID optionally pins the connection to the AP with that address,
+	// for networks where several APs share the SSID.
+	BSSID net.HardwareAddr
+
+	// Hidden is set for networks that do not broadcast their SSID. Connect
+	// then first scans with a directed probe request for the SSID.
+	Hidden bool
+}
+
+// ErrNetworkNotFound is returned by Connect when a hidden network did not
+// answer the directed probe for its SSID.
+var ErrNetworkNotFound = errors.New("network not found")
+
+// Connect requests a connection to the network described by opts. It returns
+// once the kernel has accepted the request; use WaitForConnect instead to
+// learn whether association succeeded.
+//
+// For a hidden network, Connect blocks while it scans for the SSID and
+// connects on the frequency the AP answered on. If no AP answers the
+// probe, Connect returns an error wrapping ErrNetworkNotFound without
+// requesting a connection. APs that only answer some probes may need
+// Connect to be retried.
+func (c *Client) Connect(w *WifiInterface, opts *ConnectOptions) error {
+	if opts.Hidden {
+		found, err := c.probeHidden(w, opts)
+		if err != nil { return fmt.Errorf("Connect: %w", err) }
+		opts = found
+	}
+
+	// Open networks need no cipher or offload support, so they are
+	// connected to without looking the wiphy up.
+	if opts.Security != SecurityOpen {
+		wiphy, err := c.connectWiphy(w)
+		if err != nil { return fmt.Errorf("Connect: %v", err) }
+		if err := wiphy.checkConnectOptions(opts); err != nil { return fmt.Errorf("Connect: %v", err) }
+	}
+
+	attrs, err := connectAttrs(w, opts)
+	if err != nil { return fmt.Errorf("Connect: %v", err) }
+
+	msg, err := NewNl80211Message(unix.NL80211_CMD_CONNECT, attrs)
+	if err != nil { return fmt.Errorf("Connect: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("Connect: %w", err) }
+
+	c.rememberConnection(w, opts)
+	return nil
+}
+
+// Roam moves the connection of the given interface to the AP with the given
+// BSSID, which must belong to the same network. It reassociates using the
+// options of the last Connect on the interface, or, if the connection was
+// made by another program, with the SSID of the current network provided
+// it is open. Like Connect, it returns once the kernel has accepted the
+// request. The outcome is reported as a ConnectResult on the "mlme" group,
+// which must be subscribed to with SubscribeEvents before calling Roam.
+//
+// The AP should be in the scan cache, so that its frequency is known. If it
+// isn't, the kernel first scans for it, and the roam fails with a connect
+// result reporting an error if the AP doesn't answer.
+func (c *Client) Roam(w *WifiInterface, bssid net.HardwareAddr) error {
+	if len(bssid) != 6 { return fmt.Errorf("Roam: invalid BSSID: %v", bssid) }
+
+	current, err := c.ConnectedBSS(w)
+	if err != nil { return fmt.Errorf("Roam: %w", err) }
+
+	opts, err := c.roamOptions(w, current)
+	if err != nil { return fmt.Errorf("Roam: %v", err) }
+
+	bsss, err := c.ScanResults(w)
+	if err != nil && !isPartialResults(err) { return fmt.Errorf("Roam: %v", err) }
+	for _, bss := range bsss {
+		if bytes.Equal(bss.BSSID, bssid) {
+			opts.Frequency = bss.Frequency
+		}
+	}
+
+	attrs, err := connectAttrs(w, opts)
+	if err != nil { return fmt.Errorf("Roam: %v", err) }
+	// The kernel only accepts a connect request on a connected interface
+	// as a reassociation from the AP it is connected to.
+	attrs = append(attrs, MacAttribute(bssid), PrevBSSIDAttribute(current.BSSID))
+
+	msg, err := NewNl80211Message(unix.NL80211_CMD_CONNECT, attrs)
+	if err != nil { return fmt.Errorf("Roam: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("Roam: %w", err) }
+	return nil
+}
+
+// rememberConnection records the options of a connection requested on w.
+func (c *Client) rememberConnection(w *WifiInterface, opts *ConnectOptions) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.connections == nil {
+		c.connections = make(map[uint32]*ConnectOptions)
+	}
+	remembered := *opts
+	c.connections[w.Index] = &remembered
+}
+
+// roamOptions returns the options to reassociate with, based on the last
+// connection requested on w or, failing that, the current BSS.
+func (c *Client) roamOptions(w *WifiInterface, current *BSS) (*ConnectOptions, error) {
+	c.mu.Lock()
+	remembered, ok := c.connections[w.Index]
+	c.mu.Unlock()
+
+	if ok && remembered.SSID == current.SSID {
+		opts := *remembered
+		opts.Hidden = false
+		opts.Frequency = 0
+		opts.BSSID = nil
+		return &opts, nil
+	}
+	if current.Capabilities.Privacy() {
+		return nil, fmt.Errorf("no connection options for secured network %q, use Connect", current.SSID)
+	}
+	return &ConnectOptions{SSID: current.SSID, Security: SecurityOpen}, nil
+}
+
+// checkConnectOptions reports an error if the device lacks support for the
+// security parameters in opts, which the kernel would otherwise reject with
+// a bare EINVAL.
+func (w *Wiphy) checkConnectOptions(opts *ConnectOptions) error {
+	switch opts.Security {
+	case SecurityOpen:
+		return nil
+	case SecurityPSK:
+		if !w.SupportsPSKOffload() { return errors.New("driver does not support 4-way handshake offload") }
+	case SecurityWPA3SAE:
+		if !w.SupportsSAEOffload() { return errors.New("driver does not support SAE offload") }
+	}
+
+	pairwise, group := opts.ciphers(opts.WPAVersions)
+	for _, c := range append(pairwise, group) {
+		if !w.SupportsCipher(CipherSuite(c)) {
+			return fmt.Errorf("driver does not support %v", CipherSuite(c))
+		}
+	}
+	return nil
+}
+
+// probeHidden scans for the hidden network described by opts, returning
+// a copy of opts with the frequency the network was found on.
+func (c *Client) probeHidden(w *WifiInterface, opts *ConnectOptions) (*ConnectOptions, error) {
+	bsss, err := c.Scan(w, opts.SSID)
+	if err != nil && !isPartialResults(err) { return nil, err }
+
+	for _, bss := range bsss {
+		if !bytes.Equal(bss.SSIDBytes, []byte(opts.SSID)) { continue }
+		if opts.Frequency != 0 && bss.Frequency != opts.Frequency { continue }
+		if opts.BSSID != nil && !bytes.Equal(bss.BSSID, opts.BSSID) { continue }
+
+		found := *opts
+		found.Frequency = bss.Frequency
+		return &found, nil
+	}
+	return nil, fmt.Errorf("%w: no response to probe for hidden SSID %q", ErrNetworkNotFound, opts.SSID)
+}
+
+// connectAttrs returns the NL80211_CMD_CONNECT attributes connecting w to
+// the network described by opts.
+func connectAttrs(w *WifiInterface, opts *ConnectOptions) ([]AttributeEncoder, error) {
+	attrs, err := connectionAttrEncoder(opts)
+	if err != nil { return nil, err }
+	return append([]AttributeEncoder{InterfaceIndexAttribute(w.Index)}, attrs...), nil
+}
+
+// connectionAttrEncoder returns the NL80211_CMD_CONNECT attributes for the
+// network described by opts.
+func connectionAttrEncoder(opts *ConnectOptions) ([]AttributeEncoder, error) {
+	if opts.SSID == "" || len(opts.SSID) > 32 {
+		return nil, fmt.Errorf("invalid SSID %q", opts.SSID)
+	}
+
+	attrs := []AttributeEncoder{
+		SSIDAttribute([]byte(opts.SSID)),
+	}
+	if opts.Frequency != 0 {
+		attrs = append(attrs, WiphyFrequencyAttribute(opts.Frequency))
+	}
+	if opts.BSSID != nil {
+		if len(opts.BSSID) != 6 { return nil, fmt.Errorf("invalid BSSID %v", opts.BSSID) }
+		attrs = append(attrs, MacAttribute(opts.BSSID))
+	}
+
+	switch opts.Security {
+	case SecurityOpen:
+		attrs = append(attrs, AuthTypeAttribute(unix.NL80211_AUTHTYPE_OPEN_SYSTEM))
+	case SecurityPSK:
+		versions := opts.WPAVersions
+		if versions == 0 {
+			versions = WPAVersion2
+		}
+		if versions&WPAVersion3 != 0 || versions&(WPAVersion1|WPAVersion2) == 0 {
+			return nil, fmt.Errorf("invalid WPA versions %#x for PSK", uint32(versions))
+		}
+		pmk, err := derivePSK(opts.Passphrase, opts.SSID)
+		if err != nil { return nil, err }
+		pairwise, group := opts.ciphers(versions)
+		attrs = append(attrs,
+			AuthTypeAttribute(unix.NL80211_AUTHTYPE_OPEN_SYSTEM),
+			PrivacyAttribute(true),
+			WPAVersionsAttribute(uint32(versions)),
+			AKMSuitesAttribute(akmSuitePSK),
+			PairwiseCipherSuitesAttribute(pairwise...),
+			GroupCipherSuiteAttribute(group),
+			Want4WayHandshakeAttribute(true),
+			PMKAttribute(pmk),
+		)
+	case SecurityWPA3SAE:
+		if opts.Passphrase == "" { return nil, errors.New("SAE requires a passphrase") }
+		if opts.WPAVersions != 0 && opts.WPAVersions != WPAVersion3 {
+			return nil, fmt.Errorf("invalid WPA versions %#x for SAE", uint32(opts.WPAVersions))
+		}
+		pairwise, group := opts.ciphers(WPAVersion3)
+		// The kernel runs SAE itself from the password, so unlike WPA2
+		// no PMK is given. WPA3 makes management frame protection
+		// mandatory.
+		attrs = append(attrs,
+			AuthTypeAttribute(unix.NL80211_AUTHTYPE_SAE),
+			PrivacyAttribute(true),
+			WPAVersionsAttribute(uint32(WPAVersion3)),
+			AKMSuitesAttribute(akmSuiteSAE),
+			PairwiseCipherSuitesAttribute(pairwise...),
+			GroupCipherSuiteAttribute(group),
+			MFPAttribute(unix.NL80211_MFP_REQUIRED),
+			Want4WayHandshakeAttribute(true),
+			SAEPasswordAttribute([]byte(opts.Passphrase)),
+		)
+	default:
+		return nil, fmt.Errorf("unsupported security mode %v", opts.Security)
+	}
+	return attrs, nil
+}
+
+// ciphers returns the pairwise and group cipher suites selected by opts, or
+// the defaults for the given WPA versions.
+func (opts *ConnectOptions) ciphers(versions WPAVersion) ([]uint32, uint32) {
+	defaultCipher := CipherCCMP
+	if versions == WPAVersion1 {
+		defaultCipher = CipherTKIP
+	}
+
+	pairwise := make([]uint32, 0, len(opts.PairwiseCiphers))
+	for _, c := range opts.PairwiseCiphers {
+		pairwise = append(pairwise, uint32(c))
+	}
+	if len(pairwise) == 0 {
+		pairwise = append(pairwise, uint32(defaultCipher))
+	}
+
+	group := opts.GroupCipher
+	if group == 0 {
+		group = defaultCipher
+		if versions&WPAVersion1 != 0 {
+			group = CipherTKIP
+		}
+	}
+	return pairwise, uint32(group)
+}
+
+// derivePSK derives the WPA2 pre-shared key from a passphrase as specified
+// by IEEE 802.11 Annex J: PBKDF2-HMAC-SHA1 with the SSID as salt, 4096
+// iterations and a 256 bit output.
+func derivePSK(passphrase, ssid string) ([]byte, error) {
+	if len(passphrase) < 8 || len(passphrase) > 63 {
+		return nil, errors.New("WPA2 passphrase must be 8 to 63 characters")
+	}
+
+	const iterations, keyLen = 4096, 32
+	prf := hmac.New(sha1.New, []byte(passphrase))
+
+	key := make([]byte, 0, keyLen+sha1.Size)
+	for block := uint32(1); len(key) < keyLen; block++ {
+		prf.Reset()
+		prf.Write([]byte(ssid))
+		binary.Write(prf, binary.BigEndian, block)
+		u := prf.Sum(nil)
+
+		t := make([]byte, len(u))
+		copy(t, u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen], nil
+}
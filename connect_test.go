@@ -0,0 +1,343 @@
+package wifi_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/bryancoxwell/wifi"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"github.com/mdlayher/netlink/nltest"
+	"golang.org/x/sys/unix"
+)
+
+// encodeConnectAttributes encodes the attributes built for opts and decodes
+// them again, keyed by attribute type.
+func encodeConnectAttributes(t *testing.T, opts *wifi.ConnectOptions) map[uint16][]byte {
+	t.Helper()
+	encoders, err := wifi.ConnectionAttrEncoder(opts)
+	if err != nil {
+		t.Fatalf("ConnectionAttrEncoder: %v", err)
+	}
+	return encodeAttributes(t, encoders)
+}
+
+// encodeAttributes encodes attrs and decodes them again, keyed by attribute
+// type with any nested flag cleared.
+func encodeAttributes(t *testing.T, encoders []wifi.AttributeEncoder) map[uint16][]byte {
+	t.Helper()
+	ae := netlink.NewAttributeEncoder()
+	for _, e := range encoders {
+		e.EncodeAttribute(ae)
+	}
+	b, err := ae.Encode()
+	if err != nil {
+		t.Fatalf("failed to encode attributes: %v", err)
+	}
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil {
+		t.Fatalf("failed to decode attributes: %v", err)
+	}
+	m := make(map[uint16][]byte, len(attrs))
+	for _, a := range attrs {
+		m[a.Type&^unix.NLA_F_NESTED] = a.Data
+	}
+	return m
+}
+
+// TestDerivePSK tests PSK derivation against the IEEE 802.11 Annex J
+// test vectors.
+func TestDerivePSK(t *testing.T) {
+	tests := []struct {
+		passphrase, ssid, psk string
+	}{
+		{"password", "IEEE", "f42c6fc52df0ebef9ebb4b90b38a5f902e83fe1b135a70e23aed762e9710a12e"},
+		{"ThisIsAPassword", "ThisIsASSID", "0dc0d6eb90555ed6419756b9a15ec3e3209b63df707dd508d14581f8982721af"},
+	}
+	for _, tt := range tests {
+		psk, err := wifi.DerivePSK(tt.passphrase, tt.ssid)
+		if err != nil {
+			t.Fatalf("DerivePSK(%q, %q): %v", tt.passphrase, tt.ssid, err)
+		}
+		if got := hex.EncodeToString(psk); got != tt.psk {
+			t.Errorf("DerivePSK(%q, %q) = %s, expected %s", tt.passphrase, tt.ssid, got, tt.psk)
+		}
+	}
+}
+
+// TestConnectionAttrEncoderSAE tests that a WPA3-SAE connection passes the
+// password rather than a PMK and requires management frame protection.
+func TestConnectionAttrEncoderSAE(t *testing.T) {
+	attrs := encodeConnectAttributes(t, &wifi.ConnectOptions{
+		SSID:       "home",
+		Security:   wifi.SecurityWPA3SAE,
+		Passphrase: "correct horse",
+	})
+
+	expected := map[uint16][]byte{
+		unix.NL80211_ATTR_SSID:                   []byte("home"),
+		unix.NL80211_ATTR_AUTH_TYPE:              nlenc.Uint32Bytes(unix.NL80211_AUTHTYPE_SAE),
+		unix.NL80211_ATTR_WPA_VERSIONS:           nlenc.Uint32Bytes(unix.NL80211_WPA_VERSION_3),
+		unix.NL80211_ATTR_AKM_SUITES:             nlenc.Uint32Bytes(0x000fac08),
+		unix.NL80211_ATTR_CIPHER_SUITES_PAIRWISE: nlenc.Uint32Bytes(0x000fac04),
+		unix.NL80211_ATTR_CIPHER_SUITE_GROUP:     nlenc.Uint32Bytes(0x000fac04),
+		unix.NL80211_ATTR_USE_MFP:                nlenc.Uint32Bytes(unix.NL80211_MFP_REQUIRED),
+		unix.NL80211_ATTR_SAE_PASSWORD:           []byte("correct horse"),
+		unix.NL80211_ATTR_PRIVACY:                {},
+		unix.NL80211_ATTR_WANT_1X_4WAY_HS:        {},
+	}
+	for typ, data := range expected {
+		got, ok := attrs[typ]
+		if !ok {
+			t.Errorf("missing attribute %d", typ)
+			continue
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("attribute %d = %x, expected %x", typ, got, data)
+		}
+	}
+	if _, ok := attrs[unix.NL80211_ATTR_PMK]; ok {
+		t.Errorf("unexpected NL80211_ATTR_PMK for SAE connection")
+	}
+}
+
+// TestConnectionAttrEncoderWPA2 tests that a WPA2-PSK connection passes the
+// derived PMK.
+func TestConnectionAttrEncoderWPA2(t *testing.T) {
+	attrs := encodeConnectAttributes(t, &wifi.ConnectOptions{
+		SSID:       "IEEE",
+		Security:   wifi.SecurityWPA2PSK,
+		Passphrase: "password",
+		Frequency:  2412,
+	})
+
+	if got := hex.EncodeToString(attrs[unix.NL80211_ATTR_PMK]); got != "f42c6fc52df0ebef9ebb4b90b38a5f902e83fe1b135a70e23aed762e9710a12e" {
+		t.Errorf("unexpected PMK %s", got)
+	}
+	if got := attrs[unix.NL80211_ATTR_AKM_SUITES]; !bytes.Equal(got, nlenc.Uint32Bytes(0x000fac02)) {
+		t.Errorf("unexpected AKM suites %x", got)
+	}
+	if got := attrs[unix.NL80211_ATTR_WIPHY_FREQ]; !bytes.Equal(got, nlenc.Uint32Bytes(2412)) {
+		t.Errorf("unexpected frequency %x", got)
+	}
+	if _, ok := attrs[unix.NL80211_ATTR_SAE_PASSWORD]; ok {
+		t.Errorf("unexpected NL80211_ATTR_SAE_PASSWORD for WPA2 connection")
+	}
+}
+
+// TestConnectionAttrEncoderWPAVersions tests the WPA versions and cipher
+// suites chosen for PSK connections.
+func TestConnectionAttrEncoderWPAVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     wifi.ConnectOptions
+		versions uint32
+		pairwise []uint32
+		group    uint32
+	}{
+		{
+			name:     "default",
+			opts:     wifi.ConnectOptions{},
+			versions: unix.NL80211_WPA_VERSION_2,
+			pairwise: []uint32{0x000fac04},
+			group:    0x000fac04,
+		},
+		{
+			name:     "WPA1",
+			opts:     wifi.ConnectOptions{WPAVersions: wifi.WPAVersion1},
+			versions: unix.NL80211_WPA_VERSION_1,
+			pairwise: []uint32{0x000fac02},
+			group:    0x000fac02,
+		},
+		{
+			name:     "mixed",
+			opts:     wifi.ConnectOptions{WPAVersions: wifi.WPAVersion1 | wifi.WPAVersion2},
+			versions: unix.NL80211_WPA_VERSION_1 | unix.NL80211_WPA_VERSION_2,
+			pairwise: []uint32{0x000fac04},
+			group:    0x000fac02,
+		},
+		{
+			name: "explicit ciphers",
+			opts: wifi.ConnectOptions{
+				PairwiseCiphers: []wifi.CipherSuite{wifi.CipherCCMP, wifi.CipherTKIP},
+				GroupCipher:     wifi.CipherCCMP,
+			},
+			versions: unix.NL80211_WPA_VERSION_2,
+			pairwise: []uint32{0x000fac04, 0x000fac02},
+			group:    0x000fac04,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.SSID, opts.Security, opts.Passphrase = "legacy", wifi.SecurityPSK, "password"
+			attrs := encodeConnectAttributes(t, &opts)
+
+			if got := attrs[unix.NL80211_ATTR_WPA_VERSIONS]; !bytes.Equal(got, nlenc.Uint32Bytes(tt.versions)) {
+				t.Errorf("unexpected WPA versions %x", got)
+			}
+			var pairwise []byte
+			for _, c := range tt.pairwise {
+				pairwise = append(pairwise, nlenc.Uint32Bytes(c)...)
+			}
+			if got := attrs[unix.NL80211_ATTR_CIPHER_SUITES_PAIRWISE]; !bytes.Equal(got, pairwise) {
+				t.Errorf("unexpected pairwise ciphers %x", got)
+			}
+			if got := attrs[unix.NL80211_ATTR_CIPHER_SUITE_GROUP]; !bytes.Equal(got, nlenc.Uint32Bytes(tt.group)) {
+				t.Errorf("unexpected group cipher %x", got)
+			}
+		})
+	}
+}
+
+// TestConnectionAttrEncoderInvalid tests that invalid options are rejected.
+func TestConnectionAttrEncoderInvalid(t *testing.T) {
+	tests := []*wifi.ConnectOptions{
+		{SSID: "", Security: wifi.SecurityOpen},
+		{SSID: "net", Security: wifi.SecurityWPA2PSK, Passphrase: "short"},
+		{SSID: "net", Security: wifi.SecurityWPA3SAE},
+		{SSID: "net", Security: wifi.SecurityPSK, Passphrase: "password", WPAVersions: wifi.WPAVersion3},
+		{SSID: "net", Security: wifi.SecurityWPA3SAE, Passphrase: "password", WPAVersions: wifi.WPAVersion2},
+		{SSID: "net", Security: wifi.SecurityOpen, BSSID: []byte{0x02, 0x00, 0x00}},
+	}
+	for _, opts := range tests {
+		if _, err := wifi.ConnectionAttrEncoder(opts); err == nil {
+			t.Errorf("ConnectionAttrEncoder(%+v): expected error", opts)
+		}
+	}
+}
+
+// TestConnectionAttrEncoderBSSID tests that a BSSID pins the connection
+// through NL80211_ATTR_MAC, which is left out otherwise.
+func TestConnectionAttrEncoderBSSID(t *testing.T) {
+	bssid := []byte{0x02, 0x00, 0x00, 0x00, 0x01, 0x00}
+	attrs := encodeConnectAttributes(t, &wifi.ConnectOptions{SSID: "corp", Security: wifi.SecurityOpen, BSSID: bssid})
+	if !bytes.Equal(attrs[unix.NL80211_ATTR_MAC], bssid) {
+		t.Errorf("got NL80211_ATTR_MAC %x, expected %x", attrs[unix.NL80211_ATTR_MAC], bssid)
+	}
+
+	attrs = encodeConnectAttributes(t, &wifi.ConnectOptions{SSID: "corp", Security: wifi.SecurityOpen})
+	if _, ok := attrs[unix.NL80211_ATTR_MAC]; ok {
+		t.Error("unexpected NL80211_ATTR_MAC without a BSSID")
+	}
+}
+
+// TestConnectAttrs tests the attributes of a connect request to an open
+// network, which names the interface and carries no security attributes.
+func TestConnectAttrs(t *testing.T) {
+	w := &wifi.WifiInterface{Index: 3, Name: "wlan0", Type: wifi.InterfaceTypeStation}
+	encoders, err := wifi.ConnectAttrs(w, &wifi.ConnectOptions{SSID: "cafe", Security: wifi.SecurityOpen, Frequency: 5180})
+	if err != nil {
+		t.Fatalf("ConnectAttrs: %v", err)
+	}
+	attrs := encodeAttributes(t, encoders)
+
+	expected := map[uint16][]byte{
+		unix.NL80211_ATTR_IFINDEX:    nlenc.Uint32Bytes(3),
+		unix.NL80211_ATTR_SSID:       []byte("cafe"),
+		unix.NL80211_ATTR_WIPHY_FREQ: nlenc.Uint32Bytes(5180),
+		unix.NL80211_ATTR_AUTH_TYPE:  nlenc.Uint32Bytes(unix.NL80211_AUTHTYPE_OPEN_SYSTEM),
+	}
+	if len(attrs) != len(expected) {
+		t.Errorf("got %d attributes, expected %d", len(attrs), len(expected))
+	}
+	for typ, data := range expected {
+		if got, ok := attrs[typ]; !ok || !bytes.Equal(got, data) {
+			t.Errorf("attribute %d = %x, expected %x", typ, got, data)
+		}
+	}
+
+	if _, err := wifi.ConnectAttrs(w, &wifi.ConnectOptions{Security: wifi.SecurityOpen}); err == nil {
+		t.Error("expected an error without an SSID")
+	}
+}
+
+// TestConnectRequests tests that Connect only sends the connect request:
+// open networks skip the wiphy lookup, and secured ones check the wiphy
+// looked up by an earlier Connect.
+func TestConnectRequests(t *testing.T) {
+	var cmds []uint8
+	conn := genetlink.NewConn(nltest.Dial(func(reqs []netlink.Message) ([]netlink.Message, error) {
+		var m genetlink.Message
+		if err := m.UnmarshalBinary(reqs[0].Data); err != nil {
+			return nil, err
+		}
+		cmds = append(cmds, m.Header.Command)
+		return nltest.Error(0, reqs)
+	}))
+	c := wifi.NewClientConn(conn, 0x1c)
+	defer c.Close()
+	w := &wifi.WifiInterface{Index: 3, Name: "wlan0", Phy: 1, Type: wifi.InterfaceTypeStation}
+
+	if err := c.Connect(w, &wifi.ConnectOptions{SSID: "cafe", Security: wifi.SecurityOpen, Frequency: 5180}); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if len(cmds) != 1 || cmds[0] != unix.NL80211_CMD_CONNECT {
+		t.Errorf("got commands %v, expected only NL80211_CMD_CONNECT", cmds)
+	}
+
+	cmds = nil
+	c.CacheConnectWiphy(1, &wifi.Wiphy{Index: 1})
+	if err := c.Connect(w, &wifi.ConnectOptions{SSID: "cafe", Security: wifi.SecurityPSK, Passphrase: "correct horse"}); err == nil {
+		t.Error("expected an error for a wiphy without 4-way handshake offload")
+	}
+	if len(cmds) != 0 {
+		t.Errorf("got commands %v, expected none", cmds)
+	}
+}
+
+// TestScanSSIDsAttribute tests the encoding of the nested SSID list used for
+// directed probes, and the wildcard SSID sent when no SSIDs are given.
+func TestScanSSIDsAttribute(t *testing.T) {
+	tests := []struct {
+		ssids    []string
+		expected [][]byte
+	}{
+		{[]string{"hidden", "other"}, [][]byte{[]byte("hidden"), []byte("other")}},
+		{nil, [][]byte{{}}},
+	}
+	for _, tt := range tests {
+		ae := netlink.NewAttributeEncoder()
+		wifi.ScanSSIDsAttribute(tt.ssids...).EncodeAttribute(ae)
+		b, err := ae.Encode()
+		if err != nil {
+			t.Fatalf("failed to encode attributes: %v", err)
+		}
+
+		attrs, err := netlink.UnmarshalAttributes(b)
+		if err != nil || len(attrs) != 1 || attrs[0].Type&^unix.NLA_F_NESTED != unix.NL80211_ATTR_SCAN_SSIDS {
+			t.Fatalf("unexpected attributes %v: %v", attrs, err)
+		}
+		nested, err := netlink.UnmarshalAttributes(attrs[0].Data)
+		if err != nil || len(nested) != len(tt.expected) {
+			t.Fatalf("unexpected nested attributes %v: %v", nested, err)
+		}
+		for i, a := range nested {
+			if int(a.Type) != i || !bytes.Equal(a.Data, tt.expected[i]) {
+				t.Errorf("SSID %d: got type %d data %q, expected %q", i, a.Type, a.Data, tt.expected[i])
+			}
+		}
+	}
+}
+
+// TestRoamOptions tests the options used to roam from a connection made by
+// another program.
+func TestRoamOptions(t *testing.T) {
+	c := &wifi.Client{}
+	w := &wifi.WifiInterface{Index: 3, Name: "wlan0"}
+
+	opts, err := c.RoamOptions(w, &wifi.BSS{SSID: "cafe"})
+	if err != nil {
+		t.Fatalf("open network: unexpected error: %v", err)
+	}
+	if opts.SSID != "cafe" || opts.Security != wifi.SecurityOpen {
+		t.Errorf("open network: got %+v", opts)
+	}
+
+	// The privacy capability bit.
+	if _, err := c.RoamOptions(w, &wifi.BSS{SSID: "home", Capabilities: 0x0011}); err == nil {
+		t.Error("secured network: expected an error without connection options")
+	}
+}
@@ -0,0 +1,184 @@
+//go:build linux
+// +build linux
+
+package wifi
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// A CQMEventType is the kind of connection quality monitor notification
+// reported by a CQMEvent.
+type CQMEventType int
+
+const (
+	// CQMRSSILow reports the signal dropping below a configured threshold.
+	CQMRSSILow CQMEventType = iota
+	// CQMRSSIHigh reports the signal rising above a configured threshold.
+	CQMRSSIHigh
+	// CQMPacketLoss reports packets to the peer being lost.
+	CQMPacketLoss
+	// CQMBeaconLoss reports beacons from the AP being missed.
+	CQMBeaconLoss
+)
+
+// String returns the string representation of a CQMEventType.
+func (t CQMEventType) String() string {
+	switch t {
+	case CQMRSSILow:
+		return "RSSI low"
+	case CQMRSSIHigh:
+		return "RSSI high"
+	case CQMPacketLoss:
+		return "packet loss"
+	case CQMBeaconLoss:
+		return "beacon loss"
+	default:
+		return fmt.Sprintf("unknown(%d)", t)
+	}
+}
+
+// A CQMEvent is a connection quality monitor notification, sent by the
+// kernel on the "mlme" multicast group once thresholds have been configured
+// with SetCQMRSSI or SetCQMRSSIThresholds.
+type CQMEvent struct {
+	Type           CQMEventType
+	InterfaceIndex uint32
+	WiphyIndex     uint32
+
+	// BSSID is the peer the event refers to, when the kernel reports one,
+	// as it does for packet loss.
+	BSSID net.HardwareAddr
+
+	// RSSI is the signal level in dBm that triggered a threshold event,
+	// or 0 when the driver doesn't report it.
+	RSSI int32
+
+	// PacketLoss is the number of packets lost for CQMPacketLoss events.
+	PacketLoss uint32
+}
+
+// SetCQMRSSI configures the connection quality monitor of a station
+// interface to report a CQMEvent whenever the signal crosses threshold,
+// in dBm. Once an event has fired, the signal must move hysteresis dB
+// back before the next one is sent. A threshold of 0 disables monitoring.
+func (c *Client) SetCQMRSSI(w *WifiInterface, threshold int32, hysteresis uint32) error {
+	if threshold > 0 { return fmt.Errorf("SetCQMRSSI: invalid threshold %d dBm", threshold) }
+	if err := c.setCQM(w, cqmRSSIAttribute([]int32{threshold}, hysteresis)); err != nil {
+		return fmt.Errorf("SetCQMRSSI: %w", err)
+	}
+	return nil
+}
+
+// SetCQMRSSIThresholds configures the connection quality monitor of a
+// station interface to report a CQMEvent whenever the signal crosses any of
+// thresholds, in dBm, which must be given in ascending order. It requires
+// driver support, as reported by Wiphy.SupportsCQMRSSIList.
+func (c *Client) SetCQMRSSIThresholds(w *WifiInterface, thresholds []int32, hysteresis uint32) error {
+	if err := validateCQMThresholds(thresholds); err != nil { return fmt.Errorf("SetCQMRSSIThresholds: %v", err) }
+
+	wiphy, err := c.Wiphy(w)
+	if err != nil { return fmt.Errorf("SetCQMRSSIThresholds: %v", err) }
+	if len(thresholds) > 1 && !wiphy.SupportsCQMRSSIList() {
+		return errors.New("SetCQMRSSIThresholds: driver does not support multiple RSSI thresholds")
+	}
+
+	if err := c.setCQM(w, cqmRSSIAttribute(thresholds, hysteresis)); err != nil {
+		return fmt.Errorf("SetCQMRSSIThresholds: %w", err)
+	}
+	return nil
+}
+
+// setCQM sends a NL80211_CMD_SET_CQM request for the given interface.
+func (c *Client) setCQM(w *WifiInterface, cqm AttributeEncoder) error {
+	attrs := []AttributeEncoder{InterfaceIndexAttribute(w.Index), cqm}
+	msg, err := NewNl80211Message(unix.NL80211_CMD_SET_CQM, attrs)
+	if err != nil { return err }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	_, err = request.Response(c)
+	return err
+}
+
+// validateCQMThresholds checks that thresholds is a non-empty, strictly
+// ascending list of signal levels.
+func validateCQMThresholds(thresholds []int32) error {
+	if len(thresholds) == 0 { return errors.New("no RSSI thresholds") }
+	for i, t := range thresholds {
+		if t >= 0 { return fmt.Errorf("invalid RSSI threshold %d dBm", t) }
+		if i > 0 && t <= thresholds[i-1] { return errors.New("RSSI thresholds must be in ascending order") }
+	}
+	return nil
+}
+
+// cqmRSSIAttribute returns the nested NL80211_ATTR_CQM attribute holding
+// the given RSSI thresholds and hysteresis. NL80211_ATTR_CQM_RSSI_THOLD is
+// an array of s32, so a single threshold encodes the same as a plain s32.
+func cqmRSSIAttribute(thresholds []int32, hysteresis uint32) AttributeEncoder {
+	b := make([]byte, 0, 4*len(thresholds))
+	for _, t := range thresholds {
+		b = append(b, nlenc.Int32Bytes(t)...)
+	}
+	return NestedAttribute(unix.NL80211_ATTR_CQM,
+		NewAttributeFactory[[]byte](unix.NL80211_ATTR_CQM_RSSI_THOLD)(b),
+		NewAttributeFactory[uint32](unix.NL80211_ATTR_CQM_RSSI_HYST)(hysteresis),
+	)
+}
+
+// parseCQMEvent parses a NL80211_CMD_NOTIFY_CQM notification.
+func parseCQMEvent(m genetlink.Message) (*CQMEvent, error) {
+	attrs, err := netlink.UnmarshalAttributes(m.Data)
+	if err != nil { return nil, fmt.Errorf("parseCQMEvent: %v", err) }
+
+	ev := &CQMEvent{Type: -1}
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_ATTR_IFINDEX:
+			ev.InterfaceIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_WIPHY:
+			ev.WiphyIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_MAC:
+			ev.BSSID = net.HardwareAddr(a.Data)
+		case unix.NL80211_ATTR_CQM:
+			if err := ev.parseCQMAttributes(a.Data); err != nil { return nil, fmt.Errorf("parseCQMEvent: %v", err) }
+		}
+	}
+	if ev.Type < 0 { return nil, errors.New("parseCQMEvent: unsupported CQM event") }
+	return ev, nil
+}
+
+// parseCQMAttributes fills in the event from the attributes nested in
+// NL80211_ATTR_CQM.
+func (ev *CQMEvent) parseCQMAttributes(b []byte) error {
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil { return err }
+
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_ATTR_CQM_RSSI_THRESHOLD_EVENT:
+			if nlenc.Uint32(a.Data) == unix.NL80211_CQM_RSSI_THRESHOLD_EVENT_HIGH {
+				ev.Type = CQMRSSIHigh
+			} else {
+				ev.Type = CQMRSSILow
+			}
+		case unix.NL80211_ATTR_CQM_RSSI_LEVEL:
+			ev.RSSI = nlenc.Int32(a.Data)
+		case unix.NL80211_ATTR_CQM_PKT_LOSS_EVENT:
+			ev.Type = CQMPacketLoss
+			ev.PacketLoss = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_CQM_BEACON_LOSS_EVENT:
+			ev.Type = CQMBeaconLoss
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package wifi_test
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/bryancoxwell/wifi"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// TestCQMRSSIAttribute tests the encoding of CQM RSSI thresholds.
+func TestCQMRSSIAttribute(t *testing.T) {
+	attrs := encodeAttributes(t, []wifi.AttributeEncoder{wifi.CQMRSSIAttribute([]int32{-80, -70}, 3)})
+	cqm := decodeNested(t, attrs[unix.NL80211_ATTR_CQM])
+
+	expected := append(nlenc.Int32Bytes(-80), nlenc.Int32Bytes(-70)...)
+	if got := cqm[unix.NL80211_ATTR_CQM_RSSI_THOLD]; !bytes.Equal(got, expected) {
+		t.Errorf("unexpected thresholds: %v, expected %v", got, expected)
+	}
+	if got := nlenc.Uint32(cqm[unix.NL80211_ATTR_CQM_RSSI_HYST]); got != 3 {
+		t.Errorf("unexpected hysteresis: %d, expected 3", got)
+	}
+}
+
+// TestValidateCQMThresholds tests the validation of CQM RSSI threshold
+// lists.
+func TestValidateCQMThresholds(t *testing.T) {
+	tests := []struct {
+		thresholds []int32
+		ok         bool
+	}{
+		{[]int32{-70}, true},
+		{[]int32{-80, -70, -60}, true},
+		{nil, false},
+		{[]int32{-60, -70}, false},
+		{[]int32{-70, -70}, false},
+		{[]int32{-70, 0}, false},
+	}
+	for _, tt := range tests {
+		err := wifi.ValidateCQMThresholds(tt.thresholds)
+		if (err == nil) != tt.ok {
+			t.Errorf("ValidateCQMThresholds(%v) = %v, expected ok %v", tt.thresholds, err, tt.ok)
+		}
+	}
+}
+
+// TestParseCQMEvent tests the parsing of NL80211_CMD_NOTIFY_CQM
+// notifications.
+func TestParseCQMEvent(t *testing.T) {
+	bssid := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}
+	tests := []struct {
+		name     string
+		cqm      []netlink.Attribute
+		expected wifi.CQMEvent
+	}{
+		{
+			name: "RSSI low",
+			cqm: []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_CQM_RSSI_THRESHOLD_EVENT, Data: nlenc.Uint32Bytes(unix.NL80211_CQM_RSSI_THRESHOLD_EVENT_LOW)},
+				{Type: unix.NL80211_ATTR_CQM_RSSI_LEVEL, Data: nlenc.Int32Bytes(-82)},
+			},
+			expected: wifi.CQMEvent{Type: wifi.CQMRSSILow, InterfaceIndex: 3, BSSID: bssid, RSSI: -82},
+		},
+		{
+			name: "RSSI high",
+			cqm: []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_CQM_RSSI_THRESHOLD_EVENT, Data: nlenc.Uint32Bytes(unix.NL80211_CQM_RSSI_THRESHOLD_EVENT_HIGH)},
+			},
+			expected: wifi.CQMEvent{Type: wifi.CQMRSSIHigh, InterfaceIndex: 3, BSSID: bssid},
+		},
+		{
+			name: "packet loss",
+			cqm: []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_CQM_PKT_LOSS_EVENT, Data: nlenc.Uint32Bytes(50)},
+			},
+			expected: wifi.CQMEvent{Type: wifi.CQMPacketLoss, InterfaceIndex: 3, BSSID: bssid, PacketLoss: 50},
+		},
+		{
+			name: "beacon loss",
+			cqm: []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_CQM_BEACON_LOSS_EVENT},
+			},
+			expected: wifi.CQMEvent{Type: wifi.CQMBeaconLoss, InterfaceIndex: 3, BSSID: bssid},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := genetlink.Message{
+				Header: genetlink.Header{Command: unix.NL80211_CMD_NOTIFY_CQM},
+				Data: mustMarshalAttributes(t, []netlink.Attribute{
+					{Type: unix.NL80211_ATTR_IFINDEX, Data: nlenc.Uint32Bytes(3)},
+					{Type: unix.NL80211_ATTR_MAC, Data: bssid},
+					{Type: unix.NL80211_ATTR_CQM, Data: mustMarshalAttributes(t, tt.cqm)},
+				}),
+			}
+			ev, ok := wifi.ParseEvent(m)
+			if !ok {
+				t.Fatalf("ParseEvent: event not recognized")
+			}
+			if !reflect.DeepEqual(tt.expected, ev) {
+				t.Fatalf("ParseEvent mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", tt.expected, ev)
+			}
+		})
+	}
+}
@@ -0,0 +1,659 @@
+//go:build linux
+// +build linux
+
+package wifi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// An Event is a notification received from nl80211 by SubscribeEvents or
+// SubscribeFrames. Its
+// concrete type is one of StationEvent, ConnectResult, DisconnectEvent,
+// RoamEvent, MichaelMICFailureEvent, ScanDoneEvent, ScanAbortedEvent,
+// InterfaceEvent, RegChangeEvent, BeaconHintEvent, CQMEvent, RadarEvent,
+// FrameEvent, FrameTxStatusEvent, ProbeClientEvent or
+// MeshPeerCandidateEvent, or RawEvent for notifications of any other kind.
+type Event interface {
+	isEvent()
+}
+
+func (StationEvent) isEvent()           {}
+func (ConnectResult) isEvent()          {}
+func (DisconnectEvent) isEvent()        {}
+func (RoamEvent) isEvent()              {}
+func (MichaelMICFailureEvent) isEvent() {}
+func (ScanDoneEvent) isEvent()          {}
+func (ScanAbortedEvent) isEvent()       {}
+func (InterfaceEvent) isEvent()         {}
+func (RegChangeEvent) isEvent()         {}
+func (BeaconHintEvent) isEvent()        {}
+func (CQMEvent) isEvent()               {}
+func (RadarEvent) isEvent()             {}
+func (FrameEvent) isEvent()             {}
+func (FrameTxStatusEvent) isEvent()     {}
+func (ProbeClientEvent) isEvent()       {}
+func (MeshPeerCandidateEvent) isEvent() {}
+func (RawEvent) isEvent()               {}
+
+// A RawEvent is a notification that SubscribeEvents has no Event type for.
+type RawEvent struct {
+	// Command is the NL80211_CMD_* command of the notification.
+	Command uint8
+
+	// Data holds the notification's netlink attributes, still encoded.
+	Data []byte
+}
+
+// SubscribeEvents joins the named nl80211 multicast groups, such as "config",
+// "scan", "mlme", "regulatory" or "vendor", and returns a channel of the
+// events received from them. With no groups, all of the groups advertised by
+// nl80211 are joined. Notifications that aren't parsed into one of the typed
+// events, including malformed ones, are delivered as a RawEvent. The channel
+// is closed once ctx is done or the Client is closed.
+//
+// Events are received on a connection of their own, so a slow consumer can
+// make the kernel drop notifications but never delays other requests.
+func (c *Client) SubscribeEvents(ctx context.Context, groups ...string) (<-chan Event, error) {
+	if len(groups) == 0 {
+		for name := range c.groups {
+			groups = append(groups, name)
+		}
+	}
+
+	conn, err := c.eventConn(groups...)
+	if err != nil { return nil, fmt.Errorf("SubscribeEvents: %v", err) }
+	if err := c.subscribe(conn, groups); err != nil {
+		c.closeEventConn(conn)
+		return nil, fmt.Errorf("SubscribeEvents: %v", err)
+	}
+	return c.deliverEvents(ctx, conn), nil
+}
+
+// subscribe marks conn, an event connection already joined to groups, as
+// one of the SubscribeEvents connections, joining it to the groups added by
+// JoinGroup as well.
+func (c *Client) subscribe(conn *genetlink.Conn, groups []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, name := range c.joined {
+		if containsGroup(groups, name) { continue }
+		if err := conn.JoinGroup(c.groups[name]); err != nil {
+			return fmt.Errorf("failed to join multicast group %q: %w", name, err)
+		}
+	}
+
+	if c.subscriptions == nil {
+		c.subscriptions = make(map[*genetlink.Conn]bool)
+	}
+	c.subscriptions[conn] = true
+	return nil
+}
+
+// containsGroup reports whether name is one of groups.
+func containsGroup(groups []string, name string) bool {
+	for _, g := range groups {
+		if g == name { return true }
+	}
+	return false
+}
+
+// deliverEvents returns a channel of the events received on conn, a
+// connection opened by eventConn, which is closed along with the channel
+// once ctx is done or the Client is closed.
+func (c *Client) deliverEvents(ctx context.Context, conn *genetlink.Conn) <-chan Event {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		c.closeEventConn(conn)
+	}()
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		defer close(done)
+		for {
+			msgs, _, err := conn.Receive()
+			// ENOBUFS means the socket buffer overflowed and some
+			// notifications were lost; the subscription itself is intact.
+			if errors.Is(err, unix.ENOBUFS) { continue }
+			if err != nil { return }
+
+			for _, m := range msgs {
+				ev, ok := parseEvent(m)
+				if !ok {
+					ev = RawEvent{Command: m.Header.Command, Data: m.Data}
+				}
+				select {
+				case events <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events
+}
+
+// parseEvent parses a multicast notification into one of the typed Events,
+// reporting false for notifications of any other kind.
+func parseEvent(m genetlink.Message) (Event, bool) {
+	switch m.Header.Command {
+	case unix.NL80211_CMD_NEW_STATION, unix.NL80211_CMD_DEL_STATION:
+		ev, ok := parseStationEvent(m)
+		if !ok { return nil, false }
+		return *ev, true
+	case unix.NL80211_CMD_CONNECT:
+		result, err := parseConnectResult(m)
+		if err != nil { return nil, false }
+		return *result, true
+	case unix.NL80211_CMD_DISCONNECT:
+		ev, err := parseDisconnectEvent(m)
+		if err != nil { return nil, false }
+		return *ev, true
+	case unix.NL80211_CMD_ROAM:
+		ev, err := parseRoamEvent(m)
+		if err != nil { return nil, false }
+		return *ev, true
+	case unix.NL80211_CMD_MICHAEL_MIC_FAILURE:
+		ev, err := parseMichaelMICFailureEvent(m)
+		if err != nil { return nil, false }
+		return *ev, true
+	case unix.NL80211_CMD_NEW_SCAN_RESULTS:
+		ev, err := parseScanDoneEvent(m)
+		if err != nil { return nil, false }
+		return *ev, true
+	case unix.NL80211_CMD_SCAN_ABORTED:
+		ev, err := parseScanDoneEvent(m)
+		if err != nil { return nil, false }
+		return ScanAbortedEvent{InterfaceIndex: ev.InterfaceIndex, WiphyIndex: ev.WiphyIndex}, true
+	case unix.NL80211_CMD_NEW_INTERFACE, unix.NL80211_CMD_DEL_INTERFACE:
+		ev, err := parseInterfaceEvent(m)
+		if err != nil { return nil, false }
+		return *ev, true
+	case unix.NL80211_CMD_REG_CHANGE, unix.NL80211_CMD_WIPHY_REG_CHANGE:
+		ev, err := parseRegChangeEvent(m)
+		if err != nil { return nil, false }
+		return *ev, true
+	case unix.NL80211_CMD_REG_BEACON_HINT:
+		ev, err := parseBeaconHintEvent(m)
+		if err != nil { return nil, false }
+		return *ev, true
+	case unix.NL80211_CMD_NOTIFY_CQM:
+		ev, err := parseCQMEvent(m)
+		if err != nil { return nil, false }
+		return *ev, true
+	case unix.NL80211_CMD_RADAR_DETECT:
+		ev, err := parseRadarEvent(m)
+		if err != nil { return nil, false }
+		return *ev, true
+	case unix.NL80211_CMD_FRAME:
+		ev, err := parseFrameEvent(m)
+		if err != nil { return nil, false }
+		return *ev, true
+	case unix.NL80211_CMD_FRAME_TX_STATUS:
+		ev, err := parseFrameTxStatusEvent(m)
+		if err != nil { return nil, false }
+		return *ev, true
+	case unix.NL80211_CMD_PROBE_CLIENT:
+		ev, err := parseProbeClientEvent(m)
+		if err != nil { return nil, false }
+		return *ev, true
+	case unix.NL80211_CMD_NEW_PEER_CANDIDATE:
+		ev, err := parseMeshPeerCandidateEvent(m)
+		if err != nil { return nil, false }
+		return *ev, true
+	default:
+		return nil, false
+	}
+}
+
+// A StationAction is the change reported by a StationEvent.
+type StationAction int
+
+const (
+	StationAdded StationAction = iota
+	StationRemoved
+)
+
+// String returns the string representation of a StationAction.
+func (a StationAction) String() string {
+	switch a {
+	case StationAdded:
+		return "added"
+	case StationRemoved:
+		return "removed"
+	default:
+		return fmt.Sprintf("unknown(%d)", a)
+	}
+}
+
+// A StationEvent reports a station being added to or removed from an
+// interface, such as a client associating with or leaving an AP.
+type StationEvent struct {
+	Action         StationAction
+	InterfaceIndex uint32
+	WiphyIndex     uint32
+	HardwareAddr   net.HardwareAddr
+}
+
+// StationEvents returns a channel of StationEvents for the given interface,
+// built from the NL80211_CMD_NEW_STATION and NL80211_CMD_DEL_STATION
+// notifications of the "mlme" multicast group. Like SubscribeEvents, the
+// channel is closed once ctx is done or the Client is closed; callers that
+// stop reading must cancel ctx. Without ctx, the goroutine delivering the
+// events would block forever on a channel nobody reads.
+func (c *Client) StationEvents(ctx context.Context, w *WifiInterface) (<-chan StationEvent, error) {
+	conn, err := c.eventConn("mlme")
+	if err != nil { return nil, fmt.Errorf("StationEvents: %v", err) }
+	all := c.deliverEvents(ctx, conn)
+
+	events := make(chan StationEvent)
+	go func() {
+		defer close(events)
+		for ev := range all {
+			sev, ok := ev.(StationEvent)
+			if !ok || sev.InterfaceIndex != w.Index { continue }
+			select {
+			case events <- sev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// A ConnectResult is the outcome of a connection attempt, as reported by
+// the kernel once association has succeeded or failed.
+type ConnectResult struct {
+	InterfaceIndex uint32
+	WiphyIndex     uint32
+	BSSID          net.HardwareAddr
+
+	// StatusCode is the IEEE 802.11 status code of the attempt, where 0
+	// means success.
+	StatusCode uint16
+
+	// TimedOut is set when no response was received from the AP.
+	TimedOut bool
+}
+
+// WaitForConnect requests a connection like Connect and blocks until the
+// kernel reports its result through a NL80211_CMD_CONNECT event, or until ctx
+// is done. A result with a non-zero StatusCode or TimedOut set means
+// association failed.
+//
+// WaitForConnect issues the connection itself rather than waiting on one
+// requested earlier with Connect: the "mlme" group has to be joined before
+// the connection is requested, or a result arriving in between is missed and
+// WaitForConnect blocks until ctx is done. Callers must not call Connect as
+// well.
+func (c *Client) WaitForConnect(ctx context.Context, w *WifiInterface, opts *ConnectOptions) (*ConnectResult, error) {
+	conn, err := c.eventConn("mlme")
+	if err != nil { return nil, fmt.Errorf("WaitForConnect: %v", err) }
+
+	// Closing the connection is the only way to interrupt Receive.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		c.closeEventConn(conn)
+	}()
+
+	if err := c.Connect(w, opts); err != nil { return nil, fmt.Errorf("WaitForConnect: %w", err) }
+
+	result, err := waitForConnect(conn, w)
+	if err != nil {
+		if ctx.Err() != nil { return nil, fmt.Errorf("WaitForConnect: %w", ctx.Err()) }
+		return nil, fmt.Errorf("WaitForConnect: %w", err)
+	}
+	return result, nil
+}
+
+// waitForConnect waits for the NL80211_CMD_CONNECT event reporting the
+// result of a connection attempt on w.
+func waitForConnect(conn eventReceiver, w *WifiInterface) (*ConnectResult, error) {
+	for {
+		msgs, _, err := conn.Receive()
+		if err != nil { return nil, err }
+
+		for _, m := range msgs {
+			if m.Header.Command != unix.NL80211_CMD_CONNECT { continue }
+
+			result, err := parseConnectResult(m)
+			if err != nil { return nil, err }
+			if result.InterfaceIndex == w.Index {
+				return result, nil
+			}
+		}
+	}
+}
+
+// A DisconnectEvent reports an interface losing its connection to an AP.
+type DisconnectEvent struct {
+	InterfaceIndex uint32
+	WiphyIndex     uint32
+
+	// ReasonCode is the IEEE 802.11 reason code of the disconnection.
+	ReasonCode uint16
+
+	// LocallyGenerated is set when the disconnection was initiated by this
+	// station rather than by the AP.
+	LocallyGenerated bool
+}
+
+// A RoamEvent reports an interface having moved to another AP of the same
+// network.
+type RoamEvent struct {
+	InterfaceIndex uint32
+	WiphyIndex     uint32
+	BSSID          net.HardwareAddr
+}
+
+// A MichaelMICFailureEvent reports a TKIP frame that failed its Michael MIC
+// check, which may indicate an attack on the network.
+type MichaelMICFailureEvent struct {
+	InterfaceIndex uint32
+	WiphyIndex     uint32
+
+	// HardwareAddr is the transmitter of the frame, when known.
+	HardwareAddr net.HardwareAddr
+
+	// Pairwise is set when the frame was protected with the pairwise key
+	// rather than the group key.
+	Pairwise bool
+
+	// KeyIndex is the index of the key used, or -1 if it isn't reported.
+	KeyIndex int
+}
+
+// A ScanDoneEvent reports a scan having completed, with new results
+// available from ScanResults.
+type ScanDoneEvent struct {
+	InterfaceIndex uint32
+	WiphyIndex     uint32
+
+	// Frequencies are the frequencies that were scanned, in MHz.
+	Frequencies []uint32
+}
+
+// A ScanAbortedEvent reports a scan having been aborted before completing.
+type ScanAbortedEvent struct {
+	InterfaceIndex uint32
+	WiphyIndex     uint32
+}
+
+// An InterfaceAction is the change reported by an InterfaceEvent.
+type InterfaceAction int
+
+const (
+	InterfaceAdded InterfaceAction = iota
+	InterfaceRemoved
+)
+
+// String returns the string representation of an InterfaceAction.
+func (a InterfaceAction) String() string {
+	switch a {
+	case InterfaceAdded:
+		return "added"
+	case InterfaceRemoved:
+		return "removed"
+	default:
+		return fmt.Sprintf("unknown(%d)", a)
+	}
+}
+
+// An InterfaceEvent reports a wireless interface being created or deleted.
+// It is sent on the "config" multicast group.
+type InterfaceEvent struct {
+	Action    InterfaceAction
+	Interface WifiInterface
+}
+
+// MulticastGroups returns the nl80211 multicast groups advertised by the
+// kernel, mapping their names, such as "mlme" or "scan", to their IDs.
+func (c *Client) MulticastGroups() map[string]uint32 {
+	groups := make(map[string]uint32, len(c.groups))
+	for name, id := range c.groups {
+		groups[name] = id
+	}
+	return groups
+}
+
+// JoinGroup joins the named nl80211 multicast group on the connections of
+// the SubscribeEvents subscriptions, so that running subscriptions start
+// delivering its notifications, and so do those made afterwards. The
+// connections used for requests, frames and the Wait* helpers never join it,
+// so that they only see the messages they are waiting for.
+func (c *Client) JoinGroup(name string) error {
+	id, ok := c.groups[name]
+	if !ok { return fmt.Errorf("JoinGroup: unknown nl80211 multicast group %q", name) }
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for conn := range c.subscriptions {
+		if err := conn.JoinGroup(id); err != nil { return fmt.Errorf("JoinGroup: %w", err) }
+	}
+	if !containsGroup(c.joined, name) {
+		c.joined = append(c.joined, name)
+	}
+	return nil
+}
+
+// eventReceiver is the part of an event connection that receives
+// notifications, implemented by *genetlink.Conn.
+type eventReceiver interface {
+	Receive() ([]genetlink.Message, []netlink.Message, error)
+}
+
+// eventConn opens a new generic netlink connection joined to the named
+// nl80211 multicast groups. Events get a connection of their own so that
+// they never interleave with the request/response traffic on c.c.
+func (c *Client) eventConn(groups ...string) (*genetlink.Conn, error) {
+	conn, err := dialConn(c.opts)
+	if err != nil { return nil, err }
+
+	for _, name := range groups {
+		id, ok := c.groups[name]
+		if !ok {
+			conn.Close()
+			return nil, fmt.Errorf("unknown nl80211 multicast group %q", name)
+		}
+		if err := conn.JoinGroup(id); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to join multicast group %q: %w", name, err)
+		}
+	}
+
+	c.mu.Lock()
+	c.eventConns = append(c.eventConns, conn)
+	c.mu.Unlock()
+	return conn, nil
+}
+
+// closeEventConn closes a connection opened by eventConn, if it has not
+// been closed already.
+func (c *Client) closeEventConn(conn *genetlink.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, ec := range c.eventConns {
+		if ec == conn {
+			c.eventConns = append(c.eventConns[:i], c.eventConns[i+1:]...)
+			delete(c.subscriptions, conn)
+			conn.Close()
+			return
+		}
+	}
+}
+
+// parseConnectResult parses a NL80211_CMD_CONNECT event.
+func parseConnectResult(m genetlink.Message) (*ConnectResult, error) {
+	attrs, err := netlink.UnmarshalAttributes(m.Data)
+	if err != nil { return nil, fmt.Errorf("parseConnectResult: %v", err) }
+
+	result := &ConnectResult{}
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_ATTR_IFINDEX:
+			result.InterfaceIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_WIPHY:
+			result.WiphyIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_MAC:
+			result.BSSID = net.HardwareAddr(a.Data)
+		case unix.NL80211_ATTR_STATUS_CODE:
+			result.StatusCode = nlenc.Uint16(a.Data)
+		case unix.NL80211_ATTR_TIMED_OUT:
+			result.TimedOut = true
+		}
+	}
+	return result, nil
+}
+
+// parseStationEvent parses a NL80211_CMD_NEW_STATION or NL80211_CMD_DEL_STATION
+// notification, reporting false for any other message.
+func parseStationEvent(m genetlink.Message) (*StationEvent, bool) {
+	ev := &StationEvent{}
+	switch m.Header.Command {
+	case unix.NL80211_CMD_NEW_STATION:
+		ev.Action = StationAdded
+	case unix.NL80211_CMD_DEL_STATION:
+		ev.Action = StationRemoved
+	default:
+		return nil, false
+	}
+
+	attrs, err := netlink.UnmarshalAttributes(m.Data)
+	if err != nil { return nil, false }
+
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_ATTR_IFINDEX:
+			ev.InterfaceIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_WIPHY:
+			ev.WiphyIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_MAC:
+			ev.HardwareAddr = net.HardwareAddr(a.Data)
+		}
+	}
+	return ev, true
+}
+
+// parseDisconnectEvent parses a NL80211_CMD_DISCONNECT notification.
+func parseDisconnectEvent(m genetlink.Message) (*DisconnectEvent, error) {
+	attrs, err := netlink.UnmarshalAttributes(m.Data)
+	if err != nil { return nil, fmt.Errorf("parseDisconnectEvent: %v", err) }
+
+	ev := &DisconnectEvent{LocallyGenerated: true}
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_ATTR_IFINDEX:
+			ev.InterfaceIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_WIPHY:
+			ev.WiphyIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_REASON_CODE:
+			ev.ReasonCode = nlenc.Uint16(a.Data)
+		case unix.NL80211_ATTR_DISCONNECTED_BY_AP:
+			ev.LocallyGenerated = false
+		}
+	}
+	return ev, nil
+}
+
+// parseRoamEvent parses a NL80211_CMD_ROAM notification.
+func parseRoamEvent(m genetlink.Message) (*RoamEvent, error) {
+	attrs, err := netlink.UnmarshalAttributes(m.Data)
+	if err != nil { return nil, fmt.Errorf("parseRoamEvent: %v", err) }
+
+	ev := &RoamEvent{}
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_ATTR_IFINDEX:
+			ev.InterfaceIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_WIPHY:
+			ev.WiphyIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_MAC:
+			ev.BSSID = net.HardwareAddr(a.Data)
+		}
+	}
+	return ev, nil
+}
+
+// parseMichaelMICFailureEvent parses a NL80211_CMD_MICHAEL_MIC_FAILURE
+// notification.
+func parseMichaelMICFailureEvent(m genetlink.Message) (*MichaelMICFailureEvent, error) {
+	attrs, err := netlink.UnmarshalAttributes(m.Data)
+	if err != nil { return nil, fmt.Errorf("parseMichaelMICFailureEvent: %v", err) }
+
+	ev := &MichaelMICFailureEvent{KeyIndex: -1}
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_ATTR_IFINDEX:
+			ev.InterfaceIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_WIPHY:
+			ev.WiphyIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_MAC:
+			ev.HardwareAddr = net.HardwareAddr(a.Data)
+		case unix.NL80211_ATTR_KEY_TYPE:
+			ev.Pairwise = nlenc.Uint32(a.Data) == unix.NL80211_KEYTYPE_PAIRWISE
+		case unix.NL80211_ATTR_KEY_IDX:
+			if len(a.Data) >= 1 { ev.KeyIndex = int(a.Data[0]) }
+		}
+	}
+	return ev, nil
+}
+
+// parseScanDoneEvent parses a NL80211_CMD_NEW_SCAN_RESULTS or
+// NL80211_CMD_SCAN_ABORTED notification.
+func parseScanDoneEvent(m genetlink.Message) (*ScanDoneEvent, error) {
+	attrs, err := netlink.UnmarshalAttributes(m.Data)
+	if err != nil { return nil, fmt.Errorf("parseScanDoneEvent: %v", err) }
+
+	ev := &ScanDoneEvent{}
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_ATTR_IFINDEX:
+			ev.InterfaceIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_WIPHY:
+			ev.WiphyIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_SCAN_FREQUENCIES:
+			freqs, err := netlink.UnmarshalAttributes(a.Data)
+			if err != nil { return nil, fmt.Errorf("parseScanDoneEvent: %v", err) }
+
+			ev.Frequencies = make([]uint32, 0, len(freqs))
+			for _, f := range freqs {
+				ev.Frequencies = append(ev.Frequencies, nlenc.Uint32(f.Data))
+			}
+		}
+	}
+	return ev, nil
+}
+
+// parseInterfaceEvent parses a NL80211_CMD_NEW_INTERFACE or
+// NL80211_CMD_DEL_INTERFACE notification.
+func parseInterfaceEvent(m genetlink.Message) (*InterfaceEvent, error) {
+	attrs, err := netlink.UnmarshalAttributes(m.Data)
+	if err != nil { return nil, fmt.Errorf("parseInterfaceEvent: %v", err) }
+
+	ev := &InterfaceEvent{Interface: *parseInterface(attrs)}
+	if m.Header.Command == unix.NL80211_CMD_DEL_INTERFACE {
+		ev.Action = InterfaceRemoved
+	}
+	return ev, nil
+}
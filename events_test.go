@@ -0,0 +1,422 @@
+package wifi_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/bryancoxwell/wifi"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// TestParseEvent tests the parsing of connection, scan, radar and interface
+// notifications into typed events.
+func TestParseEvent(t *testing.T) {
+	mac := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x01, 0x00}
+	ids := []netlink.Attribute{
+		{Type: unix.NL80211_ATTR_IFINDEX, Data: nlenc.Uint32Bytes(3)},
+		{Type: unix.NL80211_ATTR_WIPHY, Data: nlenc.Uint32Bytes(1)},
+	}
+
+	tests := []struct {
+		name     string
+		cmd      uint8
+		attrs    []netlink.Attribute
+		expected wifi.Event
+	}{
+		{
+			name: "station added",
+			cmd:  unix.NL80211_CMD_NEW_STATION,
+			attrs: []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_MAC, Data: mac},
+			},
+			expected: wifi.StationEvent{Action: wifi.StationAdded, InterfaceIndex: 3, WiphyIndex: 1, HardwareAddr: mac},
+		},
+		{
+			name: "connect",
+			cmd:  unix.NL80211_CMD_CONNECT,
+			attrs: []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_MAC, Data: mac},
+				{Type: unix.NL80211_ATTR_STATUS_CODE, Data: nlenc.Uint16Bytes(17)},
+			},
+			expected: wifi.ConnectResult{InterfaceIndex: 3, WiphyIndex: 1, BSSID: mac, StatusCode: 17},
+		},
+		{
+			name: "disconnected by AP",
+			cmd:  unix.NL80211_CMD_DISCONNECT,
+			attrs: []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_REASON_CODE, Data: nlenc.Uint16Bytes(3)},
+				{Type: unix.NL80211_ATTR_DISCONNECTED_BY_AP},
+			},
+			expected: wifi.DisconnectEvent{InterfaceIndex: 3, WiphyIndex: 1, ReasonCode: 3},
+		},
+		{
+			name: "disconnected locally",
+			cmd:  unix.NL80211_CMD_DISCONNECT,
+			attrs: []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_REASON_CODE, Data: nlenc.Uint16Bytes(3)},
+			},
+			expected: wifi.DisconnectEvent{InterfaceIndex: 3, WiphyIndex: 1, ReasonCode: 3, LocallyGenerated: true},
+		},
+		{
+			name: "roam",
+			cmd:  unix.NL80211_CMD_ROAM,
+			attrs: []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_MAC, Data: mac},
+			},
+			expected: wifi.RoamEvent{InterfaceIndex: 3, WiphyIndex: 1, BSSID: mac},
+		},
+		{
+			name: "Michael MIC failure",
+			cmd:  unix.NL80211_CMD_MICHAEL_MIC_FAILURE,
+			attrs: []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_MAC, Data: mac},
+				{Type: unix.NL80211_ATTR_KEY_TYPE, Data: nlenc.Uint32Bytes(unix.NL80211_KEYTYPE_PAIRWISE)},
+				{Type: unix.NL80211_ATTR_KEY_IDX, Data: []byte{0}},
+			},
+			expected: wifi.MichaelMICFailureEvent{InterfaceIndex: 3, WiphyIndex: 1, HardwareAddr: mac, Pairwise: true, KeyIndex: 0},
+		},
+		{
+			name: "Michael MIC failure with an empty key index",
+			cmd:  unix.NL80211_CMD_MICHAEL_MIC_FAILURE,
+			attrs: []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_MAC, Data: mac},
+				{Type: unix.NL80211_ATTR_KEY_IDX},
+			},
+			expected: wifi.MichaelMICFailureEvent{InterfaceIndex: 3, WiphyIndex: 1, HardwareAddr: mac, KeyIndex: -1},
+		},
+		{
+			name: "scan done",
+			cmd:  unix.NL80211_CMD_NEW_SCAN_RESULTS,
+			attrs: []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_SCAN_FREQUENCIES, Data: mustMarshalAttributes(t, []netlink.Attribute{
+					{Type: 0, Data: nlenc.Uint32Bytes(2412)},
+					{Type: 1, Data: nlenc.Uint32Bytes(5180)},
+				})},
+			},
+			expected: wifi.ScanDoneEvent{InterfaceIndex: 3, WiphyIndex: 1, Frequencies: []uint32{2412, 5180}},
+		},
+		{
+			name:     "scan aborted",
+			cmd:      unix.NL80211_CMD_SCAN_ABORTED,
+			expected: wifi.ScanAbortedEvent{InterfaceIndex: 3, WiphyIndex: 1},
+		},
+		{
+			name: "radar detected",
+			cmd:  unix.NL80211_CMD_RADAR_DETECT,
+			attrs: []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_RADAR_EVENT, Data: nlenc.Uint32Bytes(unix.NL80211_RADAR_DETECTED)},
+				{Type: unix.NL80211_ATTR_WIPHY_FREQ, Data: nlenc.Uint32Bytes(5500)},
+				{Type: unix.NL80211_ATTR_CHANNEL_WIDTH, Data: nlenc.Uint32Bytes(unix.NL80211_CHAN_WIDTH_80)},
+				{Type: unix.NL80211_ATTR_CENTER_FREQ1, Data: nlenc.Uint32Bytes(5530)},
+			},
+			expected: wifi.RadarEvent{
+				Type:           wifi.RadarDetected,
+				InterfaceIndex: 3,
+				WiphyIndex:     1,
+				Channel:        wifi.ChannelDefinition{Frequency: 5500, Width: wifi.ChannelWidth80, CenterFrequency1: 5530},
+			},
+		},
+		{
+			name: "CAC finished",
+			cmd:  unix.NL80211_CMD_RADAR_DETECT,
+			attrs: []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_RADAR_EVENT, Data: nlenc.Uint32Bytes(unix.NL80211_RADAR_CAC_FINISHED)},
+				{Type: unix.NL80211_ATTR_WIPHY_FREQ, Data: nlenc.Uint32Bytes(5260)},
+				{Type: unix.NL80211_ATTR_CHANNEL_WIDTH, Data: nlenc.Uint32Bytes(unix.NL80211_CHAN_WIDTH_20)},
+				{Type: unix.NL80211_ATTR_CENTER_FREQ1, Data: nlenc.Uint32Bytes(5260)},
+			},
+			expected: wifi.RadarEvent{
+				Type:           wifi.RadarCACFinished,
+				InterfaceIndex: 3,
+				WiphyIndex:     1,
+				Channel:        wifi.ChannelDefinition{Frequency: 5260, Width: wifi.ChannelWidth20, CenterFrequency1: 5260},
+			},
+		},
+		{
+			name: "probe client",
+			cmd:  unix.NL80211_CMD_PROBE_CLIENT,
+			attrs: []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_MAC, Data: mac},
+				{Type: unix.NL80211_ATTR_COOKIE, Data: nlenc.Uint64Bytes(7)},
+				{Type: unix.NL80211_ATTR_ACK},
+			},
+			expected: wifi.ProbeClientEvent{InterfaceIndex: 3, WiphyIndex: 1, HardwareAddr: mac, Cookie: 7, Acked: true},
+		},
+		{
+			name: "mesh peer candidate",
+			cmd:  unix.NL80211_CMD_NEW_PEER_CANDIDATE,
+			attrs: []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_MAC, Data: mac},
+				{Type: unix.NL80211_ATTR_RX_SIGNAL_DBM, Data: nlenc.Int32Bytes(-55)},
+				{Type: unix.NL80211_ATTR_IE, Data: []byte{114, 4, 'm', 'e', 's', 'h'}},
+			},
+			expected: wifi.MeshPeerCandidateEvent{InterfaceIndex: 3, WiphyIndex: 1, HardwareAddr: mac, Signal: -55, IEs: []byte{114, 4, 'm', 'e', 's', 'h'}},
+		},
+		{
+			name: "interface removed",
+			cmd:  unix.NL80211_CMD_DEL_INTERFACE,
+			attrs: []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_IFNAME, Data: nlenc.Bytes("wlan1")},
+				{Type: unix.NL80211_ATTR_IFTYPE, Data: nlenc.Uint32Bytes(unix.NL80211_IFTYPE_MONITOR)},
+			},
+			expected: wifi.InterfaceEvent{
+				Action:    wifi.InterfaceRemoved,
+				Interface: wifi.WifiInterface{Index: 3, Phy: 1, Name: "wlan1", Type: wifi.InterfaceTypeMonitor},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := genetlink.Message{
+				Header: genetlink.Header{Command: tt.cmd},
+				Data:   mustMarshalAttributes(t, append(ids, tt.attrs...)),
+			}
+			ev, ok := wifi.ParseEvent(m)
+			if !ok {
+				t.Fatalf("ParseEvent: event not recognized")
+			}
+			if !reflect.DeepEqual(tt.expected, ev) {
+				t.Fatalf("ParseEvent mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", tt.expected, ev)
+			}
+		})
+	}
+}
+
+// TestParseStationEvent tests the parsing of station notifications and the
+// rejection of other commands.
+func TestParseStationEvent(t *testing.T) {
+	mac := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x01, 0x00}
+	data, err := netlink.MarshalAttributes([]netlink.Attribute{
+		{Type: unix.NL80211_ATTR_IFINDEX, Data: nlenc.Uint32Bytes(3)},
+		{Type: unix.NL80211_ATTR_WIPHY, Data: nlenc.Uint32Bytes(1)},
+		{Type: unix.NL80211_ATTR_MAC, Data: mac},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal attributes: %v", err)
+	}
+
+	tests := []struct {
+		cmd    uint8
+		action wifi.StationAction
+	}{
+		{cmd: unix.NL80211_CMD_NEW_STATION, action: wifi.StationAdded},
+		{cmd: unix.NL80211_CMD_DEL_STATION, action: wifi.StationRemoved},
+	}
+	for _, tt := range tests {
+		ev, ok := wifi.ParseStationEvent(genetlink.Message{Header: genetlink.Header{Command: tt.cmd}, Data: data})
+		if !ok {
+			t.Fatalf("%v: event not recognized", tt.action)
+		}
+		expected := &wifi.StationEvent{Action: tt.action, InterfaceIndex: 3, WiphyIndex: 1, HardwareAddr: mac}
+		if !reflect.DeepEqual(expected, ev) {
+			t.Errorf("ParseStationEvent mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", expected, ev)
+		}
+	}
+
+	if _, ok := wifi.ParseStationEvent(genetlink.Message{Header: genetlink.Header{Command: unix.NL80211_CMD_CONNECT}, Data: data}); ok {
+		t.Error("unexpected station event for NL80211_CMD_CONNECT")
+	}
+	if _, ok := wifi.ParseStationEvent(genetlink.Message{Header: genetlink.Header{Command: unix.NL80211_CMD_NEW_STATION}, Data: []byte{1}}); ok {
+		t.Error("unexpected station event for malformed attributes")
+	}
+}
+
+// eventReceiver delivers batches of notifications, one per call to Receive,
+// and then fails.
+type eventReceiver [][]genetlink.Message
+
+var errNoMoreEvents = errors.New("no more events")
+
+func (r *eventReceiver) Receive() ([]genetlink.Message, []netlink.Message, error) {
+	if len(*r) == 0 {
+		return nil, nil, errNoMoreEvents
+	}
+	msgs := (*r)[0]
+	*r = (*r)[1:]
+	return msgs, nil, nil
+}
+
+// event returns a notification with the given command and attributes.
+func event(t *testing.T, cmd uint8, attrs ...netlink.Attribute) genetlink.Message {
+	t.Helper()
+	return genetlink.Message{Header: genetlink.Header{Command: cmd}, Data: mustMarshalAttributes(t, attrs)}
+}
+
+// TestWaitForConnectResult tests that the connect result of the interface is
+// picked out of the notifications of other interfaces and commands.
+func TestWaitForConnectResult(t *testing.T) {
+	w := &wifi.WifiInterface{Index: 3, Name: "wlan0"}
+	bssid := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x01, 0x00}
+	ifindex := func(i uint32) netlink.Attribute {
+		return netlink.Attribute{Type: unix.NL80211_ATTR_IFINDEX, Data: nlenc.Uint32Bytes(i)}
+	}
+
+	r := &eventReceiver{
+		{event(t, unix.NL80211_CMD_NEW_STATION, ifindex(3))},
+		{
+			event(t, unix.NL80211_CMD_CONNECT, ifindex(4), netlink.Attribute{Type: unix.NL80211_ATTR_STATUS_CODE, Data: nlenc.Uint16Bytes(0)}),
+			event(t, unix.NL80211_CMD_CONNECT, ifindex(3),
+				netlink.Attribute{Type: unix.NL80211_ATTR_MAC, Data: bssid},
+				netlink.Attribute{Type: unix.NL80211_ATTR_STATUS_CODE, Data: nlenc.Uint16Bytes(15)},
+			),
+		},
+	}
+	result, err := wifi.WaitForConnectResult(r, w)
+	if err != nil {
+		t.Fatalf("WaitForConnectResult: %v", err)
+	}
+	expected := &wifi.ConnectResult{InterfaceIndex: 3, BSSID: bssid, StatusCode: 15}
+	if !reflect.DeepEqual(expected, result) {
+		t.Fatalf("WaitForConnectResult mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", expected, result)
+	}
+
+	r = &eventReceiver{{event(t, unix.NL80211_CMD_CONNECT, ifindex(4))}}
+	if _, err := wifi.WaitForConnectResult(r, w); !errors.Is(err, errNoMoreEvents) {
+		t.Errorf("got error %v, expected the receive error", err)
+	}
+}
+
+// TestWaitForConnectJoinsFirst tests that WaitForConnect joins the "mlme"
+// group before requesting the connection, failing without a request when
+// the group can't be joined.
+func TestWaitForConnectJoinsFirst(t *testing.T) {
+	w := &wifi.WifiInterface{Index: 3, Name: "wlan0"}
+	opts := &wifi.ConnectOptions{SSID: "cafe", Security: wifi.SecurityOpen}
+	if _, err := (&wifi.Client{}).WaitForConnect(context.Background(), w, opts); err == nil {
+		t.Fatal("expected an error without the mlme group")
+	}
+}
+
+// TestJoinGroupUnknown tests that JoinGroup rejects groups nl80211 does not
+// advertise.
+func TestJoinGroupUnknown(t *testing.T) {
+	c := &wifi.Client{}
+	if groups := c.MulticastGroups(); len(groups) != 0 {
+		t.Fatalf("unexpected groups: %v", groups)
+	}
+	if err := c.JoinGroup("mlme"); err == nil {
+		t.Fatalf("JoinGroup: expected an error for an unknown group")
+	}
+}
+
+// groupSocket is a netlink socket recording the multicast groups it joins,
+// whose Receive blocks until it is closed.
+type groupSocket struct {
+	mu     sync.Mutex
+	groups []uint32
+	once   sync.Once
+	closed chan struct{}
+}
+
+func (s *groupSocket) Send(netlink.Message) error           { return nil }
+func (s *groupSocket) SendMessages([]netlink.Message) error { return nil }
+
+func (s *groupSocket) Receive() ([]netlink.Message, error) {
+	<-s.closed
+	return nil, errors.New("socket closed")
+}
+
+func (s *groupSocket) Close() error {
+	s.once.Do(func() { close(s.closed) })
+	return nil
+}
+
+func (s *groupSocket) JoinGroup(group uint32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.groups = append(s.groups, group)
+	return nil
+}
+
+func (s *groupSocket) LeaveGroup(uint32) error { return nil }
+
+func (s *groupSocket) joined() []uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]uint32(nil), s.groups...)
+}
+
+// dialGroupSockets makes the package open its generic netlink connections
+// on groupSockets, returning a function reporting those opened so far.
+func dialGroupSockets(t *testing.T) func() []*groupSocket {
+	t.Helper()
+	var (
+		mu      sync.Mutex
+		sockets []*groupSocket
+	)
+	restore := wifi.SetDialConn(func(wifi.Options) (*genetlink.Conn, error) {
+		s := &groupSocket{closed: make(chan struct{})}
+		mu.Lock()
+		sockets = append(sockets, s)
+		mu.Unlock()
+		return genetlink.NewConn(netlink.NewConn(s, 0)), nil
+	})
+	t.Cleanup(restore)
+	return func() []*groupSocket {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*groupSocket(nil), sockets...)
+	}
+}
+
+// TestJoinGroupLaterSubscriptions tests that a group joined with no
+// subscription open is joined by the subscriptions made afterwards.
+func TestJoinGroupLaterSubscriptions(t *testing.T) {
+	sockets := dialGroupSockets(t)
+	c := wifi.NewClientGroups(map[string]uint32{"mlme": 5, "scan": 6})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := c.JoinGroup("mlme"); err != nil {
+		t.Fatalf("JoinGroup: %v", err)
+	}
+	if _, err := c.SubscribeEvents(ctx, "scan"); err != nil {
+		t.Fatalf("SubscribeEvents: %v", err)
+	}
+
+	opened := sockets()
+	if len(opened) != 1 {
+		t.Fatalf("got %d connections, expected 1", len(opened))
+	}
+	if groups := opened[0].joined(); !reflect.DeepEqual(groups, []uint32{6, 5}) {
+		t.Errorf("subscription joined groups %v, expected [6 5]", groups)
+	}
+}
+
+// TestJoinGroupSubscriptionsOnly tests that JoinGroup joins the group on the
+// running subscriptions but not on the other event connections, such as
+// the one receiving frames.
+func TestJoinGroupSubscriptionsOnly(t *testing.T) {
+	sockets := dialGroupSockets(t)
+	c := wifi.NewClientGroups(map[string]uint32{"mlme": 5, "scan": 6})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := c.SubscribeEvents(ctx, "scan"); err != nil {
+		t.Fatalf("SubscribeEvents: %v", err)
+	}
+	if _, err := c.EventConn(); err != nil {
+		t.Fatalf("eventConn: %v", err)
+	}
+	if err := c.JoinGroup("mlme"); err != nil {
+		t.Fatalf("JoinGroup: %v", err)
+	}
+
+	opened := sockets()
+	if len(opened) != 2 {
+		t.Fatalf("got %d connections, expected 2", len(opened))
+	}
+	if groups := opened[0].joined(); !reflect.DeepEqual(groups, []uint32{6, 5}) {
+		t.Errorf("subscription joined groups %v, expected [6 5]", groups)
+	}
+	if groups := opened[1].joined(); len(groups) != 0 {
+		t.Errorf("frame connection joined groups %v, expected none", groups)
+	}
+}
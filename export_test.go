@@ -0,0 +1,167 @@
+package wifi
+
+import (
+	"time"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+)
+
+// Exported for use in wifi_test, grouped by the file defining them.
+
+// ap.go
+var ChannelSwitchAttrs = channelSwitchAttrs
+var StartAPAttrs = startAPAttrs
+var APError = apError
+var MacACLAttrs = macACLAttrs
+
+// bitrate.go
+func (m *RateMask) Attributes() ([]AttributeEncoder, error) { return m.attributes() }
+
+// bss.go
+var ParseBSS = parseBSS
+
+// cache.go
+func (c *Client) CacheInterface(w *WifiInterface, expires time.Time) {
+	if c.interfaceCache == nil {
+		c.interfaceCache = make(map[string]cachedInterface)
+	}
+	c.interfaceCache[w.Name] = cachedInterface{iface: *w, expires: expires}
+}
+
+func (c *Client) CacheConnectWiphy(phy uint32, w *Wiphy) {
+	if c.connectWiphys == nil {
+		c.connectWiphys = make(map[uint32]*Wiphy)
+	}
+	c.connectWiphys[phy] = w
+}
+
+// channel.go
+func (def *ChannelDefinition) Validate() error { return def.validate() }
+
+// client.go
+type SocketOptioner = socketOptioner
+
+func (opts Options) Apply(conn SocketOptioner) error { return opts.apply(conn) }
+
+// NewClientConn returns a Client sending its requests on conn.
+func NewClientConn(conn *genetlink.Conn, familyID uint16) *Client {
+	return &Client{c: conn, familyID: familyID}
+}
+
+// NewClientGroups returns a Client knowing the given nl80211 multicast
+// groups, opening its event connections with the function set by
+// SetDialConn.
+func NewClientGroups(groups map[string]uint32) *Client {
+	return &Client{groups: groups}
+}
+
+// SetDialConn makes the package open dial's connections in place of generic
+// netlink ones, returning a function restoring generic netlink.
+func SetDialConn(dial func(Options) (*genetlink.Conn, error)) func() {
+	old := dialConn
+	dialConn = dial
+	return func() { dialConn = old }
+}
+
+var DeauthAttrs = deauthAttrs
+var StationFlagsAttrs = stationFlagsAttrs
+var FilterInterfacesByPhy = filterInterfacesByPhy
+
+func (w *Wiphy) CheckMonitorFlags(flags MonitorFlags) error { return w.checkMonitorFlags(flags) }
+func InterfaceOptionAttrs(iftype InterfaceType, opts ...InterfaceOption) ([]AttributeEncoder, error) {
+	o := newInterfaceOptions(opts)
+	if err := o.validate(iftype); err != nil { return nil, err }
+	return o.attributes(), nil
+}
+func (c *Client) ParseGetInterfaceResponse(msgs []genetlink.Message) ([]*WifiInterface, error) { return c.parseGetInterfaceResponse(msgs) }
+func (c *Client) ParseGetPowerSaveResponse(msgs []genetlink.Message) (bool, error) { return c.parseGetPowerSaveResponse(msgs) }
+func (c *Client) ParseGetStationResponse(msgs []genetlink.Message) ([]*StationInfo, error) { return c.parseGetStationResponse(msgs) }
+func (c *Client) ParseGetScanResponse(msgs []genetlink.Message) ([]*BSS, error) { return c.parseGetScanResponse(msgs) }
+
+// connect.go
+var ConnectionAttrEncoder = connectionAttrEncoder
+var ConnectAttrs = connectAttrs
+var DerivePSK = derivePSK
+
+func (w *Wiphy) CheckConnectOptions(opts *ConnectOptions) error { return w.checkConnectOptions(opts) }
+func (c *Client) RoamOptions(w *WifiInterface, current *BSS) (*ConnectOptions, error) { return c.roamOptions(w, current) }
+
+// cqm.go
+var CQMRSSIAttribute = cqmRSSIAttribute
+var ValidateCQMThresholds = validateCQMThresholds
+
+// events.go
+var ParseEvent = parseEvent
+var ParseStationEvent = parseStationEvent
+var WaitForConnectResult = waitForConnect
+
+type EventReceiver = eventReceiver
+
+func (c *Client) EventConn(groups ...string) (*genetlink.Conn, error) { return c.eventConn(groups...) }
+
+// frame.go
+var ParseCookie = parseCookie
+
+func (opts FrameOptions) Attributes(freq int) []AttributeEncoder { return opts.attributes(freq) }
+
+// hop.go
+func (w *Wiphy) HopFrequencies(channels []int) ([]uint32, []error) { return w.hopFrequencies(channels) }
+
+// ibss.go
+var IBSSAttrs = ibssAttrs
+
+// ie.go
+var ParseIEs = parseIEs
+
+// link.go
+var IfInfoMsg = ifInfoMsg
+
+type RouteConn = routeConn
+
+// SetDialRoute makes the package open dial's connections in place of
+// rtnetlink ones, returning a function restoring rtnetlink.
+func SetDialRoute(dial func() (RouteConn, error)) func() {
+	old := dialRoute
+	dialRoute = dial
+	return func() { dialRoute = old }
+}
+
+// mesh.go
+var ParseGetMeshConfigResponse = parseGetMeshConfigResponse
+var JoinMeshAttrs = joinMeshAttrs
+var MeshPeerAttrs = meshPeerAttrs
+
+// ocb.go
+var OCBAttrs = ocbAttrs
+
+// regulatory.go
+var ValidAlpha2 = validAlpha2
+
+// scan.go
+var WaitForScan = waitForScan
+var NextScanRetryDelay = nextScanRetryDelay
+
+// station.go
+var ParseRateInfo = parseRateInfo
+
+func (info *StationInfo) ParseAttributes(attrs []netlink.Attribute) error { return info.parseAttributes(attrs) }
+
+// survey.go
+var ParseGetSurveyResponse = parseGetSurveyResponse
+
+// wiphy.go
+var ParseProtocolFeatures = parseProtocolFeatures
+var RetryLimitsAttrs = retryLimitsAttrs
+
+func (w *Wiphy) CheckChannel(channel int, freq uint32) error { return w.checkChannel(channel, freq) }
+func (w *Wiphy) CheckFrequency(freq uint32) error { return w.checkFrequency(freq) }
+func (w *Wiphy) CheckAntennas(tx, rx uint32) error { return w.checkAntennas(tx, rx) }
+
+// wiphy_parsers.go
+var ParseGetWiphyResponse = parseGetWiphyResponse
+
+// wowlan.go
+var ParseGetWoWLANResponse = parseGetWoWLANResponse
+
+func (cfg *WoWLANConfig) Attributes() ([]AttributeEncoder, error) { return cfg.attributes() }
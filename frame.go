@@ -0,0 +1,252 @@
+//go:build linux
+// +build linux
+
+package wifi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// Frame control values of the management frames most commonly registered
+// for with SubscribeFrames: the type and subtype bits of the first two bytes
+// of the frame, in little-endian order.
+const (
+	FrameTypeProbeRequest uint16 = 0x0040
+	FrameTypeAction       uint16 = 0x00d0
+)
+
+// minFrameLength is the length of the shortest 802.11 management frame
+// header: frame control, duration, three addresses and sequence control.
+const minFrameLength = 24
+
+// FrameOptions control how SendFrame transmits a frame.
+type FrameOptions struct {
+	// NoCCK forbids transmitting the frame at 802.11b CCK rates, as
+	// required for frames sent to P2P devices.
+	NoCCK bool
+
+	// DontWaitForAck skips waiting for the peer's acknowledgement. The
+	// kernel then reports no transmit status, and SendFrame returns a
+	// cookie of 0.
+	DontWaitForAck bool
+
+	// Duration is how long to stay on freq when it differs from the
+	// current channel, for instance to wait for a response. It is rounded
+	// down to a millisecond; 0 leaves the duration to the driver.
+	Duration time.Duration
+}
+
+// SendFrame transmits a raw 802.11 management frame on the given interface
+// on the frequency freq in MHz, or on the current channel if freq is 0. The
+// frame must be complete, starting with its frame control field, and the
+// driver must allow the interface to transmit frames of its type. The
+// returned cookie identifies the frame in the FrameTxStatusEvent the kernel
+// sends on the "mlme" multicast group once the frame has been transmitted.
+func (c *Client) SendFrame(w *WifiInterface, freq int, frame []byte, opts FrameOptions) (uint64, error) {
+	if len(frame) < minFrameLength {
+		return 0, fmt.Errorf("SendFrame: frame of %d bytes is too short", len(frame))
+	}
+
+	attrs := append([]AttributeEncoder{
+		InterfaceIndexAttribute(w.Index),
+		FrameAttribute(frame),
+	}, opts.attributes(freq)...)
+	msg, err := NewNl80211Message(unix.NL80211_CMD_FRAME, attrs)
+	if err != nil { return 0, fmt.Errorf("SendFrame: %v", err) }
+
+	// Without waiting for an ACK there is no cookie, and so no reply
+	// beyond the acknowledgement of the request itself.
+	flags := netlink.Request
+	if opts.DontWaitForAck {
+		flags |= netlink.Acknowledge
+	}
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: flags,
+	}
+	response, err := request.Response(c)
+	if err != nil { return 0, fmt.Errorf("SendFrame: %w", err) }
+	if opts.DontWaitForAck { return 0, nil }
+
+	cookie, err := parseCookie(response)
+	if err != nil { return 0, fmt.Errorf("SendFrame: %v", err) }
+	return cookie, nil
+}
+
+// attributes returns the attributes requesting the options for a frame
+// sent on freq.
+func (opts FrameOptions) attributes(freq int) []AttributeEncoder {
+	var attrs []AttributeEncoder
+	if freq != 0 {
+		attrs = append(attrs, WiphyFrequencyAttribute(uint32(freq)))
+	}
+	if opts.NoCCK {
+		attrs = append(attrs, NewAttributeFactory[bool](unix.NL80211_ATTR_TX_NO_CCK_RATE)(true))
+	}
+	if opts.DontWaitForAck {
+		attrs = append(attrs, NewAttributeFactory[bool](unix.NL80211_ATTR_DONT_WAIT_FOR_ACK)(true))
+	}
+	if opts.Duration > 0 {
+		attrs = append(attrs,
+			NewAttributeFactory[bool](unix.NL80211_ATTR_OFFCHANNEL_TX_OK)(true),
+			NewAttributeFactory[uint32](unix.NL80211_ATTR_DURATION)(uint32(opts.Duration/time.Millisecond)),
+		)
+	}
+	return attrs
+}
+
+// A FrameTxStatusEvent reports the outcome of transmitting a frame with
+// SendFrame.
+type FrameTxStatusEvent struct {
+	InterfaceIndex uint32
+	WiphyIndex     uint32
+
+	// Cookie is the cookie SendFrame returned for the frame.
+	Cookie uint64
+
+	// Acked is set when the peer acknowledged the frame.
+	Acked bool
+
+	// Frame is the frame that was transmitted.
+	Frame []byte
+}
+
+// parseFrameTxStatusEvent parses a NL80211_CMD_FRAME_TX_STATUS notification.
+func parseFrameTxStatusEvent(m genetlink.Message) (*FrameTxStatusEvent, error) {
+	attrs, err := netlink.UnmarshalAttributes(m.Data)
+	if err != nil { return nil, fmt.Errorf("parseFrameTxStatusEvent: %v", err) }
+
+	ev := &FrameTxStatusEvent{}
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_ATTR_IFINDEX:
+			ev.InterfaceIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_WIPHY:
+			ev.WiphyIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_COOKIE:
+			ev.Cookie = nlenc.Uint64(a.Data)
+		case unix.NL80211_ATTR_ACK:
+			ev.Acked = true
+		case unix.NL80211_ATTR_FRAME:
+			ev.Frame = a.Data
+		}
+	}
+	return ev, nil
+}
+
+// parseCookie returns the NL80211_ATTR_COOKIE of a response.
+func parseCookie(msgs []genetlink.Message) (uint64, error) {
+	for _, m := range msgs {
+		attrs, err := netlink.UnmarshalAttributes(m.Data)
+		if err != nil { return 0, fmt.Errorf("parseCookie: %v", err) }
+
+		for _, a := range attrs {
+			if a.Type == unix.NL80211_ATTR_COOKIE {
+				return nlenc.Uint64(a.Data), nil
+			}
+		}
+	}
+	return 0, errors.New("parseCookie: no cookie in response")
+}
+
+// A FrameRegistration selects management frames to be received with
+// SubscribeFrames.
+type FrameRegistration struct {
+	// Type is the frame control value of the frames, such as
+	// FrameTypeProbeRequest.
+	Type uint16
+
+	// Match, if set, restricts the registration to frames whose body
+	// starts with these bytes, such as the category of action frames.
+	Match []byte
+}
+
+// A FrameEvent is a management frame received on an interface after
+// registering for it with SubscribeFrames.
+type FrameEvent struct {
+	InterfaceIndex uint32
+	WiphyIndex     uint32
+
+	// Frequency is the frequency the frame was received on, in MHz.
+	Frequency uint32
+
+	// Signal is the signal strength of the frame in dBm, or 0 if the
+	// driver doesn't report it.
+	Signal int
+
+	// Frame is the complete frame, starting with its frame control field.
+	Frame []byte
+}
+
+// Source returns the transmitter address of the frame, or nil if the frame
+// is too short to hold one.
+func (ev *FrameEvent) Source() net.HardwareAddr {
+	if len(ev.Frame) < minFrameLength { return nil }
+	return net.HardwareAddr(ev.Frame[10:16])
+}
+
+// SubscribeFrames registers to receive the management frames matching regs
+// on the given interface, such as probe requests, without the need for a
+// monitor interface. The frames are delivered as FrameEvents on the
+// returned channel, which is closed once ctx is done or the Client is
+// closed; the registrations end along with it. Registration fails if
+// another process already registered for the same frames.
+func (c *Client) SubscribeFrames(ctx context.Context, w *WifiInterface, regs ...FrameRegistration) (<-chan Event, error) {
+	if len(regs) == 0 { return nil, errors.New("SubscribeFrames: no frame registrations") }
+
+	// Registrations belong to the socket that made them, and the kernel
+	// sends matching frames to that socket only, so they are made on the
+	// connection the events are received on.
+	conn, err := c.eventConn()
+	if err != nil { return nil, fmt.Errorf("SubscribeFrames: %v", err) }
+
+	for _, reg := range regs {
+		attrs := []AttributeEncoder{
+			InterfaceIndexAttribute(w.Index),
+			FrameTypeAttribute(reg.Type),
+			FrameMatchAttribute(reg.Match),
+		}
+		msg, err := NewNl80211Message(unix.NL80211_CMD_REGISTER_FRAME, attrs)
+		if err == nil {
+			_, err = conn.Execute(*msg, c.familyID, netlink.Request|netlink.Acknowledge)
+		}
+		if err != nil {
+			c.closeEventConn(conn)
+			return nil, fmt.Errorf("SubscribeFrames: failed to register frame type %#04x: %w", reg.Type, err)
+		}
+	}
+	return c.deliverEvents(ctx, conn), nil
+}
+
+// parseFrameEvent parses a NL80211_CMD_FRAME notification.
+func parseFrameEvent(m genetlink.Message) (*FrameEvent, error) {
+	attrs, err := netlink.UnmarshalAttributes(m.Data)
+	if err != nil { return nil, fmt.Errorf("parseFrameEvent: %v", err) }
+
+	ev := &FrameEvent{}
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_ATTR_IFINDEX:
+			ev.InterfaceIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_WIPHY:
+			ev.WiphyIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_WIPHY_FREQ:
+			ev.Frequency = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_RX_SIGNAL_DBM:
+			ev.Signal = int(int32(nlenc.Uint32(a.Data)))
+		case unix.NL80211_ATTR_FRAME:
+			ev.Frame = a.Data
+		}
+	}
+	if ev.Frame == nil { return nil, errors.New("parseFrameEvent: no frame in notification") }
+	return ev, nil
+}
@@ -0,0 +1,111 @@
+package wifi_test
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/bryancoxwell/wifi"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// TestParseCookie tests finding the cookie in a NL80211_CMD_FRAME response.
+func TestParseCookie(t *testing.T) {
+	msgs := []genetlink.Message{{
+		Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_ATTR_IFINDEX, Data: nlenc.Uint32Bytes(3)},
+			{Type: unix.NL80211_ATTR_COOKIE, Data: nlenc.Uint64Bytes(0x1122334455)},
+		}),
+	}}
+	cookie, err := wifi.ParseCookie(msgs)
+	if err != nil {
+		t.Fatalf("failed to parse cookie: %v", err)
+	}
+	if cookie != 0x1122334455 {
+		t.Errorf("got cookie %#x, expected %#x", cookie, 0x1122334455)
+	}
+
+	if _, err := wifi.ParseCookie(nil); err == nil {
+		t.Error("expected an error for a response without a cookie")
+	}
+}
+
+// TestParseFrameEvent tests the parsing of a received probe request.
+func TestParseFrameEvent(t *testing.T) {
+	src := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x01, 0x00}
+	frame := make([]byte, 24)
+	frame[0] = 0x40
+	copy(frame[4:10], net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	copy(frame[10:16], src)
+
+	m := genetlink.Message{
+		Header: genetlink.Header{Command: unix.NL80211_CMD_FRAME},
+		Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_ATTR_IFINDEX, Data: nlenc.Uint32Bytes(3)},
+			{Type: unix.NL80211_ATTR_WIPHY_FREQ, Data: nlenc.Uint32Bytes(2437)},
+			{Type: unix.NL80211_ATTR_RX_SIGNAL_DBM, Data: nlenc.Int32Bytes(-64)},
+			{Type: unix.NL80211_ATTR_FRAME, Data: frame},
+		}),
+	}
+	ev, ok := wifi.ParseEvent(m)
+	if !ok {
+		t.Fatalf("ParseEvent: event not recognized")
+	}
+	fe, ok := ev.(wifi.FrameEvent)
+	if !ok {
+		t.Fatalf("ParseEvent: unexpected event type %T", ev)
+	}
+	if fe.InterfaceIndex != 3 || fe.Frequency != 2437 || fe.Signal != -64 || !bytes.Equal(fe.Frame, frame) {
+		t.Errorf("unexpected event: %+v", fe)
+	}
+	if got := fe.Source(); !bytes.Equal(got, src) {
+		t.Errorf("Source() = %v, expected %v", got, src)
+	}
+}
+
+// TestFrameOptionsAttributes tests the encoding of the options of a
+// transmitted frame.
+func TestFrameOptionsAttributes(t *testing.T) {
+	if attrs := (wifi.FrameOptions{}).Attributes(0); len(attrs) != 0 {
+		t.Errorf("unexpected attributes for default options: %d", len(attrs))
+	}
+
+	opts := wifi.FrameOptions{NoCCK: true, DontWaitForAck: true, Duration: 200 * time.Millisecond}
+	attrs := encodeAttributes(t, opts.Attributes(5180))
+	if got := nlenc.Uint32(attrs[unix.NL80211_ATTR_WIPHY_FREQ]); got != 5180 {
+		t.Errorf("unexpected frequency: %d", got)
+	}
+	if got := nlenc.Uint32(attrs[unix.NL80211_ATTR_DURATION]); got != 200 {
+		t.Errorf("unexpected duration: %d", got)
+	}
+	for _, typ := range []uint16{unix.NL80211_ATTR_TX_NO_CCK_RATE, unix.NL80211_ATTR_DONT_WAIT_FOR_ACK, unix.NL80211_ATTR_OFFCHANNEL_TX_OK} {
+		if _, ok := attrs[typ]; !ok {
+			t.Errorf("missing flag attribute %d", typ)
+		}
+	}
+}
+
+// TestParseFrameTxStatusEvent tests the parsing of the transmit status of a
+// frame.
+func TestParseFrameTxStatusEvent(t *testing.T) {
+	m := genetlink.Message{
+		Header: genetlink.Header{Command: unix.NL80211_CMD_FRAME_TX_STATUS},
+		Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_ATTR_IFINDEX, Data: nlenc.Uint32Bytes(3)},
+			{Type: unix.NL80211_ATTR_COOKIE, Data: nlenc.Uint64Bytes(42)},
+			{Type: unix.NL80211_ATTR_ACK},
+		}),
+	}
+	ev, ok := wifi.ParseEvent(m)
+	if !ok {
+		t.Fatalf("ParseEvent: event not recognized")
+	}
+	expected := wifi.FrameTxStatusEvent{InterfaceIndex: 3, Cookie: 42, Acked: true}
+	if got, ok := ev.(wifi.FrameTxStatusEvent); !ok || got.InterfaceIndex != 3 || got.Cookie != 42 || !got.Acked {
+		t.Errorf("ParseEvent mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", expected, ev)
+	}
+}
@@ -0,0 +1,144 @@
+package wifi
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// An HEMCSMap is an HE-MCS map as carried in the HE Capabilities and
+// HE Operation elements. It holds 2 bits per spatial stream, for up
+// to 8 spatial streams.
+type HEMCSMap uint16
+
+// MaxMCS returns the highest HE MCS index supported for the given number
+// of spatial streams (1-8), or -1 if that number of streams is not supported.
+func (m HEMCSMap) MaxMCS(nss int) int {
+	if nss < 1 || nss > 8 { return -1 }
+
+	switch (m >> (2 * (nss - 1))) & 0x3 {
+	case 0:
+		return 7
+	case 1:
+		return 9
+	case 2:
+		return 11
+	default:
+		return -1
+	}
+}
+
+// HECapabilities describes the contents of an HE Capabilities element
+// (extension ID 35).
+type HECapabilities struct {
+	// MACCapabilities and PHYCapabilities are the raw HE MAC and
+	// HE PHY Capabilities Information fields.
+	MACCapabilities [6]byte
+	PHYCapabilities [11]byte
+
+	Supports40MHzIn2GHz bool
+	Supports40And80MHz  bool
+	Supports160MHz      bool
+	Supports80Plus80MHz bool
+
+	// RxMCSMap and TxMCSMap are the supported HE-MCS maps for
+	// channel widths up to 80 MHz.
+	RxMCSMap HEMCSMap
+	TxMCSMap HEMCSMap
+}
+
+// HEOperation describes the contents of an HE Operation element
+// (extension ID 36).
+type HEOperation struct {
+	BSSColor         int
+	BSSColorDisabled bool
+
+	// BasicMCS is the HE-MCS map every station in the BSS must support.
+	BasicMCS HEMCSMap
+
+	// VHTOperation holds the VHT Operation Information subfield when present.
+	// Its BasicMCS is always zero.
+	VHTOperation *VHTOperation
+
+	// SixGHz holds the 6 GHz Operation Information subfield of a BSS
+	// operating in the 6 GHz band, or nil.
+	SixGHz *HE6GHzOperation
+}
+
+// HE6GHzOperation describes the 6 GHz Operation Information subfield of an
+// HE Operation element.
+type HE6GHzOperation struct {
+	PrimaryChannel int
+
+	// Width is the channel width in MHz. 80+80 MHz channels report 160.
+	Width int
+
+	CenterFrequencySegment0 int
+	CenterFrequencySegment1 int
+	DuplicateBeacon         bool
+
+	// MinimumRate is the minimum rate in Mbps that non-AP stations may
+	// transmit at.
+	MinimumRate int
+}
+
+// parseHECapabilities parses the body of an HE Capabilities element, not
+// including the extension ID byte.
+func parseHECapabilities(b []byte) (*HECapabilities, error) {
+	if len(b) < 21 { return nil, fmt.Errorf("parseHECapabilities: %v", errInvalidIE) }
+
+	he := &HECapabilities{
+		RxMCSMap: HEMCSMap(binary.LittleEndian.Uint16(b[17:19])),
+		TxMCSMap: HEMCSMap(binary.LittleEndian.Uint16(b[19:21])),
+	}
+	copy(he.MACCapabilities[:], b[0:6])
+	copy(he.PHYCapabilities[:], b[6:17])
+
+	width := he.PHYCapabilities[0]
+	he.Supports40MHzIn2GHz = width&(1<<1) != 0
+	he.Supports40And80MHz = width&(1<<2) != 0
+	he.Supports160MHz = width&(1<<3) != 0
+	he.Supports80Plus80MHz = width&(1<<4) != 0
+	return he, nil
+}
+
+// parseHEOperation parses the body of an HE Operation element, not
+// including the extension ID byte. The optional subfields are only
+// decoded when the element is long enough to hold them.
+func parseHEOperation(b []byte) (*HEOperation, error) {
+	if len(b) < 6 { return nil, fmt.Errorf("parseHEOperation: %v", errInvalidIE) }
+
+	params := uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+	he := &HEOperation{
+		BSSColor:         int(b[3] & 0x3f),
+		BSSColorDisabled: b[3]&(1<<7) != 0,
+		BasicMCS:         HEMCSMap(binary.LittleEndian.Uint16(b[4:6])),
+	}
+
+	b = b[6:]
+	if params&(1<<14) != 0 {
+		if len(b) < 3 { return nil, fmt.Errorf("parseHEOperation: %v", errInvalidIE) }
+		he.VHTOperation = &VHTOperation{
+			ChannelWidth:            int(b[0]),
+			CenterFrequencySegment0: int(b[1]),
+			CenterFrequencySegment1: int(b[2]),
+		}
+		b = b[3:]
+	}
+	if params&(1<<15) != 0 {
+		// Max Co-Hosted BSSID Indicator.
+		if len(b) < 1 { return nil, fmt.Errorf("parseHEOperation: %v", errInvalidIE) }
+		b = b[1:]
+	}
+	if params&(1<<17) != 0 {
+		if len(b) < 5 { return nil, fmt.Errorf("parseHEOperation: %v", errInvalidIE) }
+		he.SixGHz = &HE6GHzOperation{
+			PrimaryChannel:          int(b[0]),
+			Width:                   20 << (b[1] & 0x3),
+			DuplicateBeacon:         b[1]&(1<<2) != 0,
+			CenterFrequencySegment0: int(b[2]),
+			CenterFrequencySegment1: int(b[3]),
+			MinimumRate:             int(b[4]),
+		}
+	}
+	return he, nil
+}
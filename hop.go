@@ -0,0 +1,73 @@
+//go:build linux
+// +build linux
+
+package wifi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// HopChannels cycles the given interface, typically a monitor interface,
+// through the given channels, numbered as for SetChannel, staying dwell on
+// each, until ctx is done, when it returns nil. Channels the device or
+// regulatory domain don't allow are skipped with a logged warning when the
+// bands of the wiphy can be read; if none are left, or setting a channel
+// fails, HopChannels returns an error.
+func (c *Client) HopChannels(ctx context.Context, w *WifiInterface, channels []int, dwell time.Duration) error {
+	if dwell <= 0 { return fmt.Errorf("HopChannels: invalid dwell time %v", dwell) }
+
+	// Without the bands of the wiphy, every valid channel is hopped to and
+	// the kernel rejects those the device can't use.
+	wiphy, err := c.Wiphy(w)
+	if err != nil { wiphy = nil }
+
+	freqs, skipped := wiphy.hopFrequencies(channels)
+	for _, err := range skipped {
+		log.Printf("wifi: HopChannels: skipping %v", err)
+	}
+	if len(freqs) == 0 { return errors.New("HopChannels: no usable channels") }
+
+	timer := time.NewTimer(dwell)
+	defer timer.Stop()
+	for {
+		for _, freq := range freqs {
+			if err := c.setWiphy(w, []AttributeEncoder{WiphyFrequencyAttribute(freq)}); err != nil {
+				return fmt.Errorf("HopChannels: failed to set %d MHz: %w", freq, err)
+			}
+
+			timer.Reset(dwell)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// hopFrequencies returns the frequencies of the channels the device can
+// hop to, in order, along with an error for each channel it can't. A nil
+// wiphy, or one whose bands are unknown, only rejects invalid channels.
+func (w *Wiphy) hopFrequencies(channels []int) ([]uint32, []error) {
+	var freqs []uint32
+	var skipped []error
+	for _, ch := range channels {
+		freq, err := channelFrequency(ch)
+		if err != nil {
+			skipped = append(skipped, fmt.Errorf("invalid channel %d", ch))
+			continue
+		}
+		if w != nil && len(w.Bands) > 0 {
+			if err := w.checkChannel(ch, freq); err != nil {
+				skipped = append(skipped, err)
+				continue
+			}
+		}
+		freqs = append(freqs, freq)
+	}
+	return freqs, skipped
+}
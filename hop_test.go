@@ -0,0 +1,48 @@
+package wifi_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bryancoxwell/wifi"
+)
+
+// TestWiphyHopFrequencies tests that channels a device can't use are left
+// out of a channel hop.
+func TestWiphyHopFrequencies(t *testing.T) {
+	wiphy := &wifi.Wiphy{
+		Bands: []wifi.Band{{
+			Type: wifi.Band2GHz,
+			Frequencies: []wifi.BandFrequency{
+				{Frequency: 2412},
+				{Frequency: 2437},
+				{Frequency: 2462},
+				{Frequency: 2484, Disabled: true},
+			},
+		}},
+	}
+
+	freqs, skipped := wiphy.HopFrequencies([]int{1, 6, 14, 36, 11, 0})
+	expected := []uint32{2412, 2437, 2462}
+	if !reflect.DeepEqual(expected, freqs) {
+		t.Errorf("got frequencies %v, expected %v", freqs, expected)
+	}
+	if len(skipped) != 3 {
+		t.Errorf("got %d skipped channels, expected 3: %v", len(skipped), skipped)
+	}
+}
+
+// TestWiphyHopFrequenciesUnknownBands tests that a wiphy whose bands are
+// unknown, as after a non-split dump, only skips invalid channels.
+func TestWiphyHopFrequenciesUnknownBands(t *testing.T) {
+	for _, wiphy := range []*wifi.Wiphy{{}, nil} {
+		freqs, skipped := wiphy.HopFrequencies([]int{1, 6, 36, 0})
+		expected := []uint32{2412, 2437, 5180}
+		if !reflect.DeepEqual(expected, freqs) {
+			t.Errorf("got frequencies %v, expected %v", freqs, expected)
+		}
+		if len(skipped) != 1 {
+			t.Errorf("got %d skipped channels, expected 1: %v", len(skipped), skipped)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package wifi
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// HTCapabilities describes the contents of an HT Capabilities element (IE 45).
+type HTCapabilities struct {
+	// Info is the raw HT Capability Information field.
+	Info uint16
+
+	// ChannelWidth40 is set when the station supports both 20 and 40 MHz
+	// operation rather than 20 MHz only.
+	ChannelWidth40 bool
+	ShortGI20      bool
+	ShortGI40      bool
+
+	// MaxAMPDULength is the maximum A-MPDU length the station can receive,
+	// in bytes.
+	MaxAMPDULength int
+
+	// RxMCS lists the MCS indices the station can receive.
+	RxMCS []int
+
+	// RxHighestRate is the highest supported data rate in Mbps, or 0 if
+	// unspecified.
+	RxHighestRate int
+}
+
+// A SecondaryChannelOffset indicates the position of the secondary 20 MHz
+// channel of an HT40 BSS relative to its primary channel.
+type SecondaryChannelOffset int
+
+const (
+	SecondaryChannelNone  SecondaryChannelOffset = 0
+	SecondaryChannelAbove SecondaryChannelOffset = 1
+	SecondaryChannelBelow SecondaryChannelOffset = 3
+)
+
+// String returns the string representation of a SecondaryChannelOffset.
+func (o SecondaryChannelOffset) String() string {
+	switch o {
+	case SecondaryChannelNone:
+		return "none"
+	case SecondaryChannelAbove:
+		return "above"
+	case SecondaryChannelBelow:
+		return "below"
+	default:
+		return fmt.Sprintf("unknown(%d)", o)
+	}
+}
+
+// HTOperation describes the contents of an HT Operation element (IE 61).
+type HTOperation struct {
+	PrimaryChannel         int
+	SecondaryChannelOffset SecondaryChannelOffset
+
+	// AnyChannelWidth is set when the BSS allows use of any channel width
+	// in its supported channel width set, i.e. 40 MHz operation.
+	AnyChannelWidth bool
+
+	// CenterFrequencySegment2 is the channel number of the second VHT
+	// center frequency segment, used by some 160 and 80+80 MHz BSSs.
+	CenterFrequencySegment2 int
+
+	// BasicMCS lists the MCS indices every station in the BSS must support.
+	BasicMCS []int
+}
+
+// parseHTCapabilities parses the body of an HT Capabilities element.
+func parseHTCapabilities(b []byte) (*HTCapabilities, error) {
+	if len(b) < 26 { return nil, fmt.Errorf("parseHTCapabilities: %v", errInvalidIE) }
+
+	info := binary.LittleEndian.Uint16(b[0:2])
+	// Bits 10-12 of the Rx Highest Supported Data Rate subfield are reserved.
+	highest := binary.LittleEndian.Uint16(b[13:15]) & 0x3ff
+
+	return &HTCapabilities{
+		Info:           info,
+		ChannelWidth40: info&(1<<1) != 0,
+		ShortGI20:      info&(1<<5) != 0,
+		ShortGI40:      info&(1<<6) != 0,
+		MaxAMPDULength: (1 << (13 + int(b[2]&0x3))) - 1,
+		RxMCS:          parseMCSBitmask(b[3:13]),
+		RxHighestRate:  int(highest),
+	}, nil
+}
+
+// parseHTOperation parses the body of an HT Operation element.
+func parseHTOperation(b []byte) (*HTOperation, error) {
+	if len(b) < 22 { return nil, fmt.Errorf("parseHTOperation: %v", errInvalidIE) }
+
+	return &HTOperation{
+		PrimaryChannel:          int(b[0]),
+		SecondaryChannelOffset:  SecondaryChannelOffset(b[1] & 0x3),
+		AnyChannelWidth:         b[1]&(1<<2) != 0,
+		CenterFrequencySegment2: int(binary.LittleEndian.Uint16(b[2:4])>>5) & 0xff,
+		BasicMCS:                parseMCSBitmask(b[6:16]),
+	}, nil
+}
+
+// parseMCSBitmask returns the MCS indices set in the 77-bit MCS
+// bitmask shared by the HT Capabilities and HT Operation elements.
+func parseMCSBitmask(b []byte) []int {
+	var mcs []int
+	for i := 0; i < 77; i++ {
+		if b[i/8]&(1<<(i%8)) != 0 {
+			mcs = append(mcs, i)
+		}
+	}
+	return mcs
+}
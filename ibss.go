@@ -0,0 +1,115 @@
+//go:build linux
+// +build linux
+
+package wifi
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/mdlayher/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// IBSSOptions are the optional parameters of an IBSS joined with JoinIBSS.
+type IBSSOptions struct {
+	// BSSID, when set, is the only BSSID the interface joins or creates
+	// the IBSS with.
+	BSSID net.HardwareAddr
+
+	// BeaconInterval is the time between beacons in TUs of 1024 µs, used
+	// when the IBSS is created. It defaults to the kernel's choice.
+	BeaconInterval int
+
+	// BasicRates are the rates in Mbps every member must support, used
+	// when the IBSS is created.
+	BasicRates []float64
+
+	// FixedFrequency keeps the interface on the given frequency rather
+	// than following an IBSS with the same SSID found on another one.
+	FixedFrequency bool
+
+	// ChannelWidth is the width of the channel of an HT IBSS, with
+	// CenterFrequency1 the center of a 40 MHz channel. The default is a
+	// 20 MHz channel without HT.
+	ChannelWidth     ChannelWidth
+	CenterFrequency1 uint32
+}
+
+// JoinIBSS joins the ad-hoc network with the given SSID on the frequency
+// freq in MHz with the given ad-hoc interface, creating it if no member
+// answers. The options are optional; without them the kernel picks the
+// parameters of a new IBSS.
+func (c *Client) JoinIBSS(w *WifiInterface, ssid string, freq int, opts ...IBSSOptions) error {
+	var o IBSSOptions
+	switch len(opts) {
+	case 0:
+	case 1:
+		o = opts[0]
+	default:
+		return errors.New("JoinIBSS: more than one set of options given")
+	}
+
+	attrs, err := ibssAttrs(w, ssid, freq, &o)
+	if err != nil { return fmt.Errorf("JoinIBSS: %v", err) }
+
+	msg, err := NewNl80211Message(unix.NL80211_CMD_JOIN_IBSS, attrs)
+	if err != nil { return fmt.Errorf("JoinIBSS: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("JoinIBSS: %w", err) }
+	return nil
+}
+
+// LeaveIBSS leaves the ad-hoc network joined by the given interface.
+func (c *Client) LeaveIBSS(w *WifiInterface) error {
+	attrs := []AttributeEncoder{InterfaceIndexAttribute(w.Index)}
+	msg, err := NewNl80211Message(unix.NL80211_CMD_LEAVE_IBSS, attrs)
+	if err != nil { return fmt.Errorf("LeaveIBSS: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("LeaveIBSS: %w", err) }
+	return nil
+}
+
+// ibssAttrs returns the NL80211_CMD_JOIN_IBSS attributes joining ssid on
+// freq with opts.
+func ibssAttrs(w *WifiInterface, ssid string, freq int, opts *IBSSOptions) ([]AttributeEncoder, error) {
+	if w.Type != InterfaceTypeAdHoc { return nil, fmt.Errorf("%s is a %v interface, not ad-hoc", w.Name, w.Type) }
+	if len(ssid) == 0 || len(ssid) > 32 { return nil, fmt.Errorf("invalid SSID %q", ssid) }
+	if opts.BSSID != nil && len(opts.BSSID) != 6 { return nil, fmt.Errorf("invalid BSSID: %v", opts.BSSID) }
+	if opts.BeaconInterval < 0 || opts.BeaconInterval > 0xffff { return nil, fmt.Errorf("invalid beacon interval %d", opts.BeaconInterval) }
+
+	def := ChannelDefinition{Frequency: uint32(freq), Width: opts.ChannelWidth, CenterFrequency1: opts.CenterFrequency1}
+	if err := def.validate(); err != nil { return nil, err }
+
+	attrs := []AttributeEncoder{
+		InterfaceIndexAttribute(w.Index),
+		SSIDAttribute([]byte(ssid)),
+	}
+	attrs = append(attrs, channelWidthEncoder(&def)...)
+	if opts.BSSID != nil {
+		attrs = append(attrs, MacAttribute(opts.BSSID))
+	}
+	if opts.BeaconInterval != 0 {
+		attrs = append(attrs, NewAttributeFactory[uint32](unix.NL80211_ATTR_BEACON_INTERVAL)(uint32(opts.BeaconInterval)))
+	}
+	if len(opts.BasicRates) != 0 {
+		// NL80211_ATTR_BSS_BASIC_RATES lists rates in units of 500 kbps,
+		// without the basic rate flag of the Supported Rates element.
+		attrs = append(attrs, NewAttributeFactory[[]byte](unix.NL80211_ATTR_BSS_BASIC_RATES)(encodeRates(opts.BasicRates, nil)))
+	}
+	if opts.FixedFrequency {
+		attrs = append(attrs, NewAttributeFactory[bool](unix.NL80211_ATTR_FREQ_FIXED)(true))
+	}
+	return attrs, nil
+}
@@ -0,0 +1,87 @@
+package wifi_test
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/bryancoxwell/wifi"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// TestIBSSAttrs tests the attributes of a request joining an IBSS.
+func TestIBSSAttrs(t *testing.T) {
+	w := &wifi.WifiInterface{Index: 3, Name: "wlan0", Type: wifi.InterfaceTypeAdHoc}
+	bssid := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x01, 0x00}
+	opts := &wifi.IBSSOptions{
+		BSSID:            bssid,
+		BeaconInterval:   200,
+		BasicRates:       []float64{1, 2, 5.5, 11},
+		FixedFrequency:   true,
+		ChannelWidth:     wifi.ChannelWidth40,
+		CenterFrequency1: 2432,
+	}
+	encoders, err := wifi.IBSSAttrs(w, "sensors", 2422, opts)
+	if err != nil {
+		t.Fatalf("IBSSAttrs: %v", err)
+	}
+	attrs := encodeAttributes(t, encoders)
+	if got := string(attrs[unix.NL80211_ATTR_SSID]); got != "sensors" {
+		t.Errorf("unexpected SSID %q", got)
+	}
+	if got := nlenc.Uint32(attrs[unix.NL80211_ATTR_WIPHY_FREQ]); got != 2422 {
+		t.Errorf("unexpected frequency %d", got)
+	}
+	if got := nlenc.Uint32(attrs[unix.NL80211_ATTR_CENTER_FREQ1]); got != 2432 {
+		t.Errorf("unexpected center frequency %d", got)
+	}
+	if got := attrs[unix.NL80211_ATTR_MAC]; !bytes.Equal(got, bssid) {
+		t.Errorf("unexpected BSSID %v", net.HardwareAddr(got))
+	}
+	if got := nlenc.Uint32(attrs[unix.NL80211_ATTR_BEACON_INTERVAL]); got != 200 {
+		t.Errorf("unexpected beacon interval %d", got)
+	}
+	if got := attrs[unix.NL80211_ATTR_BSS_BASIC_RATES]; !bytes.Equal(got, []byte{2, 4, 11, 22}) {
+		t.Errorf("unexpected basic rates %v", got)
+	}
+	if _, ok := attrs[unix.NL80211_ATTR_FREQ_FIXED]; !ok {
+		t.Error("missing NL80211_ATTR_FREQ_FIXED")
+	}
+
+	encoders, err = wifi.IBSSAttrs(w, "sensors", 2412, &wifi.IBSSOptions{})
+	if err != nil {
+		t.Fatalf("IBSSAttrs: %v", err)
+	}
+	attrs = encodeAttributes(t, encoders)
+	for _, typ := range []uint16{unix.NL80211_ATTR_MAC, unix.NL80211_ATTR_BEACON_INTERVAL, unix.NL80211_ATTR_BSS_BASIC_RATES, unix.NL80211_ATTR_FREQ_FIXED} {
+		if _, ok := attrs[typ]; ok {
+			t.Errorf("unexpected attribute %d for default options", typ)
+		}
+	}
+
+	if _, err := wifi.IBSSAttrs(w, "", 2412, &wifi.IBSSOptions{}); err == nil {
+		t.Error("expected an error for an empty SSID")
+	}
+	if _, err := wifi.IBSSAttrs(w, "sensors", 2412, &wifi.IBSSOptions{ChannelWidth: wifi.ChannelWidth40, CenterFrequency1: 2500}); err == nil {
+		t.Error("expected an error for an invalid channel")
+	}
+	station := &wifi.WifiInterface{Index: 3, Name: "wlan0", Type: wifi.InterfaceTypeStation}
+	if _, err := wifi.IBSSAttrs(station, "sensors", 2412, &wifi.IBSSOptions{}); err == nil {
+		t.Error("expected an error for a station interface")
+	}
+}
+
+// TestJoinIBSSOptionsOptional tests that JoinIBSS takes at most one set of
+// options, checking its arguments before sending anything.
+func TestJoinIBSSOptionsOptional(t *testing.T) {
+	w := &wifi.WifiInterface{Index: 3, Name: "wlan0", Type: wifi.InterfaceTypeStation}
+	if err := (&wifi.Client{}).JoinIBSS(w, "sensors", 2412); err == nil {
+		t.Error("expected an error for a station interface")
+	}
+
+	w.Type = wifi.InterfaceTypeAdHoc
+	if err := (&wifi.Client{}).JoinIBSS(w, "sensors", 2412, wifi.IBSSOptions{}, wifi.IBSSOptions{}); err == nil {
+		t.Error("expected an error for two sets of options")
+	}
+}
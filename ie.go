@@ -0,0 +1,294 @@
+package wifi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Information element IDs used by the parsers in this package.
+const (
+	ieSSID            = 0
+	ieSupportedRates  = 1
+	ieDSParameterSet  = 3
+	ieCountry         = 7
+	ieBSSLoad         = 11
+	ieERP             = 42
+	ieHTCapabilities  = 45
+	ieExtendedRates   = 50
+	ieHTOperation     = 61
+	ieVHTCapabilities = 191
+	ieVHTOperation    = 192
+	ieVendor          = 221
+	ieExtension       = 255
+)
+
+// Element ID extensions carried in the first byte of an extension element.
+const (
+	ieExtHECapabilities = 35
+	ieExtHEOperation    = 36
+)
+
+var errInvalidIE = errors.New("invalid 802.11 information element")
+
+// An IE is a raw 802.11 information element, as carried in beacons
+// and probe responses.
+type IE struct {
+	ID uint8
+
+	// ExtensionID is the Element ID Extension of an extension element
+	// (ID 255). Data does not include the extension ID byte.
+	ExtensionID uint8
+
+	Data []byte
+}
+
+// parseIEs parses a byte slice of information elements into a list of IEs.
+func parseIEs(b []byte) ([]IE, error) {
+	var ies []IE
+	for len(b) > 0 {
+		if len(b) < 2 { return nil, errInvalidIE }
+		id := b[0]
+		l := int(b[1])
+		if len(b[2:]) < l { return nil, errInvalidIE }
+
+		ie := IE{
+			ID:   id,
+			Data: b[2 : 2+l],
+		}
+		// An extension element too short to hold its extension ID is kept
+		// with an empty body so it never matches a lookup by extension ID.
+		if id == ieExtension && l > 0 {
+			ie.ExtensionID = ie.Data[0]
+			ie.Data = ie.Data[1:]
+		}
+
+		ies = append(ies, ie)
+		b = b[2+l:]
+	}
+	return ies, nil
+}
+
+// findIE returns the first element with the given ID from a list of IEs.
+func findIE(ies []IE, id uint8) (IE, bool) {
+	for _, ie := range ies {
+		if ie.ID == id {
+			return ie, true
+		}
+	}
+	return IE{}, false
+}
+
+// findExtensionIE returns the first extension element with the given
+// extension ID from a list of IEs.
+func findExtensionIE(ies []IE, ext uint8) (IE, bool) {
+	for _, ie := range ies {
+		if ie.ID == ieExtension && ie.ExtensionID == ext {
+			return ie, true
+		}
+	}
+	return IE{}, false
+}
+
+// Microsoft's OUI, used for the WPA, WMM and WPS vendor-specific elements.
+var ouiMicrosoft = [3]byte{0x00, 0x50, 0xf2}
+
+// Vendor-specific element types under ouiMicrosoft.
+const (
+	vendorTypeWMM = 2
+	vendorTypeWPS = 4
+)
+
+// Subtypes of the WMM vendor-specific element.
+const (
+	wmmSubtypeInformation = 0
+	wmmSubtypeParameter   = 1
+)
+
+// isHiddenSSID reports whether an SSID is empty or made up only of zero
+// bytes, which APs use to hide their SSID from beacons.
+func isHiddenSSID(b []byte) bool {
+	for _, c := range b {
+		if c != 0x00 {
+			return false
+		}
+	}
+	return true
+}
+
+// DecodeSSID decodes the raw bytes of an SSID into a printable string.
+// Valid, printable UTF-8 is kept as is. Bytes that are not valid UTF-8 or
+// that encode non-printable characters, as well as backslashes, are
+// escaped as \xNN the way iw does. Hidden SSIDs, which are empty or all
+// zero bytes, decode to "", and the null padding some APs add after the
+// SSID is stripped.
+func DecodeSSID(b []byte) string {
+	if isHiddenSSID(b) { return "" }
+	b = bytes.TrimRight(b, "\x00")
+
+	buf := bytes.NewBuffer(nil)
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		if (r == utf8.RuneError && size <= 1) || r == '\\' || !unicode.IsPrint(r) {
+			for _, c := range b[:size] {
+				fmt.Fprintf(buf, "\\x%02x", c)
+			}
+		} else {
+			buf.WriteRune(r)
+		}
+		b = b[size:]
+	}
+	return buf.String()
+}
+
+// parseRates parses the body of a Supported Rates or Extended Supported
+// Rates element into rates in Mbps, split into all supported rates and the
+// basic rates every station in the BSS must support. BSS membership
+// selectors, which share the encoding, are skipped.
+func parseRates(b []byte) (supported, basic []float64) {
+	for _, r := range b {
+		basicRate := r&0x80 != 0
+		rate := r & 0x7f
+		if basicRate && rate >= 122 { continue }
+
+		mbps := float64(rate) / 2
+		supported = append(supported, mbps)
+		if basicRate {
+			basic = append(basic, mbps)
+		}
+	}
+	return supported, basic
+}
+
+// encodeRates encodes rates in Mbps into the body of a Supported Rates or
+// Extended Supported Rates element, flagging those listed in basic.
+func encodeRates(rates, basic []float64) []byte {
+	b := make([]byte, 0, len(rates))
+	for _, mbps := range rates {
+		r := byte(mbps * 2)
+		for _, br := range basic {
+			if br == mbps {
+				r |= 0x80
+				break
+			}
+		}
+		b = append(b, r)
+	}
+	return b
+}
+
+// BSSLoad describes the contents of a BSS Load element (IE 11).
+type BSSLoad struct {
+	// StationCount is the number of stations associated with the BSS.
+	StationCount int
+
+	// ChannelUtilization is the fraction of time, scaled to 0-255, the AP
+	// sensed the medium busy.
+	ChannelUtilization uint8
+
+	// AvailableAdmissionCapacity is the remaining amount of medium time
+	// available via explicit admission control, in units of 32 µs/s.
+	AvailableAdmissionCapacity int
+}
+
+// ChannelUtilizationPercent returns ChannelUtilization as a percentage.
+func (l *BSSLoad) ChannelUtilizationPercent() float64 {
+	return float64(l.ChannelUtilization) * 100 / 255
+}
+
+// parseBSSLoad parses the body of a BSS Load element.
+func parseBSSLoad(b []byte) (*BSSLoad, error) {
+	if len(b) != 5 { return nil, fmt.Errorf("parseBSSLoad: %v", errInvalidIE) }
+
+	return &BSSLoad{
+		StationCount:               int(binary.LittleEndian.Uint16(b[0:2])),
+		ChannelUtilization:         b[2],
+		AvailableAdmissionCapacity: int(binary.LittleEndian.Uint16(b[3:5])),
+	}, nil
+}
+
+// ERPInfo describes the contents of an ERP element (IE 42), which 802.11g
+// APs use to protect 802.11b stations sharing the channel.
+type ERPInfo struct {
+	// NonERPPresent is set when 802.11b-only stations are associated
+	// with or were heard near the BSS.
+	NonERPPresent bool
+
+	// UseProtection is set when 802.11g transmissions must be protected,
+	// for instance by CTS-to-self, so that 802.11b stations defer to them.
+	UseProtection bool
+
+	// BarkerPreambleMode is set when some stations can't receive short
+	// preambles.
+	BarkerPreambleMode bool
+}
+
+// parseERP parses the body of an ERP element.
+func parseERP(b []byte) (*ERPInfo, error) {
+	if len(b) < 1 { return nil, fmt.Errorf("parseERP: %v", errInvalidIE) }
+
+	return &ERPInfo{
+		NonERPPresent:      b[0]&0x01 != 0,
+		UseProtection:      b[0]&0x02 != 0,
+		BarkerPreambleMode: b[0]&0x04 != 0,
+	}, nil
+}
+
+// Country describes the contents of a Country element (IE 7).
+type Country struct {
+	// Code is the two letter ISO 3166-1 country code.
+	Code string
+
+	// Environment is the third byte of the country string: ' ' for any
+	// environment, 'O' for outdoor, 'I' for indoor, or a table number.
+	Environment byte
+
+	Subbands []CountrySubband
+}
+
+// A CountrySubband is a range of channels and the maximum transmit power
+// allowed on them, as advertised in a Country element.
+type CountrySubband struct {
+	FirstChannel int
+	NumChannels  int
+
+	// MaxTxPower is the maximum transmit power in dBm.
+	MaxTxPower int
+
+	// OperatingClass is the operating class the channel numbers belong
+	// to, taken from the preceding operating triplet, or 0 if the element
+	// uses none.
+	OperatingClass int
+}
+
+// parseCountry parses the body of a Country element. A trailing pad byte,
+// which the spec allows to keep the element length even, is ignored.
+func parseCountry(b []byte) (*Country, error) {
+	if len(b) < 3 { return nil, fmt.Errorf("parseCountry: %v", errInvalidIE) }
+
+	c := &Country{
+		Code:        string(b[0:2]),
+		Environment: b[2],
+	}
+
+	var class int
+	for b = b[3:]; len(b) >= 3; b = b[3:] {
+		// A first byte of 201 or above marks an operating triplet
+		// (operating extension identifier, operating class, coverage
+		// class) rather than a subband triplet.
+		if b[0] >= 201 {
+			class = int(b[1])
+			continue
+		}
+		c.Subbands = append(c.Subbands, CountrySubband{
+			FirstChannel:   int(b[0]),
+			NumChannels:    int(b[1]),
+			MaxTxPower:     int(int8(b[2])),
+			OperatingClass: class,
+		})
+	}
+	return c, nil
+}
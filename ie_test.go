@@ -0,0 +1,66 @@
+package wifi_test
+
+import (
+	"testing"
+
+	"github.com/bryancoxwell/wifi"
+)
+
+// TestDecodeSSID tests the DecodeSSID function from the wifi package.
+// Printable UTF-8 should be kept while everything else is escaped as \xNN.
+func TestDecodeSSID(t *testing.T) {
+	tests := []struct {
+		name     string
+		ssid     []byte
+		expected string
+	}{
+		{
+			name:     "ASCII",
+			ssid:     []byte("Home Network"),
+			expected: "Home Network",
+		},
+		{
+			name:     "UTF-8",
+			ssid:     []byte("Café ☕"),
+			expected: "Café ☕",
+		},
+		{
+			name:     "Latin-1",
+			ssid:     []byte{'C', 'a', 'f', 0xe9},
+			expected: `Caf\xe9`,
+		},
+		{
+			name:     "embedded NUL",
+			ssid:     []byte{'a', 0x00, 'b'},
+			expected: `a\x00b`,
+		},
+		{
+			name:     "null padded",
+			ssid:     []byte{'a', 'b', 0x00, 0x00},
+			expected: "ab",
+		},
+		{
+			name:     "hidden",
+			ssid:     []byte{0x00, 0x00, 0x00, 0x00},
+			expected: "",
+		},
+		{
+			name:     "control characters",
+			ssid:     []byte("a\x1b[2Jb"),
+			expected: `a\x1b[2Jb`,
+		},
+		{
+			name:     "backslash",
+			ssid:     []byte(`a\xe9`),
+			expected: `a\x5cxe9`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wifi.DecodeSSID(tt.ssid); got != tt.expected {
+				t.Errorf("DecodeSSID mismatch.\nExpected: \t%q\nGot:\t\t%q\n", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package wifi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+)
+
+// The types below are marshaled to JSON as their fields would be, except
+// that hardware addresses render as colon separated strings and durations
+// in the format of time.Duration.String, e.g. "1m30s". Unmarshaling reads
+// the same format back.
+
+// MarshalText implements encoding.TextMarshaler, rendering an InterfaceType
+// as its String representation.
+func (t InterfaceType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, parsing the String
+// representation of an InterfaceType.
+func (t *InterfaceType) UnmarshalText(text []byte) error {
+	for typ := InterfaceTypeUnspecified; typ <= InterfaceTypeNAN; typ++ {
+		if typ.String() == string(text) {
+			*t = typ
+			return nil
+		}
+	}
+	var n int
+	if _, err := fmt.Sscanf(string(text), "unknown(%d)", &n); err != nil {
+		return fmt.Errorf("unknown interface type %q", text)
+	}
+	*t = InterfaceType(n)
+	return nil
+}
+
+// parseHardwareAddr parses a hardware address marshaled by the types below,
+// where the empty string is a missing address.
+func parseHardwareAddr(s string) (net.HardwareAddr, error) {
+	if s == "" { return nil, nil }
+	return net.ParseMAC(s)
+}
+
+// parseDurations parses the durations marshaled by the types below into the
+// fields pointed to by each key, leaving those that are missing alone.
+func parseDurations(durations map[*time.Duration]string) error {
+	for d, s := range durations {
+		if s == "" { continue }
+		v, err := time.ParseDuration(s)
+		if err != nil { return err }
+		*d = v
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c WifiInterface) MarshalJSON() ([]byte, error) {
+	type wifiInterface WifiInterface
+	return json.Marshal(struct {
+		wifiInterface
+		HardwareAddr string
+	}{
+		wifiInterface: wifiInterface(c),
+		HardwareAddr:  c.HardwareAddr.String(),
+	})
+}
+
+// MarshalJSON implements json.Marshaler.
+func (b BSS) MarshalJSON() ([]byte, error) {
+	type bss BSS
+	return json.Marshal(struct {
+		bss
+		BSSID          string
+		BeaconInterval string
+		LastSeen       string
+	}{
+		bss:            bss(b),
+		BSSID:          b.BSSID.String(),
+		BeaconInterval: b.BeaconInterval.String(),
+		LastSeen:       b.LastSeen.String(),
+	})
+}
+
+// MarshalJSON implements json.Marshaler.
+func (info StationInfo) MarshalJSON() ([]byte, error) {
+	type stationInfo StationInfo
+	return json.Marshal(struct {
+		stationInfo
+		HardwareAddr     string
+		Connected        string
+		Inactive         string
+		ReceiveDuration  string
+		TransmitDuration string
+	}{
+		stationInfo:      stationInfo(info),
+		HardwareAddr:     info.HardwareAddr.String(),
+		Connected:        info.Connected.String(),
+		Inactive:         info.Inactive.String(),
+		ReceiveDuration:  info.ReceiveDuration.String(),
+		TransmitDuration: info.TransmitDuration.String(),
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *WifiInterface) UnmarshalJSON(b []byte) error {
+	type wifiInterface WifiInterface
+	v := struct {
+		*wifiInterface
+		HardwareAddr string
+	}{wifiInterface: (*wifiInterface)(c)}
+	if err := json.Unmarshal(b, &v); err != nil { return err }
+
+	mac, err := parseHardwareAddr(v.HardwareAddr)
+	if err != nil { return err }
+	c.HardwareAddr = mac
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *BSS) UnmarshalJSON(data []byte) error {
+	type bss BSS
+	v := struct {
+		*bss
+		BSSID          string
+		BeaconInterval string
+		LastSeen       string
+	}{bss: (*bss)(b)}
+	if err := json.Unmarshal(data, &v); err != nil { return err }
+
+	mac, err := parseHardwareAddr(v.BSSID)
+	if err != nil { return err }
+	b.BSSID = mac
+	return parseDurations(map[*time.Duration]string{
+		&b.BeaconInterval: v.BeaconInterval,
+		&b.LastSeen:       v.LastSeen,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (info *StationInfo) UnmarshalJSON(b []byte) error {
+	type stationInfo StationInfo
+	v := struct {
+		*stationInfo
+		HardwareAddr     string
+		Connected        string
+		Inactive         string
+		ReceiveDuration  string
+		TransmitDuration string
+	}{stationInfo: (*stationInfo)(info)}
+	if err := json.Unmarshal(b, &v); err != nil { return err }
+
+	mac, err := parseHardwareAddr(v.HardwareAddr)
+	if err != nil { return err }
+	info.HardwareAddr = mac
+	return parseDurations(map[*time.Duration]string{
+		&info.Connected:        v.Connected,
+		&info.Inactive:         v.Inactive,
+		&info.ReceiveDuration:  v.ReceiveDuration,
+		&info.TransmitDuration: v.TransmitDuration,
+	})
+}
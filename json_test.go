@@ -0,0 +1,125 @@
+package wifi_test
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bryancoxwell/wifi"
+)
+
+// TestMarshalJSON tests that hardware addresses, durations and interface
+// types are marshaled in a readable form.
+func TestMarshalJSON(t *testing.T) {
+	mac := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x01, 0x00}
+
+	tests := []struct {
+		name     string
+		v        interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name: "WifiInterface",
+			v:    &wifi.WifiInterface{Index: 3, Name: "wlan0", HardwareAddr: mac, Type: wifi.InterfaceTypeStation},
+			expected: map[string]interface{}{
+				"Index":        3.0,
+				"Name":         "wlan0",
+				"HardwareAddr": "02:00:00:00:01:00",
+				"Type":         "station",
+			},
+		},
+		{
+			name: "BSS",
+			v:    wifi.BSS{SSID: "home", BSSID: mac, BeaconInterval: 102400 * time.Microsecond, LastSeen: 1500 * time.Millisecond, Signal: -42},
+			expected: map[string]interface{}{
+				"SSID":           "home",
+				"BSSID":          "02:00:00:00:01:00",
+				"BeaconInterval": "102.4ms",
+				"LastSeen":       "1.5s",
+				"Signal":         -42.0,
+			},
+		},
+		{
+			name: "StationInfo",
+			v:    &wifi.StationInfo{HardwareAddr: mac, Connected: 90 * time.Second, Inactive: 20 * time.Millisecond, TransmitDuration: 1500 * time.Millisecond},
+			expected: map[string]interface{}{
+				"HardwareAddr":     "02:00:00:00:01:00",
+				"Connected":        "1m30s",
+				"Inactive":         "20ms",
+				"TransmitDuration": "1.5s",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.v)
+			if err != nil {
+				t.Fatalf("json.Marshal: %v", err)
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatalf("json.Unmarshal: %v", err)
+			}
+			for k, v := range tt.expected {
+				if got[k] != v {
+					t.Errorf("%s = %v, expected %v in %s", k, got[k], v, b)
+				}
+			}
+		})
+	}
+}
+
+// TestUnmarshalJSON tests that the types marshaled in a readable form
+// survive a round trip through JSON.
+func TestUnmarshalJSON(t *testing.T) {
+	mac := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x01, 0x00}
+
+	tests := []struct {
+		name string
+		v    interface{}
+		new  func() interface{}
+	}{
+		{
+			name: "WifiInterface",
+			v:    &wifi.WifiInterface{Index: 3, Name: "wlan0", HardwareAddr: mac, Type: wifi.InterfaceTypeStation, TxPower: 19.5},
+			new:  func() interface{} { return &wifi.WifiInterface{} },
+		},
+		{
+			name: "WifiInterface without an address",
+			v:    &wifi.WifiInterface{Index: 3, Name: "wlan0", Type: wifi.InterfaceTypeAPVLAN},
+			new:  func() interface{} { return &wifi.WifiInterface{} },
+		},
+		{
+			name: "BSS",
+			v:    &wifi.BSS{SSID: "home", BSSID: mac, BeaconInterval: 102400 * time.Microsecond, LastSeen: 1500 * time.Millisecond, Signal: -42},
+			new:  func() interface{} { return &wifi.BSS{} },
+		},
+		{
+			name: "StationInfo",
+			v:    &wifi.StationInfo{HardwareAddr: mac, Connected: 90 * time.Second, Inactive: 20 * time.Millisecond, TransmitDuration: 1500 * time.Millisecond},
+			new:  func() interface{} { return &wifi.StationInfo{} },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.v)
+			if err != nil {
+				t.Fatalf("json.Marshal: %v", err)
+			}
+			got := tt.new()
+			if err := json.Unmarshal(b, got); err != nil {
+				t.Fatalf("json.Unmarshal: %v", err)
+			}
+			if !reflect.DeepEqual(tt.v, got) {
+				t.Errorf("round trip mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", tt.v, got)
+			}
+		})
+	}
+
+	var typ wifi.InterfaceType
+	if err := typ.UnmarshalText([]byte("hovercraft")); err == nil {
+		t.Error("expected an error for an unknown interface type")
+	}
+}
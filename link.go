@@ -0,0 +1,188 @@
+//go:build linux
+// +build linux
+
+package wifi
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// SetMACAddress sets the hardware address of the given interface. nl80211
+// can't change addresses, so this goes through rtnetlink instead. The kernel
+// only changes the address of a link that is down, so a link that is up is
+// brought down first and back up afterwards, even if setting the address
+// failed.
+func (c *Client) SetMACAddress(w *WifiInterface, mac net.HardwareAddr) error {
+	if err := setMACAddress(w, mac, true); err != nil { return fmt.Errorf("SetMACAddress: %v", err) }
+	return nil
+}
+
+// SetHardwareAddr sets the hardware address of the given interface like
+// SetMACAddress, but leaves the link state to the caller: the link must be
+// brought down first, with SetInterfaceDown, and SetHardwareAddr returns an
+// error without changing anything if it is up. Taking a link down drops its
+// association, which SetHardwareAddr never does behind the caller's back.
+func (c *Client) SetHardwareAddr(w *WifiInterface, mac net.HardwareAddr) error {
+	if err := setMACAddress(w, mac, false); err != nil { return fmt.Errorf("SetHardwareAddr: %v", err) }
+	return nil
+}
+
+// setMACAddress sets the hardware address of w through rtnetlink. A link
+// that is up is brought down for the change if bringDown is set, and
+// refused otherwise.
+func setMACAddress(w *WifiInterface, mac net.HardwareAddr, bringDown bool) error {
+	if len(mac) != 6 { return fmt.Errorf("invalid hardware address: %v", mac) }
+
+	conn, err := dialRoute()
+	if err != nil { return err }
+	defer conn.Close()
+
+	set := func() error { return setLinkAddress(conn, w.Index, mac) }
+	if bringDown {
+		err = withLinkDown(conn, w, set)
+	} else {
+		err = ifLinkDown(conn, w, set)
+	}
+	if err != nil { return err }
+
+	w.HardwareAddr = mac
+	return nil
+}
+
+// SetLinkUp brings the given interface up or down, like "ip link set up"
+// and "ip link set down". nl80211 can't do this itself, so the request goes
+// through rtnetlink.
+func (c *Client) SetLinkUp(w *WifiInterface, up bool) error {
+	if err := setInterfaceUp(w, up); err != nil { return fmt.Errorf("SetLinkUp: %v", err) }
+	return nil
+}
+
+// SetInterfaceUp brings the given interface up, like "ip link set up".
+func (c *Client) SetInterfaceUp(w *WifiInterface) error {
+	if err := setInterfaceUp(w, true); err != nil { return fmt.Errorf("SetInterfaceUp: %v", err) }
+	return nil
+}
+
+// SetInterfaceDown brings the given interface down, like "ip link set down".
+func (c *Client) SetInterfaceDown(w *WifiInterface) error {
+	if err := setInterfaceUp(w, false); err != nil { return fmt.Errorf("SetInterfaceDown: %v", err) }
+	return nil
+}
+
+func setInterfaceUp(w *WifiInterface, up bool) error {
+	conn, err := dialRoute()
+	if err != nil { return err }
+	defer conn.Close()
+
+	return setLinkUp(conn, w.Index, up)
+}
+
+// routeConn is the part of a rtnetlink connection used to change links.
+type routeConn interface {
+	Execute(m netlink.Message) ([]netlink.Message, error)
+	Close() error
+}
+
+// dialRoute opens a rtnetlink connection. It is a variable so that tests
+// can replace the connection.
+var dialRoute = func() (routeConn, error) {
+	conn, err := netlink.Dial(unix.NETLINK_ROUTE, nil)
+	if err != nil { return nil, fmt.Errorf("failed to open rtnetlink connection: %v", err) }
+	return conn, nil
+}
+
+// withLinkDown calls fn with the link of w down. A link that is up is
+// brought down first and back up afterwards, even if fn failed.
+func withLinkDown(conn routeConn, w *WifiInterface, fn func() error) error {
+	flags, err := linkFlags(conn, w.Index)
+	if err != nil { return err }
+
+	up := flags&unix.IFF_UP != 0
+	if up {
+		if err := setLinkUp(conn, w.Index, false); err != nil {
+			return fmt.Errorf("failed to bring %s down: %v", w.Name, err)
+		}
+	}
+
+	err = fn()
+	if up {
+		if uerr := setLinkUp(conn, w.Index, true); uerr != nil && err == nil {
+			err = fmt.Errorf("failed to bring %s back up: %v", w.Name, uerr)
+		}
+	}
+	return err
+}
+
+// ifLinkDown calls fn if the link of w is down, returning an error
+// otherwise.
+func ifLinkDown(conn routeConn, w *WifiInterface, fn func() error) error {
+	flags, err := linkFlags(conn, w.Index)
+	if err != nil { return err }
+
+	if flags&unix.IFF_UP != 0 { return fmt.Errorf("%s is up, bring it down first", w.Name) }
+	return fn()
+}
+
+// linkFlags returns the IFF_* flags of the link with the given index.
+func linkFlags(conn routeConn, ifindex uint32) (uint32, error) {
+	msgs, err := conn.Execute(netlink.Message{
+		Header: netlink.Header{
+			Type:  unix.RTM_GETLINK,
+			Flags: netlink.Request,
+		},
+		Data: ifInfoMsg(ifindex, 0, 0),
+	})
+	if err != nil { return 0, err }
+
+	for _, m := range msgs {
+		if len(m.Data) < unix.SizeofIfInfomsg { continue }
+		return nlenc.Uint32(m.Data[8:12]), nil
+	}
+	return 0, fmt.Errorf("no link with index %d", ifindex)
+}
+
+// setLinkUp sets or clears the IFF_UP flag of the link with the given index.
+func setLinkUp(conn routeConn, ifindex uint32, up bool) error {
+	var flags uint32
+	if up {
+		flags = unix.IFF_UP
+	}
+	return setLink(conn, ifInfoMsg(ifindex, flags, unix.IFF_UP))
+}
+
+// setLinkAddress sets the hardware address of the link with the given index.
+func setLinkAddress(conn routeConn, ifindex uint32, mac net.HardwareAddr) error {
+	attrs, err := netlink.MarshalAttributes([]netlink.Attribute{
+		{Type: unix.IFLA_ADDRESS, Data: mac},
+	})
+	if err != nil { return err }
+	return setLink(conn, append(ifInfoMsg(ifindex, 0, 0), attrs...))
+}
+
+// setLink sends a RTM_NEWLINK request modifying an existing link.
+func setLink(conn routeConn, data []byte) error {
+	_, err := conn.Execute(netlink.Message{
+		Header: netlink.Header{
+			Type:  unix.RTM_NEWLINK,
+			Flags: netlink.Request | netlink.Acknowledge,
+		},
+		Data: data,
+	})
+	return err
+}
+
+// ifInfoMsg returns an encoded struct ifinfomsg for the link with the given
+// index, changing the flags selected by change to those in flags.
+func ifInfoMsg(ifindex, flags, change uint32) []byte {
+	b := make([]byte, unix.SizeofIfInfomsg)
+	b[0] = unix.AF_UNSPEC
+	nlenc.PutUint32(b[4:8], ifindex)
+	nlenc.PutUint32(b[8:12], flags)
+	nlenc.PutUint32(b[12:16], change)
+	return b
+}
@@ -0,0 +1,235 @@
+package wifi_test
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/bryancoxwell/wifi"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// routeConn records the rtnetlink requests sent by the package, answering
+// RTM_GETLINK with a link having the given flags.
+type routeConn struct {
+	flags uint32
+	sent  []netlink.Message
+}
+
+func (c *routeConn) Execute(m netlink.Message) ([]netlink.Message, error) {
+	c.sent = append(c.sent, m)
+	if m.Header.Type != unix.RTM_GETLINK {
+		return nil, nil
+	}
+	return []netlink.Message{{Data: wifi.IfInfoMsg(nlenc.Uint32(m.Data[4:8]), c.flags, 0)}}, nil
+}
+
+func (c *routeConn) Close() error { return nil }
+
+// dialRouteConn makes the package send its rtnetlink requests to a
+// routeConn reporting a link with the given flags.
+func dialRouteConn(t *testing.T, flags uint32) *routeConn {
+	t.Helper()
+	conn := &routeConn{flags: flags}
+	restore := wifi.SetDialRoute(func() (wifi.RouteConn, error) { return conn, nil })
+	t.Cleanup(restore)
+	return conn
+}
+
+// checkSetLink checks that m is a RTM_NEWLINK request for the link with the
+// given index changing the flags in change to flags, returning its
+// attributes.
+func checkSetLink(t *testing.T, m netlink.Message, ifindex, flags, change uint32) []netlink.Attribute {
+	t.Helper()
+	if m.Header.Type != unix.RTM_NEWLINK || m.Header.Flags != netlink.Request|netlink.Acknowledge {
+		t.Fatalf("got message type %d with flags %v, expected an acknowledged RTM_NEWLINK request", m.Header.Type, m.Header.Flags)
+	}
+	if !bytes.Equal(m.Data[:unix.SizeofIfInfomsg], wifi.IfInfoMsg(ifindex, flags, change)) {
+		t.Errorf("got ifinfomsg %x, expected %x", m.Data[:unix.SizeofIfInfomsg], wifi.IfInfoMsg(ifindex, flags, change))
+	}
+	attrs, err := netlink.UnmarshalAttributes(m.Data[unix.SizeofIfInfomsg:])
+	if err != nil {
+		t.Fatalf("failed to decode attributes: %v", err)
+	}
+	return attrs
+}
+
+// TestIfInfoMsg tests the layout of an encoded struct ifinfomsg.
+func TestIfInfoMsg(t *testing.T) {
+	b := wifi.IfInfoMsg(7, unix.IFF_UP, unix.IFF_UP)
+	if len(b) != unix.SizeofIfInfomsg {
+		t.Fatalf("got %d bytes, expected %d", len(b), unix.SizeofIfInfomsg)
+	}
+	if b[0] != unix.AF_UNSPEC {
+		t.Errorf("got family %d, expected AF_UNSPEC", b[0])
+	}
+	if got := nlenc.Uint32(b[4:8]); got != 7 {
+		t.Errorf("got index %d, expected 7", got)
+	}
+	if got := nlenc.Uint32(b[8:12]); got != unix.IFF_UP {
+		t.Errorf("got flags %#x, expected IFF_UP", got)
+	}
+	if got := nlenc.Uint32(b[12:16]); got != unix.IFF_UP {
+		t.Errorf("got change mask %#x, expected IFF_UP", got)
+	}
+}
+
+// TestSetMACAddress tests that the hardware address is set through
+// rtnetlink, bringing a link that is up down for the change.
+func TestSetMACAddress(t *testing.T) {
+	conn := dialRouteConn(t, unix.IFF_UP)
+	w := &wifi.WifiInterface{Index: 7, Name: "wlan0"}
+	mac := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x01, 0x00}
+
+	if err := (&wifi.Client{}).SetMACAddress(w, mac); err != nil {
+		t.Fatalf("SetMACAddress: %v", err)
+	}
+	if len(conn.sent) != 4 {
+		t.Fatalf("got %d messages, expected 4", len(conn.sent))
+	}
+	if conn.sent[0].Header.Type != unix.RTM_GETLINK {
+		t.Errorf("got message type %d, expected RTM_GETLINK", conn.sent[0].Header.Type)
+	}
+	checkSetLink(t, conn.sent[1], 7, 0, unix.IFF_UP)
+	attrs := checkSetLink(t, conn.sent[2], 7, 0, 0)
+	if len(attrs) != 1 || attrs[0].Type != unix.IFLA_ADDRESS || !bytes.Equal(attrs[0].Data, mac) {
+		t.Errorf("got attributes %+v, expected IFLA_ADDRESS %v", attrs, mac)
+	}
+	checkSetLink(t, conn.sent[3], 7, unix.IFF_UP, unix.IFF_UP)
+	if !bytes.Equal(w.HardwareAddr, mac) {
+		t.Errorf("got HardwareAddr %v, expected %v", w.HardwareAddr, mac)
+	}
+
+	if err := (&wifi.Client{}).SetMACAddress(w, mac[:5]); err == nil {
+		t.Error("expected an error for a short hardware address")
+	}
+}
+
+// TestSetHardwareAddr tests that SetHardwareAddr sets the hardware address
+// of a link that is down and refuses a link that is up, leaving its state
+// alone.
+func TestSetHardwareAddr(t *testing.T) {
+	w := &wifi.WifiInterface{Index: 7, Name: "wlan0"}
+	mac := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x01, 0x00}
+
+	conn := dialRouteConn(t, 0)
+	if err := (&wifi.Client{}).SetHardwareAddr(w, mac); err != nil {
+		t.Fatalf("SetHardwareAddr: %v", err)
+	}
+	if len(conn.sent) != 2 {
+		t.Fatalf("got %d messages, expected 2", len(conn.sent))
+	}
+	attrs := checkSetLink(t, conn.sent[1], 7, 0, 0)
+	if len(attrs) != 1 || attrs[0].Type != unix.IFLA_ADDRESS || !bytes.Equal(attrs[0].Data, mac) {
+		t.Errorf("got attributes %+v, expected IFLA_ADDRESS %v", attrs, mac)
+	}
+	if !bytes.Equal(w.HardwareAddr, mac) {
+		t.Errorf("got HardwareAddr %v, expected %v", w.HardwareAddr, mac)
+	}
+
+	w = &wifi.WifiInterface{Index: 7, Name: "wlan0"}
+	conn = dialRouteConn(t, unix.IFF_UP)
+	if err := (&wifi.Client{}).SetHardwareAddr(w, mac); err == nil {
+		t.Fatal("expected an error for a link that is up")
+	}
+	if len(conn.sent) != 1 || conn.sent[0].Header.Type != unix.RTM_GETLINK {
+		t.Errorf("got messages %+v, expected only RTM_GETLINK", conn.sent)
+	}
+	if w.HardwareAddr != nil {
+		t.Errorf("got HardwareAddr %v, expected it unchanged", w.HardwareAddr)
+	}
+}
+
+// TestSetLinkUp tests the requests bringing a link up and down.
+func TestSetLinkUp(t *testing.T) {
+	w := &wifi.WifiInterface{Index: 7, Name: "wlan0"}
+	for _, up := range []bool{true, false} {
+		conn := dialRouteConn(t, 0)
+		if err := (&wifi.Client{}).SetLinkUp(w, up); err != nil {
+			t.Fatalf("SetLinkUp(%v): %v", up, err)
+		}
+		if len(conn.sent) != 1 {
+			t.Fatalf("SetLinkUp(%v): got %d messages, expected 1", up, len(conn.sent))
+		}
+		var flags uint32
+		if up {
+			flags = unix.IFF_UP
+		}
+		if attrs := checkSetLink(t, conn.sent[0], 7, flags, unix.IFF_UP); len(attrs) != 0 {
+			t.Errorf("SetLinkUp(%v): unexpected attributes %+v", up, attrs)
+		}
+	}
+}
+
+// TestSetInterfaceUpDown tests that SetInterfaceUp and SetInterfaceDown
+// send the same requests as SetLinkUp.
+func TestSetInterfaceUpDown(t *testing.T) {
+	w := &wifi.WifiInterface{Index: 7, Name: "wlan0"}
+
+	conn := dialRouteConn(t, 0)
+	if err := (&wifi.Client{}).SetInterfaceUp(w); err != nil {
+		t.Fatalf("SetInterfaceUp: %v", err)
+	}
+	if len(conn.sent) != 1 {
+		t.Fatalf("SetInterfaceUp: got %d messages, expected 1", len(conn.sent))
+	}
+	checkSetLink(t, conn.sent[0], 7, unix.IFF_UP, unix.IFF_UP)
+
+	conn = dialRouteConn(t, unix.IFF_UP)
+	if err := (&wifi.Client{}).SetInterfaceDown(w); err != nil {
+		t.Fatalf("SetInterfaceDown: %v", err)
+	}
+	if len(conn.sent) != 1 {
+		t.Fatalf("SetInterfaceDown: got %d messages, expected 1", len(conn.sent))
+	}
+	checkSetLink(t, conn.sent[0], 7, 0, unix.IFF_UP)
+}
+
+// TestSetMACAddressLinkDown tests that the hardware address of a link that
+// is down is set without changing its state.
+func TestSetMACAddressLinkDown(t *testing.T) {
+	conn := dialRouteConn(t, 0)
+	w := &wifi.WifiInterface{Index: 7, Name: "wlan0"}
+	mac := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x01, 0x00}
+
+	if err := (&wifi.Client{}).SetMACAddress(w, mac); err != nil {
+		t.Fatalf("SetMACAddress: %v", err)
+	}
+	if len(conn.sent) != 2 {
+		t.Fatalf("got %d messages, expected 2", len(conn.sent))
+	}
+	attrs := checkSetLink(t, conn.sent[1], 7, 0, 0)
+	if len(attrs) != 1 || attrs[0].Type != unix.IFLA_ADDRESS || !bytes.Equal(attrs[0].Data, mac) {
+		t.Errorf("got attributes %+v, expected IFLA_ADDRESS %v", attrs, mac)
+	}
+}
+
+// TestCreateInterfaceLinkDown tests that CreateInterface rejects
+// WithLinkDown, which only applies to SetInterfaceType.
+func TestCreateInterfaceLinkDown(t *testing.T) {
+	if _, err := (&wifi.Client{}).CreateInterface(0, "wlan1", wifi.InterfaceTypeStation, wifi.WithLinkDown()); err == nil {
+		t.Error("expected an error for WithLinkDown")
+	}
+}
+
+// TestIsUp tests that IsUp reads the state of an existing link and reports
+// a failed lookup as an error rather than as a link that is down.
+func TestIsUp(t *testing.T) {
+	lo, err := net.InterfaceByName("lo")
+	if err != nil {
+		t.Skipf("no loopback interface: %v", err)
+	}
+	up, err := (&wifi.WifiInterface{Index: uint32(lo.Index)}).IsUp()
+	if err != nil {
+		t.Fatalf("IsUp: %v", err)
+	}
+	if up != (lo.Flags&net.FlagUp != 0) {
+		t.Errorf("IsUp() = %v, expected %v", up, lo.Flags&net.FlagUp != 0)
+	}
+
+	if _, err := (&wifi.WifiInterface{Index: 1 << 30}).IsUp(); err == nil {
+		t.Error("expected an error for an unknown interface")
+	}
+}
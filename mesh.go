@@ -0,0 +1,563 @@
+//go:build linux
+// +build linux
+
+package wifi
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// tu is an IEEE 802.11 time unit.
+const tu = 1024 * time.Microsecond
+
+// MeshConfig holds the parameters of an 802.11s mesh point interface.
+type MeshConfig struct {
+	// Params holds the parameters JoinMesh applies; the others keep the
+	// kernel's defaults, and JoinMesh returns an error if any of them is
+	// set to a non-zero value. GetMeshConfig sets it to the parameters the
+	// kernel reported.
+	Params MeshParamSet
+
+	// RetryTimeout, ConfirmTimeout and HoldingTimeout are the timeouts of
+	// the peer link open, confirm and close messages.
+	RetryTimeout   time.Duration
+	ConfirmTimeout time.Duration
+	HoldingTimeout time.Duration
+
+	// MaxPeerLinks is the maximum number of peer links, and MaxRetries the
+	// number of times a peer link open message is retried.
+	MaxPeerLinks int
+	MaxRetries   int
+
+	// TTL is the hop limit of mesh frames, and ElementTTL that of path
+	// selection elements.
+	TTL        int
+	ElementTTL int
+
+	// AutoOpenPeerLinks is set when peer links are opened to compatible
+	// mesh peers without waiting for userspace.
+	AutoOpenPeerLinks bool
+
+	// SyncOffsetMaxNeighbor is the number of neighbors whose clock offset
+	// is tracked for synchronization.
+	SyncOffsetMaxNeighbor int
+
+	// PathRefreshTime is how long before a path expires it is refreshed,
+	// and MinDiscoveryTimeout the minimum path discovery timeout.
+	PathRefreshTime     time.Duration
+	MinDiscoveryTimeout time.Duration
+
+	// The parameters of the Hybrid Wireless Mesh Protocol, the default
+	// path selection protocol.
+	HWMPMaxPREQRetries           int
+	HWMPActivePathTimeout        time.Duration
+	HWMPPREQMinInterval          time.Duration
+	HWMPPERRMinInterval          time.Duration
+	HWMPNetDiameterTraversalTime time.Duration
+	HWMPRootMode                 int
+	HWMPRANNInterval             time.Duration
+	HWMPPathToRootTimeout        time.Duration
+	HWMPRootInterval             time.Duration
+	HWMPConfirmationInterval     time.Duration
+
+	// GateAnnouncements is set when the mesh point announces itself as a
+	// gate to other networks, and Forwarding when it forwards frames for
+	// other mesh points.
+	GateAnnouncements bool
+	Forwarding        bool
+
+	// RSSIThreshold is the signal level in dBm below which no peer links
+	// are established, or 0 for no threshold.
+	RSSIThreshold int
+
+	// HTOperationMode is the HT protection mode advertised by the mesh.
+	HTOperationMode int
+
+	// PowerMode is the default NL80211_MESH_POWER_* power mode of new
+	// peer links, and AwakeWindow the time the mesh point stays awake
+	// after its beacons when it sleeps.
+	PowerMode   int
+	AwakeWindow time.Duration
+
+	// PeerLinkTimeout is how long an inactive peer link is kept, or 0 if
+	// it is never removed.
+	PeerLinkTimeout time.Duration
+
+	// ConnectedToGate and ConnectedToAS advertise a path to a mesh gate
+	// and to an authentication server, and NoLearn is set when the mesh
+	// doesn't learn paths from the frames it forwards.
+	ConnectedToGate bool
+	ConnectedToAS   bool
+	NoLearn         bool
+
+	// UserspacePeering leaves peering to userspace: the kernel reports
+	// candidate peers with a MeshPeerCandidateEvent, and they are added
+	// with AddMeshPeer. It is only used by JoinMesh, as part of the mesh
+	// setup, and isn't reported by GetMeshConfig.
+	UserspacePeering bool
+}
+
+// A MeshPeerCandidateEvent reports a mesh point that could become a peer of
+// a mesh joined with MeshConfig.UserspacePeering. It is sent on the "mlme"
+// multicast group.
+type MeshPeerCandidateEvent struct {
+	InterfaceIndex uint32
+	WiphyIndex     uint32
+	HardwareAddr   net.HardwareAddr
+
+	// Signal is the signal level of the candidate's beacon in dBm, or 0
+	// if the driver doesn't report it.
+	Signal int
+
+	// IEs holds the elements of the candidate's beacon.
+	IEs []byte
+}
+
+// meshPeerListenInterval is the listen interval sent for mesh peers. They
+// don't use one, but the kernel requires it.
+const meshPeerListenInterval = 100
+
+// A MeshParam is a mesh parameter that can be changed at runtime with
+// SetMeshParam. Its value is given in the units used by nl80211, noted for
+// each parameter.
+type MeshParam uint16
+
+const (
+	MeshParamRetryTimeout                 MeshParam = unix.NL80211_MESHCONF_RETRY_TIMEOUT                 // ms
+	MeshParamConfirmTimeout               MeshParam = unix.NL80211_MESHCONF_CONFIRM_TIMEOUT               // ms
+	MeshParamHoldingTimeout               MeshParam = unix.NL80211_MESHCONF_HOLDING_TIMEOUT               // ms
+	MeshParamMaxPeerLinks                 MeshParam = unix.NL80211_MESHCONF_MAX_PEER_LINKS
+	MeshParamMaxRetries                   MeshParam = unix.NL80211_MESHCONF_MAX_RETRIES
+	MeshParamTTL                          MeshParam = unix.NL80211_MESHCONF_TTL
+	MeshParamElementTTL                   MeshParam = unix.NL80211_MESHCONF_ELEMENT_TTL
+	MeshParamAutoOpenPeerLinks            MeshParam = unix.NL80211_MESHCONF_AUTO_OPEN_PLINKS              // 0 or 1
+	MeshParamSyncOffsetMaxNeighbor        MeshParam = unix.NL80211_MESHCONF_SYNC_OFFSET_MAX_NEIGHBOR
+	MeshParamPathRefreshTime              MeshParam = unix.NL80211_MESHCONF_PATH_REFRESH_TIME             // ms
+	MeshParamMinDiscoveryTimeout          MeshParam = unix.NL80211_MESHCONF_MIN_DISCOVERY_TIMEOUT         // ms
+	MeshParamHWMPMaxPREQRetries           MeshParam = unix.NL80211_MESHCONF_HWMP_MAX_PREQ_RETRIES
+	MeshParamHWMPActivePathTimeout        MeshParam = unix.NL80211_MESHCONF_HWMP_ACTIVE_PATH_TIMEOUT      // TUs
+	MeshParamHWMPPREQMinInterval          MeshParam = unix.NL80211_MESHCONF_HWMP_PREQ_MIN_INTERVAL        // TUs
+	MeshParamHWMPPERRMinInterval          MeshParam = unix.NL80211_MESHCONF_HWMP_PERR_MIN_INTERVAL        // TUs
+	MeshParamHWMPNetDiameterTraversalTime MeshParam = unix.NL80211_MESHCONF_HWMP_NET_DIAM_TRVS_TIME       // TUs
+	MeshParamHWMPRootMode                 MeshParam = unix.NL80211_MESHCONF_HWMP_ROOTMODE
+	MeshParamHWMPRANNInterval             MeshParam = unix.NL80211_MESHCONF_HWMP_RANN_INTERVAL            // TUs
+	MeshParamHWMPPathToRootTimeout        MeshParam = unix.NL80211_MESHCONF_HWMP_PATH_TO_ROOT_TIMEOUT     // TUs
+	MeshParamHWMPRootInterval             MeshParam = unix.NL80211_MESHCONF_HWMP_ROOT_INTERVAL            // TUs
+	MeshParamHWMPConfirmationInterval     MeshParam = unix.NL80211_MESHCONF_HWMP_CONFIRMATION_INTERVAL    // TUs
+	MeshParamGateAnnouncements            MeshParam = unix.NL80211_MESHCONF_GATE_ANNOUNCEMENTS            // 0 or 1
+	MeshParamForwarding                   MeshParam = unix.NL80211_MESHCONF_FORWARDING                    // 0 or 1
+	MeshParamRSSIThreshold                MeshParam = unix.NL80211_MESHCONF_RSSI_THRESHOLD                // dBm
+	MeshParamHTOperationMode              MeshParam = unix.NL80211_MESHCONF_HT_OPMODE
+	MeshParamPowerMode                    MeshParam = unix.NL80211_MESHCONF_POWER_MODE
+	MeshParamAwakeWindow                  MeshParam = unix.NL80211_MESHCONF_AWAKE_WINDOW                  // TUs
+	MeshParamPeerLinkTimeout              MeshParam = unix.NL80211_MESHCONF_PLINK_TIMEOUT                 // s
+	MeshParamConnectedToGate              MeshParam = unix.NL80211_MESHCONF_CONNECTED_TO_GATE             // 0 or 1
+	MeshParamConnectedToAS                MeshParam = unix.NL80211_MESHCONF_CONNECTED_TO_AS               // 0 or 1
+	MeshParamNoLearn                      MeshParam = unix.NL80211_MESHCONF_NOLEARN                       // 0 or 1
+)
+
+// A MeshParamSet is a set of mesh parameters.
+type MeshParamSet uint64
+
+// MeshParams returns the set holding the given parameters.
+func MeshParams(params ...MeshParam) MeshParamSet {
+	var s MeshParamSet
+	for _, p := range params {
+		s |= 1 << p
+	}
+	return s
+}
+
+// Has reports whether p is in s.
+func (s MeshParamSet) Has(p MeshParam) bool {
+	return p < 64 && s&(1<<p) != 0
+}
+
+// size returns the size in bytes of the value of p, or 0 if p is unknown.
+func (p MeshParam) size() int {
+	switch p {
+	case MeshParamMaxRetries, MeshParamTTL, MeshParamElementTTL, MeshParamAutoOpenPeerLinks,
+		MeshParamHWMPMaxPREQRetries, MeshParamHWMPRootMode, MeshParamGateAnnouncements,
+		MeshParamForwarding, MeshParamConnectedToGate, MeshParamConnectedToAS, MeshParamNoLearn:
+		return 1
+	case MeshParamRetryTimeout, MeshParamConfirmTimeout, MeshParamHoldingTimeout, MeshParamMaxPeerLinks,
+		MeshParamMinDiscoveryTimeout, MeshParamHWMPPREQMinInterval, MeshParamHWMPPERRMinInterval,
+		MeshParamHWMPNetDiameterTraversalTime, MeshParamHWMPRANNInterval, MeshParamHWMPRootInterval,
+		MeshParamHWMPConfirmationInterval, MeshParamHTOperationMode, MeshParamAwakeWindow:
+		return 2
+	case MeshParamSyncOffsetMaxNeighbor, MeshParamPathRefreshTime, MeshParamHWMPActivePathTimeout,
+		MeshParamRSSIThreshold, MeshParamHWMPPathToRootTimeout, MeshParamPowerMode, MeshParamPeerLinkTimeout:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// attribute returns the NL80211_MESHCONF_* attribute setting p to value.
+func (p MeshParam) attribute(value int) (AttributeEncoder, error) {
+	switch p.size() {
+	case 1:
+		if value < 0 || value > 0xff { return nil, fmt.Errorf("invalid value %d for mesh parameter %d", value, p) }
+		return NewAttributeFactory[uint8](uint16(p))(uint8(value)), nil
+	case 2:
+		if value < 0 || value > 0xffff { return nil, fmt.Errorf("invalid value %d for mesh parameter %d", value, p) }
+		return NewAttributeFactory[uint16](uint16(p))(uint16(value)), nil
+	case 4:
+		// Only the RSSI threshold is signed; the kernel reads it as an s32.
+		if p == MeshParamRSSIThreshold {
+			return NewAttributeFactory[uint32](uint16(p))(uint32(int32(value))), nil
+		}
+		if value < 0 || int64(value) > 0xffffffff { return nil, fmt.Errorf("invalid value %d for mesh parameter %d", value, p) }
+		return NewAttributeFactory[uint32](uint16(p))(uint32(value)), nil
+	default:
+		return nil, fmt.Errorf("unknown mesh parameter %d", p)
+	}
+}
+
+// A meshParamValue is the value of a mesh parameter in nl80211 units.
+type meshParamValue struct {
+	param MeshParam
+	value int
+}
+
+// params returns the value of each parameter of cfg.
+func (cfg *MeshConfig) params() []meshParamValue {
+	return []meshParamValue{
+		{MeshParamRetryTimeout, int(cfg.RetryTimeout / time.Millisecond)},
+		{MeshParamConfirmTimeout, int(cfg.ConfirmTimeout / time.Millisecond)},
+		{MeshParamHoldingTimeout, int(cfg.HoldingTimeout / time.Millisecond)},
+		{MeshParamMaxPeerLinks, cfg.MaxPeerLinks},
+		{MeshParamMaxRetries, cfg.MaxRetries},
+		{MeshParamTTL, cfg.TTL},
+		{MeshParamElementTTL, cfg.ElementTTL},
+		{MeshParamAutoOpenPeerLinks, boolToInt(cfg.AutoOpenPeerLinks)},
+		{MeshParamSyncOffsetMaxNeighbor, cfg.SyncOffsetMaxNeighbor},
+		{MeshParamPathRefreshTime, int(cfg.PathRefreshTime / time.Millisecond)},
+		{MeshParamMinDiscoveryTimeout, int(cfg.MinDiscoveryTimeout / time.Millisecond)},
+		{MeshParamHWMPMaxPREQRetries, cfg.HWMPMaxPREQRetries},
+		{MeshParamHWMPActivePathTimeout, int(cfg.HWMPActivePathTimeout / tu)},
+		{MeshParamHWMPPREQMinInterval, int(cfg.HWMPPREQMinInterval / tu)},
+		{MeshParamHWMPPERRMinInterval, int(cfg.HWMPPERRMinInterval / tu)},
+		{MeshParamHWMPNetDiameterTraversalTime, int(cfg.HWMPNetDiameterTraversalTime / tu)},
+		{MeshParamHWMPRootMode, cfg.HWMPRootMode},
+		{MeshParamHWMPRANNInterval, int(cfg.HWMPRANNInterval / tu)},
+		{MeshParamHWMPPathToRootTimeout, int(cfg.HWMPPathToRootTimeout / tu)},
+		{MeshParamHWMPRootInterval, int(cfg.HWMPRootInterval / tu)},
+		{MeshParamHWMPConfirmationInterval, int(cfg.HWMPConfirmationInterval / tu)},
+		{MeshParamGateAnnouncements, boolToInt(cfg.GateAnnouncements)},
+		{MeshParamForwarding, boolToInt(cfg.Forwarding)},
+		{MeshParamRSSIThreshold, cfg.RSSIThreshold},
+		{MeshParamHTOperationMode, cfg.HTOperationMode},
+		{MeshParamPowerMode, cfg.PowerMode},
+		{MeshParamAwakeWindow, int(cfg.AwakeWindow / tu)},
+		{MeshParamPeerLinkTimeout, int(cfg.PeerLinkTimeout / time.Second)},
+		{MeshParamConnectedToGate, boolToInt(cfg.ConnectedToGate)},
+		{MeshParamConnectedToAS, boolToInt(cfg.ConnectedToAS)},
+		{MeshParamNoLearn, boolToInt(cfg.NoLearn)},
+	}
+}
+
+// attribute returns the nested NL80211_ATTR_MESH_CONFIG attribute holding
+// the parameters of cfg in cfg.Params, reporting an error for a parameter
+// that is set but not in cfg.Params, which would be silently dropped.
+func (cfg *MeshConfig) attribute() (AttributeEncoder, error) {
+	params := cfg.params()
+	attrs := make([]AttributeEncoder, 0, len(params))
+	for _, p := range params {
+		if !cfg.Params.Has(p.param) {
+			if p.value != 0 { return nil, fmt.Errorf("mesh parameter %d is set but not in Params", p.param) }
+			continue
+		}
+		attr, err := p.param.attribute(p.value)
+		if err != nil { return nil, err }
+		attrs = append(attrs, attr)
+	}
+	return NestedAttribute(unix.NL80211_ATTR_MESH_CONFIG, attrs...), nil
+}
+
+// boolToInt returns 1 for true and 0 for false.
+func boolToInt(b bool) int {
+	if b { return 1 }
+	return 0
+}
+
+// JoinMesh joins the 802.11s mesh with the given mesh ID on the frequency
+// freq in MHz with the given mesh point interface. Like an SSID, the mesh ID
+// is up to 32 bytes of arbitrary data. The mesh configuration is optional:
+// without one, or with the zero MeshConfig, the kernel's defaults are kept,
+// and otherwise only the parameters in cfg.Params are applied.
+func (c *Client) JoinMesh(w *WifiInterface, meshID string, freq int, cfg ...MeshConfig) error {
+	var config MeshConfig
+	switch len(cfg) {
+	case 0:
+	case 1:
+		config = cfg[0]
+	default:
+		return errors.New("JoinMesh: more than one mesh configuration given")
+	}
+
+	attrs, err := joinMeshAttrs(w, meshID, freq, &config)
+	if err != nil { return fmt.Errorf("JoinMesh: %v", err) }
+
+	msg, err := NewNl80211Message(unix.NL80211_CMD_JOIN_MESH, attrs)
+	if err != nil { return fmt.Errorf("JoinMesh: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("JoinMesh: %w", err) }
+	return nil
+}
+
+// LeaveMesh leaves the mesh joined by the given mesh point interface.
+func (c *Client) LeaveMesh(w *WifiInterface) error {
+	attrs := []AttributeEncoder{InterfaceIndexAttribute(w.Index)}
+	msg, err := NewNl80211Message(unix.NL80211_CMD_LEAVE_MESH, attrs)
+	if err != nil { return fmt.Errorf("LeaveMesh: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("LeaveMesh: %w", err) }
+	return nil
+}
+
+// SetMeshParam changes a single parameter of the mesh joined by the given
+// mesh point interface, with value in the units noted for param.
+func (c *Client) SetMeshParam(w *WifiInterface, param MeshParam, value int) error {
+	attr, err := param.attribute(value)
+	if err != nil { return fmt.Errorf("SetMeshParam: %v", err) }
+
+	attrs := []AttributeEncoder{
+		InterfaceIndexAttribute(w.Index),
+		NestedAttribute(unix.NL80211_ATTR_MESH_CONFIG, attr),
+	}
+	msg, err := NewNl80211Message(unix.NL80211_CMD_SET_MESH_CONFIG, attrs)
+	if err != nil { return fmt.Errorf("SetMeshParam: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("SetMeshParam: %w", err) }
+	return nil
+}
+
+// joinMeshAttrs returns the NL80211_CMD_JOIN_MESH attributes joining the
+// mesh meshID on freq with cfg.
+func joinMeshAttrs(w *WifiInterface, meshID string, freq int, cfg *MeshConfig) ([]AttributeEncoder, error) {
+	if w.Type != InterfaceTypeMeshPoint { return nil, fmt.Errorf("%s is a %v interface, not a mesh point", w.Name, w.Type) }
+	if len(meshID) == 0 || len(meshID) > 32 { return nil, fmt.Errorf("invalid mesh ID %q", meshID) }
+	if freq <= 0 { return nil, errors.New("no frequency given") }
+
+	def := ChannelDefinition{Frequency: uint32(freq)}
+	attrs := []AttributeEncoder{
+		InterfaceIndexAttribute(w.Index),
+		MeshIDAttribute([]byte(meshID)),
+	}
+	attrs = append(attrs, channelWidthEncoder(&def)...)
+	attr, err := cfg.attribute()
+	if err != nil { return nil, err }
+	if cfg.Params != 0 {
+		attrs = append(attrs, attr)
+	}
+	if cfg.UserspacePeering {
+		attrs = append(attrs, NestedFlagsAttribute(unix.NL80211_ATTR_MESH_SETUP, unix.NL80211_MESH_SETUP_USERSPACE_MPM))
+	}
+	return attrs, nil
+}
+
+// AddMeshPeer adds the mesh point with the given hardware address as a peer
+// of the given mesh point interface, with the association ID aid and the
+// rates in Mbps it supports, typically taken from the IEs of its
+// MeshPeerCandidateEvent. This is only needed for meshes joined with
+// MeshConfig.UserspacePeering. The peer link is added as established and
+// authenticated; on a secured mesh, the peer is then authorized with
+// SetStationFlags once its keys are installed.
+func (c *Client) AddMeshPeer(w *WifiInterface, mac net.HardwareAddr, aid uint16, supportedRates []float64) error {
+	attrs, err := meshPeerAttrs(w, mac, aid, supportedRates)
+	if err != nil { return fmt.Errorf("AddMeshPeer: %v", err) }
+
+	msg, err := NewNl80211Message(unix.NL80211_CMD_NEW_STATION, attrs)
+	if err != nil { return fmt.Errorf("AddMeshPeer: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("AddMeshPeer: %w", err) }
+	return nil
+}
+
+// meshPeerAttrs returns the NL80211_CMD_NEW_STATION attributes adding mac
+// as a mesh peer.
+func meshPeerAttrs(w *WifiInterface, mac net.HardwareAddr, aid uint16, supportedRates []float64) ([]AttributeEncoder, error) {
+	if w.Type != InterfaceTypeMeshPoint { return nil, fmt.Errorf("%s is a %v interface, not a mesh point", w.Name, w.Type) }
+	if len(mac) != 6 { return nil, fmt.Errorf("invalid hardware address: %v", mac) }
+	if aid < 1 || aid > 2007 { return nil, fmt.Errorf("invalid association ID %d", aid) }
+	if len(supportedRates) == 0 { return nil, errors.New("no supported rates") }
+
+	return []AttributeEncoder{
+		InterfaceIndexAttribute(w.Index),
+		MacAttribute(mac),
+		NewAttributeFactory[uint16](unix.NL80211_ATTR_STA_AID)(aid),
+		NewAttributeFactory[[]byte](unix.NL80211_ATTR_STA_SUPPORTED_RATES)(encodeRates(supportedRates, nil)),
+		NewAttributeFactory[uint16](unix.NL80211_ATTR_STA_LISTEN_INTERVAL)(meshPeerListenInterval),
+		NewAttributeFactory[uint8](unix.NL80211_ATTR_STA_PLINK_STATE)(unix.NL80211_PLINK_ESTAB),
+		stationFlagsAttribute(StationFlagAuthenticated, StationFlagAuthenticated),
+	}, nil
+}
+
+// parseMeshPeerCandidateEvent parses a NL80211_CMD_NEW_PEER_CANDIDATE
+// notification.
+func parseMeshPeerCandidateEvent(m genetlink.Message) (*MeshPeerCandidateEvent, error) {
+	attrs, err := netlink.UnmarshalAttributes(m.Data)
+	if err != nil { return nil, fmt.Errorf("parseMeshPeerCandidateEvent: %v", err) }
+
+	ev := &MeshPeerCandidateEvent{}
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_ATTR_IFINDEX:
+			ev.InterfaceIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_WIPHY:
+			ev.WiphyIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_MAC:
+			ev.HardwareAddr = net.HardwareAddr(a.Data)
+		case unix.NL80211_ATTR_RX_SIGNAL_DBM:
+			ev.Signal = int(nlenc.Int32(a.Data))
+		case unix.NL80211_ATTR_IE:
+			ev.IEs = a.Data
+		}
+	}
+	return ev, nil
+}
+
+// GetMeshConfig returns the parameters of the given mesh point interface.
+// Before the interface joins a mesh, these are the defaults JoinMesh would
+// use.
+func (c *Client) GetMeshConfig(w *WifiInterface) (*MeshConfig, error) {
+	if w.Type != InterfaceTypeMeshPoint { return nil, fmt.Errorf("GetMeshConfig: %s is a %v interface, not a mesh point", w.Name, w.Type) }
+
+	attrs := []AttributeEncoder{InterfaceIndexAttribute(w.Index)}
+	msg, err := NewNl80211Message(unix.NL80211_CMD_GET_MESH_CONFIG, attrs)
+	if err != nil { return nil, fmt.Errorf("GetMeshConfig: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request,
+	}
+	response, err := request.Response(c)
+	if err != nil { return nil, fmt.Errorf("GetMeshConfig: %w", err) }
+
+	cfg, err := parseGetMeshConfigResponse(response)
+	if err != nil { return nil, fmt.Errorf("GetMeshConfig: %v", err) }
+	return cfg, nil
+}
+
+// parseGetMeshConfigResponse parses the response to a
+// NL80211_CMD_GET_MESH_CONFIG request.
+func parseGetMeshConfigResponse(msgs []genetlink.Message) (*MeshConfig, error) {
+	for _, m := range msgs {
+		attrs, err := netlink.UnmarshalAttributes(m.Data)
+		if err != nil { return nil, err }
+
+		for _, a := range attrs {
+			if a.Type&^unix.NLA_F_NESTED != unix.NL80211_ATTR_MESH_CONFIG { continue }
+
+			cfg := &MeshConfig{}
+			if err := cfg.parseAttributes(a.Data); err != nil { return nil, err }
+			return cfg, nil
+		}
+	}
+	return nil, fmt.Errorf("no mesh configuration in response")
+}
+
+// parseAttributes parses the NL80211_MESHCONF_* attributes nested in
+// NL80211_ATTR_MESH_CONFIG.
+func (cfg *MeshConfig) parseAttributes(b []byte) error {
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil { return err }
+
+	for _, a := range attrs {
+		if MeshParam(a.Type).size() != 0 { cfg.Params |= MeshParams(MeshParam(a.Type)) }
+		switch a.Type {
+		case unix.NL80211_MESHCONF_RETRY_TIMEOUT:
+			cfg.RetryTimeout = time.Duration(nlenc.Uint16(a.Data)) * time.Millisecond
+		case unix.NL80211_MESHCONF_CONFIRM_TIMEOUT:
+			cfg.ConfirmTimeout = time.Duration(nlenc.Uint16(a.Data)) * time.Millisecond
+		case unix.NL80211_MESHCONF_HOLDING_TIMEOUT:
+			cfg.HoldingTimeout = time.Duration(nlenc.Uint16(a.Data)) * time.Millisecond
+		case unix.NL80211_MESHCONF_MAX_PEER_LINKS:
+			cfg.MaxPeerLinks = int(nlenc.Uint16(a.Data))
+		case unix.NL80211_MESHCONF_MAX_RETRIES:
+			cfg.MaxRetries = int(nlenc.Uint8(a.Data))
+		case unix.NL80211_MESHCONF_TTL:
+			cfg.TTL = int(nlenc.Uint8(a.Data))
+		case unix.NL80211_MESHCONF_ELEMENT_TTL:
+			cfg.ElementTTL = int(nlenc.Uint8(a.Data))
+		case unix.NL80211_MESHCONF_AUTO_OPEN_PLINKS:
+			cfg.AutoOpenPeerLinks = nlenc.Uint8(a.Data) != 0
+		case unix.NL80211_MESHCONF_SYNC_OFFSET_MAX_NEIGHBOR:
+			cfg.SyncOffsetMaxNeighbor = int(nlenc.Uint32(a.Data))
+		case unix.NL80211_MESHCONF_PATH_REFRESH_TIME:
+			cfg.PathRefreshTime = time.Duration(nlenc.Uint32(a.Data)) * time.Millisecond
+		case unix.NL80211_MESHCONF_MIN_DISCOVERY_TIMEOUT:
+			cfg.MinDiscoveryTimeout = time.Duration(nlenc.Uint16(a.Data)) * time.Millisecond
+		case unix.NL80211_MESHCONF_HWMP_MAX_PREQ_RETRIES:
+			cfg.HWMPMaxPREQRetries = int(nlenc.Uint8(a.Data))
+		case unix.NL80211_MESHCONF_HWMP_ACTIVE_PATH_TIMEOUT:
+			cfg.HWMPActivePathTimeout = time.Duration(nlenc.Uint32(a.Data)) * tu
+		case unix.NL80211_MESHCONF_HWMP_PREQ_MIN_INTERVAL:
+			cfg.HWMPPREQMinInterval = time.Duration(nlenc.Uint16(a.Data)) * tu
+		case unix.NL80211_MESHCONF_HWMP_PERR_MIN_INTERVAL:
+			cfg.HWMPPERRMinInterval = time.Duration(nlenc.Uint16(a.Data)) * tu
+		case unix.NL80211_MESHCONF_HWMP_NET_DIAM_TRVS_TIME:
+			cfg.HWMPNetDiameterTraversalTime = time.Duration(nlenc.Uint16(a.Data)) * tu
+		case unix.NL80211_MESHCONF_HWMP_ROOTMODE:
+			cfg.HWMPRootMode = int(nlenc.Uint8(a.Data))
+		case unix.NL80211_MESHCONF_HWMP_RANN_INTERVAL:
+			cfg.HWMPRANNInterval = time.Duration(nlenc.Uint16(a.Data)) * tu
+		case unix.NL80211_MESHCONF_HWMP_PATH_TO_ROOT_TIMEOUT:
+			cfg.HWMPPathToRootTimeout = time.Duration(nlenc.Uint32(a.Data)) * tu
+		case unix.NL80211_MESHCONF_HWMP_ROOT_INTERVAL:
+			cfg.HWMPRootInterval = time.Duration(nlenc.Uint16(a.Data)) * tu
+		case unix.NL80211_MESHCONF_HWMP_CONFIRMATION_INTERVAL:
+			cfg.HWMPConfirmationInterval = time.Duration(nlenc.Uint16(a.Data)) * tu
+		case unix.NL80211_MESHCONF_GATE_ANNOUNCEMENTS:
+			cfg.GateAnnouncements = nlenc.Uint8(a.Data) != 0
+		case unix.NL80211_MESHCONF_FORWARDING:
+			cfg.Forwarding = nlenc.Uint8(a.Data) != 0
+		case unix.NL80211_MESHCONF_RSSI_THRESHOLD:
+			cfg.RSSIThreshold = int(nlenc.Int32(a.Data))
+		case unix.NL80211_MESHCONF_HT_OPMODE:
+			cfg.HTOperationMode = int(nlenc.Uint16(a.Data))
+		case unix.NL80211_MESHCONF_POWER_MODE:
+			cfg.PowerMode = int(nlenc.Uint32(a.Data))
+		case unix.NL80211_MESHCONF_AWAKE_WINDOW:
+			cfg.AwakeWindow = time.Duration(nlenc.Uint16(a.Data)) * tu
+		case unix.NL80211_MESHCONF_PLINK_TIMEOUT:
+			cfg.PeerLinkTimeout = time.Duration(nlenc.Uint32(a.Data)) * time.Second
+		case unix.NL80211_MESHCONF_CONNECTED_TO_GATE:
+			cfg.ConnectedToGate = nlenc.Uint8(a.Data) != 0
+		case unix.NL80211_MESHCONF_CONNECTED_TO_AS:
+			cfg.ConnectedToAS = nlenc.Uint8(a.Data) != 0
+		case unix.NL80211_MESHCONF_NOLEARN:
+			cfg.NoLearn = nlenc.Uint8(a.Data) != 0
+		}
+	}
+	return nil
+}
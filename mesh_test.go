@@ -0,0 +1,222 @@
+package wifi_test
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bryancoxwell/wifi"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// TestParseGetMeshConfigResponse tests the parsing of mesh parameters and
+// the conversion of their units.
+func TestParseGetMeshConfigResponse(t *testing.T) {
+	msgs := []genetlink.Message{{
+		Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_ATTR_IFINDEX, Data: nlenc.Uint32Bytes(3)},
+			{Type: unix.NL80211_ATTR_MESH_CONFIG, Data: mustMarshalAttributes(t, []netlink.Attribute{
+				{Type: unix.NL80211_MESHCONF_RETRY_TIMEOUT, Data: nlenc.Uint16Bytes(100)},
+				{Type: unix.NL80211_MESHCONF_MAX_PEER_LINKS, Data: nlenc.Uint16Bytes(99)},
+				{Type: unix.NL80211_MESHCONF_TTL, Data: []byte{31}},
+				{Type: unix.NL80211_MESHCONF_AUTO_OPEN_PLINKS, Data: []byte{1}},
+				{Type: unix.NL80211_MESHCONF_HWMP_ACTIVE_PATH_TIMEOUT, Data: nlenc.Uint32Bytes(5000)},
+				{Type: unix.NL80211_MESHCONF_HWMP_ROOTMODE, Data: []byte{0}},
+				{Type: unix.NL80211_MESHCONF_FORWARDING, Data: []byte{1}},
+				{Type: unix.NL80211_MESHCONF_RSSI_THRESHOLD, Data: nlenc.Int32Bytes(-80)},
+				{Type: unix.NL80211_MESHCONF_PLINK_TIMEOUT, Data: nlenc.Uint32Bytes(1800)},
+			})},
+		}),
+	}}
+	cfg, err := wifi.ParseGetMeshConfigResponse(msgs)
+	if err != nil {
+		t.Fatalf("ParseGetMeshConfigResponse: %v", err)
+	}
+	expected := &wifi.MeshConfig{
+		Params: wifi.MeshParams(wifi.MeshParamRetryTimeout, wifi.MeshParamMaxPeerLinks, wifi.MeshParamTTL,
+			wifi.MeshParamAutoOpenPeerLinks, wifi.MeshParamHWMPActivePathTimeout, wifi.MeshParamHWMPRootMode,
+			wifi.MeshParamForwarding, wifi.MeshParamRSSIThreshold, wifi.MeshParamPeerLinkTimeout),
+		RetryTimeout:          100 * time.Millisecond,
+		MaxPeerLinks:          99,
+		TTL:                   31,
+		AutoOpenPeerLinks:     true,
+		HWMPActivePathTimeout: 5000 * 1024 * time.Microsecond,
+		Forwarding:            true,
+		RSSIThreshold:         -80,
+		PeerLinkTimeout:       30 * time.Minute,
+	}
+	if !reflect.DeepEqual(expected, cfg) {
+		t.Fatalf("ParseGetMeshConfigResponse mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", expected, cfg)
+	}
+
+	if _, err := wifi.ParseGetMeshConfigResponse(nil); err == nil {
+		t.Error("expected an error for a response without a mesh configuration")
+	}
+}
+
+// TestGetMeshConfigNotMesh tests that GetMeshConfig rejects interfaces that
+// aren't mesh points.
+func TestGetMeshConfigNotMesh(t *testing.T) {
+	w := &wifi.WifiInterface{Index: 3, Name: "wlan0", Type: wifi.InterfaceTypeStation}
+	if _, err := (&wifi.Client{}).GetMeshConfig(w); err == nil {
+		t.Error("expected an error for a station interface")
+	}
+}
+
+// TestJoinMeshAttrs tests that the mesh configuration sent when joining a
+// mesh parses back to the same configuration.
+func TestJoinMeshAttrs(t *testing.T) {
+	w := &wifi.WifiInterface{Index: 3, Name: "mesh0", Type: wifi.InterfaceTypeMeshPoint}
+	cfg := &wifi.MeshConfig{
+		Params: wifi.MeshParams(wifi.MeshParamRetryTimeout, wifi.MeshParamConfirmTimeout, wifi.MeshParamHoldingTimeout,
+			wifi.MeshParamMaxPeerLinks, wifi.MeshParamMaxRetries, wifi.MeshParamTTL, wifi.MeshParamElementTTL,
+			wifi.MeshParamAutoOpenPeerLinks, wifi.MeshParamHWMPActivePathTimeout, wifi.MeshParamHWMPRootMode,
+			wifi.MeshParamForwarding, wifi.MeshParamRSSIThreshold, wifi.MeshParamPowerMode,
+			wifi.MeshParamPeerLinkTimeout),
+		RetryTimeout:          100 * time.Millisecond,
+		ConfirmTimeout:        100 * time.Millisecond,
+		HoldingTimeout:        100 * time.Millisecond,
+		MaxPeerLinks:          99,
+		MaxRetries:            3,
+		TTL:                   31,
+		ElementTTL:            31,
+		AutoOpenPeerLinks:     true,
+		HWMPActivePathTimeout: 5000 * 1024 * time.Microsecond,
+		HWMPRootMode:          2,
+		Forwarding:            true,
+		RSSIThreshold:         -80,
+		PowerMode:             unix.NL80211_MESH_POWER_ACTIVE,
+		PeerLinkTimeout:       30 * time.Minute,
+	}
+	encoders, err := wifi.JoinMeshAttrs(w, "backhaul", 5180, cfg)
+	if err != nil {
+		t.Fatalf("JoinMeshAttrs: %v", err)
+	}
+	attrs := encodeAttributes(t, encoders)
+	if got := string(attrs[unix.NL80211_ATTR_MESH_ID]); got != "backhaul" {
+		t.Errorf("unexpected mesh ID %q", got)
+	}
+	if got := nlenc.Uint32(attrs[unix.NL80211_ATTR_WIPHY_FREQ]); got != 5180 {
+		t.Errorf("unexpected frequency %d", got)
+	}
+
+	msgs := []genetlink.Message{{
+		Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_ATTR_MESH_CONFIG, Data: attrs[unix.NL80211_ATTR_MESH_CONFIG]},
+		}),
+	}}
+	parsed, err := wifi.ParseGetMeshConfigResponse(msgs)
+	if err != nil {
+		t.Fatalf("ParseGetMeshConfigResponse: %v", err)
+	}
+	if !reflect.DeepEqual(cfg, parsed) {
+		t.Fatalf("mesh configuration mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", cfg, parsed)
+	}
+
+	// The zero configuration keeps the kernel's defaults.
+	encoders, err = wifi.JoinMeshAttrs(w, "backhaul", 5180, &wifi.MeshConfig{})
+	if err != nil {
+		t.Fatalf("JoinMeshAttrs: %v", err)
+	}
+	if _, ok := encodeAttributes(t, encoders)[unix.NL80211_ATTR_MESH_CONFIG]; ok {
+		t.Error("unexpected mesh configuration for the zero MeshConfig")
+	}
+
+	if _, err := wifi.JoinMeshAttrs(w, "backhaul", 5180, &wifi.MeshConfig{Params: wifi.MeshParams(wifi.MeshParamTTL), TTL: 256}); err == nil {
+		t.Error("expected an error for an out of range TTL")
+	}
+	if _, err := wifi.JoinMeshAttrs(w, "", 5180, &wifi.MeshConfig{}); err == nil {
+		t.Error("expected an error for an empty mesh ID")
+	}
+}
+
+// TestJoinMeshSingleParam tests that only the parameters in
+// MeshConfig.Params are sent, even when their value is zero, and that a
+// parameter set outside of Params is reported rather than dropped.
+func TestJoinMeshSingleParam(t *testing.T) {
+	w := &wifi.WifiInterface{Index: 3, Name: "mesh0", Type: wifi.InterfaceTypeMeshPoint}
+	cfg := &wifi.MeshConfig{Params: wifi.MeshParams(wifi.MeshParamForwarding)}
+	encoders, err := wifi.JoinMeshAttrs(w, "backhaul", 5180, cfg)
+	if err != nil {
+		t.Fatalf("JoinMeshAttrs: %v", err)
+	}
+	nested := decodeNested(t, encodeAttributes(t, encoders)[unix.NL80211_ATTR_MESH_CONFIG])
+	if len(nested) != 1 {
+		t.Fatalf("expected 1 mesh parameter, got %d", len(nested))
+	}
+	if got, ok := nested[unix.NL80211_MESHCONF_FORWARDING]; !ok || !bytes.Equal(got, []byte{0}) {
+		t.Errorf("unexpected NL80211_MESHCONF_FORWARDING %v", got)
+	}
+
+	cfg = &wifi.MeshConfig{Params: wifi.MeshParams(wifi.MeshParamTTL), TTL: 31, Forwarding: true}
+	if _, err := wifi.JoinMeshAttrs(w, "backhaul", 5180, cfg); err == nil {
+		t.Error("expected an error for Forwarding set outside of Params")
+	}
+	if _, err := wifi.JoinMeshAttrs(w, "backhaul", 5180, &wifi.MeshConfig{Forwarding: true}); err == nil {
+		t.Error("expected an error for Forwarding set without Params")
+	}
+}
+
+// TestJoinMeshConfigOptional tests that JoinMesh takes at most one mesh
+// configuration, checking its arguments before sending anything.
+func TestJoinMeshConfigOptional(t *testing.T) {
+	w := &wifi.WifiInterface{Index: 3, Name: "wlan0", Type: wifi.InterfaceTypeStation}
+	if err := (&wifi.Client{}).JoinMesh(w, "backhaul", 5180); err == nil {
+		t.Error("expected an error for a station interface")
+	}
+
+	w.Type = wifi.InterfaceTypeMeshPoint
+	if err := (&wifi.Client{}).JoinMesh(w, "backhaul", 5180, wifi.MeshConfig{}, wifi.MeshConfig{}); err == nil {
+		t.Error("expected an error for two mesh configurations")
+	}
+}
+
+// TestJoinMeshUserspacePeering tests that userspace peering is requested in
+// the mesh setup without sending a mesh configuration.
+func TestJoinMeshUserspacePeering(t *testing.T) {
+	w := &wifi.WifiInterface{Index: 3, Name: "mesh0", Type: wifi.InterfaceTypeMeshPoint}
+	encoders, err := wifi.JoinMeshAttrs(w, "backhaul", 5180, &wifi.MeshConfig{UserspacePeering: true})
+	if err != nil {
+		t.Fatalf("JoinMeshAttrs: %v", err)
+	}
+	attrs := encodeAttributes(t, encoders)
+	if _, ok := attrs[unix.NL80211_ATTR_MESH_CONFIG]; ok {
+		t.Error("unexpected mesh configuration")
+	}
+	if _, ok := decodeNested(t, attrs[unix.NL80211_ATTR_MESH_SETUP])[unix.NL80211_MESH_SETUP_USERSPACE_MPM]; !ok {
+		t.Error("missing NL80211_MESH_SETUP_USERSPACE_MPM")
+	}
+}
+
+// TestMeshPeerAttrs tests the attributes of a request adding a mesh peer.
+func TestMeshPeerAttrs(t *testing.T) {
+	w := &wifi.WifiInterface{Index: 3, Name: "mesh0", Type: wifi.InterfaceTypeMeshPoint}
+	mac := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x01, 0x00}
+
+	encoders, err := wifi.MeshPeerAttrs(w, mac, 1, []float64{6, 12, 24})
+	if err != nil {
+		t.Fatalf("MeshPeerAttrs: %v", err)
+	}
+	attrs := encodeAttributes(t, encoders)
+	if got := nlenc.Uint16(attrs[unix.NL80211_ATTR_STA_AID]); got != 1 {
+		t.Errorf("unexpected AID %d", got)
+	}
+	if got := attrs[unix.NL80211_ATTR_STA_SUPPORTED_RATES]; !bytes.Equal(got, []byte{12, 24, 48}) {
+		t.Errorf("unexpected supported rates %v", got)
+	}
+	if got := attrs[unix.NL80211_ATTR_STA_PLINK_STATE]; len(got) != 1 || got[0] != unix.NL80211_PLINK_ESTAB {
+		t.Errorf("unexpected peer link state %v", got)
+	}
+
+	if _, err := wifi.MeshPeerAttrs(w, mac, 0, []float64{6}); err == nil {
+		t.Error("expected an error for an invalid AID")
+	}
+	if _, err := wifi.MeshPeerAttrs(w, mac, 1, nil); err == nil {
+		t.Error("expected an error without supported rates")
+	}
+}
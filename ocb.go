@@ -0,0 +1,70 @@
+//go:build linux
+// +build linux
+
+package wifi
+
+import (
+	"fmt"
+
+	"github.com/mdlayher/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// The 5.9 GHz band used by ITS (802.11p) in MHz. Parts of it have no
+// channel number in the 5 GHz band.
+const (
+	itsBandStart = 5850
+	itsBandEnd   = 5925
+)
+
+// JoinOCB starts communicating outside the context of a BSS (OCB), as used
+// by 802.11p, on the given OCB interface. The channel has its primary
+// frequency freq in MHz and is 5, 10 or 20 MHz wide.
+func (c *Client) JoinOCB(w *WifiInterface, freq int, width ChannelWidth) error {
+	attrs, err := ocbAttrs(w, freq, width)
+	if err != nil { return fmt.Errorf("JoinOCB: %v", err) }
+
+	msg, err := NewNl80211Message(unix.NL80211_CMD_JOIN_OCB, attrs)
+	if err != nil { return fmt.Errorf("JoinOCB: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("JoinOCB: %w", err) }
+	return nil
+}
+
+// LeaveOCB stops OCB communication on the given interface.
+func (c *Client) LeaveOCB(w *WifiInterface) error {
+	attrs := []AttributeEncoder{InterfaceIndexAttribute(w.Index)}
+	msg, err := NewNl80211Message(unix.NL80211_CMD_LEAVE_OCB, attrs)
+	if err != nil { return fmt.Errorf("LeaveOCB: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("LeaveOCB: %w", err) }
+	return nil
+}
+
+// ocbAttrs returns the NL80211_CMD_JOIN_OCB attributes for the channel
+// with primary frequency freq and the given width.
+func ocbAttrs(w *WifiInterface, freq int, width ChannelWidth) ([]AttributeEncoder, error) {
+	if w.Type != InterfaceTypeOCB { return nil, fmt.Errorf("%s is a %v interface, not OCB", w.Name, w.Type) }
+	switch width {
+	case ChannelWidth5, ChannelWidth10, ChannelWidth20, ChannelWidth20NoHT:
+	default:
+		return nil, fmt.Errorf("unsupported OCB channel width %v", width)
+	}
+	if _, _, err := FrequencyToChannel(freq); err != nil && (freq < itsBandStart || freq > itsBandEnd || freq%5 != 0) {
+		return nil, fmt.Errorf("invalid frequency %d MHz", freq)
+	}
+
+	def := ChannelDefinition{Frequency: uint32(freq), Width: width}
+	attrs := []AttributeEncoder{InterfaceIndexAttribute(w.Index)}
+	return append(attrs, channelWidthEncoder(&def)...), nil
+}
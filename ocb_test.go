@@ -0,0 +1,44 @@
+package wifi_test
+
+import (
+	"testing"
+
+	"github.com/bryancoxwell/wifi"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// TestOCBAttrs tests the channel attributes of an OCB join, and that
+// frequencies of the 5.9 GHz ITS band are accepted.
+func TestOCBAttrs(t *testing.T) {
+	w := &wifi.WifiInterface{Index: 3, Name: "ocb0", Type: wifi.InterfaceTypeOCB}
+
+	encoders, err := wifi.OCBAttrs(w, 5920, wifi.ChannelWidth10)
+	if err != nil {
+		t.Fatalf("OCBAttrs: %v", err)
+	}
+	attrs := encodeAttributes(t, encoders)
+	if got := nlenc.Uint32(attrs[unix.NL80211_ATTR_WIPHY_FREQ]); got != 5920 {
+		t.Errorf("unexpected frequency %d", got)
+	}
+	if got := nlenc.Uint32(attrs[unix.NL80211_ATTR_CHANNEL_WIDTH]); got != unix.NL80211_CHAN_WIDTH_10 {
+		t.Errorf("unexpected channel width %d", got)
+	}
+	if got := nlenc.Uint32(attrs[unix.NL80211_ATTR_CENTER_FREQ1]); got != 5920 {
+		t.Errorf("unexpected center frequency %d", got)
+	}
+
+	if _, err := wifi.OCBAttrs(w, 5180, wifi.ChannelWidth20); err != nil {
+		t.Errorf("OCBAttrs: unexpected error for 5180 MHz: %v", err)
+	}
+	if _, err := wifi.OCBAttrs(w, 5927, wifi.ChannelWidth10); err == nil {
+		t.Error("expected an error for an invalid frequency")
+	}
+	if _, err := wifi.OCBAttrs(w, 5900, wifi.ChannelWidth40); err == nil {
+		t.Error("expected an error for a 40 MHz channel")
+	}
+	station := &wifi.WifiInterface{Index: 3, Name: "wlan0", Type: wifi.InterfaceTypeStation}
+	if _, err := wifi.OCBAttrs(station, 5900, wifi.ChannelWidth10); err == nil {
+		t.Error("expected an error for a station interface")
+	}
+}
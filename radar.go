@@ -0,0 +1,87 @@
+//go:build linux
+// +build linux
+
+package wifi
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// A RadarEventType is the DFS state change reported by a RadarEvent.
+type RadarEventType int
+
+const (
+	// RadarDetected reports radar on the channel, which must be vacated.
+	RadarDetected RadarEventType = unix.NL80211_RADAR_DETECTED
+	// RadarCACFinished reports a channel availability check completing
+	// without radar, so the channel may be used.
+	RadarCACFinished RadarEventType = unix.NL80211_RADAR_CAC_FINISHED
+	// RadarCACAborted reports a channel availability check being cancelled.
+	RadarCACAborted RadarEventType = unix.NL80211_RADAR_CAC_ABORTED
+	// RadarNOPFinished reports the non-occupancy period of a channel on
+	// which radar was detected having expired.
+	RadarNOPFinished RadarEventType = unix.NL80211_RADAR_NOP_FINISHED
+	// RadarPreCACExpired reports the result of an earlier channel
+	// availability check no longer being valid.
+	RadarPreCACExpired RadarEventType = unix.NL80211_RADAR_PRE_CAC_EXPIRED
+	// RadarCACStarted reports a channel availability check starting.
+	RadarCACStarted RadarEventType = unix.NL80211_RADAR_CAC_STARTED
+)
+
+// String returns the string representation of a RadarEventType.
+func (t RadarEventType) String() string {
+	switch t {
+	case RadarDetected:
+		return "radar detected"
+	case RadarCACFinished:
+		return "CAC finished"
+	case RadarCACAborted:
+		return "CAC aborted"
+	case RadarNOPFinished:
+		return "NOP finished"
+	case RadarPreCACExpired:
+		return "pre-CAC expired"
+	case RadarCACStarted:
+		return "CAC started"
+	default:
+		return fmt.Sprintf("unknown(%d)", t)
+	}
+}
+
+// A RadarEvent reports a DFS state change on a channel, sent by the kernel
+// on the "mlme" multicast group. An AP receiving RadarDetected must leave
+// the channel, for instance with SwitchChannel.
+type RadarEvent struct {
+	Type           RadarEventType
+	InterfaceIndex uint32
+	WiphyIndex     uint32
+
+	// Channel is the channel the event applies to.
+	Channel ChannelDefinition
+}
+
+// parseRadarEvent parses a NL80211_CMD_RADAR_DETECT notification.
+func parseRadarEvent(m genetlink.Message) (*RadarEvent, error) {
+	attrs, err := netlink.UnmarshalAttributes(m.Data)
+	if err != nil { return nil, fmt.Errorf("parseRadarEvent: %v", err) }
+
+	ev := &RadarEvent{Type: -1, Channel: parseChannelDefinition(attrs)}
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_ATTR_IFINDEX:
+			ev.InterfaceIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_WIPHY:
+			ev.WiphyIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_RADAR_EVENT:
+			ev.Type = RadarEventType(nlenc.Uint32(a.Data))
+		}
+	}
+	if ev.Type < 0 { return nil, errors.New("parseRadarEvent: missing radar event type") }
+	return ev, nil
+}
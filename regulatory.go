@@ -0,0 +1,191 @@
+//go:build linux
+// +build linux
+
+package wifi
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+var (
+	// ErrRegulatoryInProgress is returned by SetRegulatoryDomain when the
+	// kernel is still processing an earlier regulatory request.
+	ErrRegulatoryInProgress = errors.New("regulatory request already in progress")
+
+	// ErrRegulatoryAlreadySet is returned by SetRegulatoryDomain when the
+	// requested regulatory domain is already in effect.
+	ErrRegulatoryAlreadySet = errors.New("regulatory domain already set")
+)
+
+// SetRegulatoryDomain hints to the kernel that the system is in the country
+// with the given ISO 3166-1 alpha2 code, or "00" for the world regulatory
+// domain. This is only a hint: the kernel may ignore it, for instance when a
+// driver has locked the device to the country it was programmed for, and
+// the resulting domain is the intersection of the hint with any others the
+// kernel has received.
+func (c *Client) SetRegulatoryDomain(alpha2 string) error {
+	if !validAlpha2(alpha2) {
+		return fmt.Errorf("SetRegulatoryDomain: invalid alpha2 %q", alpha2)
+	}
+
+	attrs := []AttributeEncoder{
+		RegulatoryAlpha2Attribute(alpha2),
+	}
+	msg, err := NewNl80211Message(unix.NL80211_CMD_REQ_SET_REG, attrs)
+	if err != nil { return fmt.Errorf("SetRegulatoryDomain: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+
+	_, err = request.Response(c)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, unix.EINPROGRESS):
+		return fmt.Errorf("SetRegulatoryDomain: %w", ErrRegulatoryInProgress)
+	case errors.Is(err, unix.EALREADY):
+		return fmt.Errorf("SetRegulatoryDomain: %w", ErrRegulatoryAlreadySet)
+	default:
+		return fmt.Errorf("SetRegulatoryDomain: %w", err)
+	}
+}
+
+// validAlpha2 reports whether s is two uppercase ASCII letters or "00".
+func validAlpha2(s string) bool {
+	if s == "00" { return true }
+	if len(s) != 2 { return false }
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' { return false }
+	}
+	return true
+}
+
+// A RegInitiator is the source of a regulatory domain change.
+type RegInitiator int
+
+const (
+	RegInitiatorCore      RegInitiator = unix.NL80211_REGDOM_SET_BY_CORE
+	RegInitiatorUser      RegInitiator = unix.NL80211_REGDOM_SET_BY_USER
+	RegInitiatorDriver    RegInitiator = unix.NL80211_REGDOM_SET_BY_DRIVER
+	RegInitiatorCountryIE RegInitiator = unix.NL80211_REGDOM_SET_BY_COUNTRY_IE
+)
+
+// String returns the string representation of a RegInitiator.
+func (i RegInitiator) String() string {
+	switch i {
+	case RegInitiatorCore:
+		return "core"
+	case RegInitiatorUser:
+		return "user"
+	case RegInitiatorDriver:
+		return "driver"
+	case RegInitiatorCountryIE:
+		return "country IE"
+	default:
+		return fmt.Sprintf("unknown(%d)", i)
+	}
+}
+
+// A RegDomainType is the kind of a regulatory domain.
+type RegDomainType int
+
+const (
+	RegDomainCountry      RegDomainType = unix.NL80211_REGDOM_TYPE_COUNTRY
+	RegDomainWorld        RegDomainType = unix.NL80211_REGDOM_TYPE_WORLD
+	RegDomainCustomWorld  RegDomainType = unix.NL80211_REGDOM_TYPE_CUSTOM_WORLD
+	RegDomainIntersection RegDomainType = unix.NL80211_REGDOM_TYPE_INTERSECTION
+)
+
+// String returns the string representation of a RegDomainType.
+func (t RegDomainType) String() string {
+	switch t {
+	case RegDomainCountry:
+		return "country"
+	case RegDomainWorld:
+		return "world"
+	case RegDomainCustomWorld:
+		return "custom world"
+	case RegDomainIntersection:
+		return "intersection"
+	default:
+		return fmt.Sprintf("unknown(%d)", t)
+	}
+}
+
+// A RegChangeEvent reports that a regulatory domain took effect. It doesn't
+// say which channels changed; re-read the Bands of the affected Wiphys to
+// find out.
+type RegChangeEvent struct {
+	Initiator RegInitiator
+	Type      RegDomainType
+
+	// Alpha2 is the country code of a RegDomainCountry domain.
+	Alpha2 string
+
+	// WiphyIndex is the wiphy whose self-managed domain changed, when
+	// Global is false. Global changes apply to every wiphy.
+	WiphyIndex uint32
+	Global     bool
+}
+
+// A BeaconHintEvent reports that a beacon received on a frequency lifted
+// some of the restrictions on it, typically the no-IR flag.
+type BeaconHintEvent struct {
+	WiphyIndex uint32
+
+	// Before and After are the frequency before and after the hint.
+	Before BandFrequency
+	After  BandFrequency
+}
+
+// parseRegChangeEvent parses a NL80211_CMD_REG_CHANGE or
+// NL80211_CMD_WIPHY_REG_CHANGE notification.
+func parseRegChangeEvent(m genetlink.Message) (*RegChangeEvent, error) {
+	attrs, err := netlink.UnmarshalAttributes(m.Data)
+	if err != nil { return nil, fmt.Errorf("parseRegChangeEvent: %v", err) }
+
+	ev := &RegChangeEvent{Global: true}
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_ATTR_REG_INITIATOR:
+			ev.Initiator = RegInitiator(nlenc.Uint8(a.Data))
+		case unix.NL80211_ATTR_REG_TYPE:
+			ev.Type = RegDomainType(nlenc.Uint8(a.Data))
+		case unix.NL80211_ATTR_REG_ALPHA2:
+			ev.Alpha2 = nlenc.String(a.Data)
+		case unix.NL80211_ATTR_WIPHY:
+			ev.WiphyIndex = nlenc.Uint32(a.Data)
+			ev.Global = false
+		}
+	}
+	return ev, nil
+}
+
+// parseBeaconHintEvent parses a NL80211_CMD_REG_BEACON_HINT notification.
+func parseBeaconHintEvent(m genetlink.Message) (*BeaconHintEvent, error) {
+	attrs, err := netlink.UnmarshalAttributes(m.Data)
+	if err != nil { return nil, fmt.Errorf("parseBeaconHintEvent: %v", err) }
+
+	ev := &BeaconHintEvent{}
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_ATTR_WIPHY:
+			ev.WiphyIndex = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_FREQ_BEFORE:
+			ev.Before, err = parseBandFrequency(a.Data)
+			if err != nil { return nil, fmt.Errorf("parseBeaconHintEvent: %v", err) }
+		case unix.NL80211_ATTR_FREQ_AFTER:
+			ev.After, err = parseBandFrequency(a.Data)
+			if err != nil { return nil, fmt.Errorf("parseBeaconHintEvent: %v", err) }
+		}
+	}
+	return ev, nil
+}
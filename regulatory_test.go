@@ -0,0 +1,116 @@
+package wifi_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bryancoxwell/wifi"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// TestValidAlpha2 tests the validation of regulatory domain country codes.
+func TestValidAlpha2(t *testing.T) {
+	tests := map[string]bool{
+		"US":  true,
+		"DE":  true,
+		"00":  true,
+		"us":  false,
+		"U":   false,
+		"USA": false,
+		"0A":  false,
+		"":    false,
+	}
+	for alpha2, expected := range tests {
+		if got := wifi.ValidAlpha2(alpha2); got != expected {
+			t.Errorf("ValidAlpha2(%q) = %v, expected %v", alpha2, got, expected)
+		}
+	}
+}
+
+// TestParseRegulatoryEvents tests the parsing of regulatory domain change
+// and beacon hint notifications.
+func TestParseRegulatoryEvents(t *testing.T) {
+	freq := func(attrs ...netlink.Attribute) []byte {
+		attrs = append([]netlink.Attribute{
+			{Type: unix.NL80211_FREQUENCY_ATTR_FREQ, Data: nlenc.Uint32Bytes(5260)},
+		}, attrs...)
+		return mustMarshalAttributes(t, attrs)
+	}
+
+	tests := []struct {
+		name     string
+		cmd      uint8
+		attrs    []netlink.Attribute
+		expected wifi.Event
+	}{
+		{
+			name: "global country change",
+			cmd:  unix.NL80211_CMD_REG_CHANGE,
+			attrs: []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_REG_INITIATOR, Data: []byte{unix.NL80211_REGDOM_SET_BY_USER}},
+				{Type: unix.NL80211_ATTR_REG_TYPE, Data: []byte{unix.NL80211_REGDOM_TYPE_COUNTRY}},
+				{Type: unix.NL80211_ATTR_REG_ALPHA2, Data: nlenc.Bytes("DE")},
+			},
+			expected: wifi.RegChangeEvent{
+				Initiator: wifi.RegInitiatorUser,
+				Type:      wifi.RegDomainCountry,
+				Alpha2:    "DE",
+				Global:    true,
+			},
+		},
+		{
+			name: "self-managed wiphy change",
+			cmd:  unix.NL80211_CMD_WIPHY_REG_CHANGE,
+			attrs: []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_REG_INITIATOR, Data: []byte{unix.NL80211_REGDOM_SET_BY_DRIVER}},
+				{Type: unix.NL80211_ATTR_REG_TYPE, Data: []byte{unix.NL80211_REGDOM_TYPE_WORLD}},
+				{Type: unix.NL80211_ATTR_WIPHY, Data: nlenc.Uint32Bytes(1)},
+			},
+			expected: wifi.RegChangeEvent{
+				Initiator:  wifi.RegInitiatorDriver,
+				Type:       wifi.RegDomainWorld,
+				WiphyIndex: 1,
+			},
+		},
+		{
+			name: "beacon hint",
+			cmd:  unix.NL80211_CMD_REG_BEACON_HINT,
+			attrs: []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_WIPHY, Data: nlenc.Uint32Bytes(0)},
+				{Type: unix.NL80211_ATTR_FREQ_BEFORE, Data: freq(
+					netlink.Attribute{Type: unix.NL80211_FREQUENCY_ATTR_NO_IR},
+					netlink.Attribute{Type: unix.NL80211_FREQUENCY_ATTR_RADAR},
+				)},
+				{Type: unix.NL80211_ATTR_FREQ_AFTER, Data: freq(
+					netlink.Attribute{Type: unix.NL80211_FREQUENCY_ATTR_RADAR},
+				)},
+			},
+			expected: wifi.BeaconHintEvent{
+				Before: wifi.BandFrequency{Frequency: 5260, NoIR: true, Radar: true},
+				After:  wifi.BandFrequency{Frequency: 5260, Radar: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := genetlink.Message{
+				Header: genetlink.Header{Command: tt.cmd},
+				Data:   mustMarshalAttributes(t, tt.attrs),
+			}
+			ev, ok := wifi.ParseEvent(m)
+			if !ok {
+				t.Fatalf("ParseEvent: event not recognized")
+			}
+			if !reflect.DeepEqual(tt.expected, ev) {
+				t.Fatalf("ParseEvent mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", tt.expected, ev)
+			}
+		})
+	}
+
+	if _, ok := wifi.ParseEvent(genetlink.Message{Header: genetlink.Header{Command: unix.NL80211_CMD_GET_WIPHY}}); ok {
+		t.Errorf("ParseEvent: unexpected event for NL80211_CMD_GET_WIPHY")
+	}
+}
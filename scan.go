@@ -0,0 +1,166 @@
+//go:build linux
+// +build linux
+
+package wifi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// scanTimeout bounds how long Scan waits for the kernel to report the end
+// of a scan.
+const scanTimeout = 15 * time.Second
+
+// scanRetryDelay is the delay before ScanWithRetry first retries a scan,
+// doubling with every further retry up to scanRetryMaxDelay.
+const (
+	scanRetryDelay    = 250 * time.Millisecond
+	scanRetryMaxDelay = 4 * time.Second
+)
+
+// ErrScanAborted is returned when the kernel aborts a scan, for instance
+// because the interface went down.
+var ErrScanAborted = errors.New("scan aborted")
+
+// TriggerScan starts a scan on the given interface. When SSIDs are given,
+// the scan sends directed probe requests for them, which is needed to find
+// networks that hide their SSID; otherwise a wildcard probe is sent.
+// TriggerScan returns as soon as the scan has started.
+func (c *Client) TriggerScan(w *WifiInterface, ssids ...string) error {
+	attrs := []AttributeEncoder{
+		InterfaceIndexAttribute(w.Index),
+		ScanSSIDsAttribute(ssids...),
+	}
+	msg, err := NewNl80211Message(unix.NL80211_CMD_TRIGGER_SCAN, attrs)
+	if err != nil { return fmt.Errorf("TriggerScan: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("TriggerScan: %w", err) }
+	return nil
+}
+
+// Scan triggers a scan on the given interface, probing for the given SSIDs
+// as TriggerScan does, waits for it to complete and returns the scan
+// results. As with ScanResults, BSSs that can't be parsed are skipped and
+// reported in a *PartialResultsError.
+func (c *Client) Scan(w *WifiInterface, ssids ...string) ([]*BSS, error) {
+	// Join the "scan" group before triggering so that the completion
+	// event can't be missed.
+	conn, err := c.eventConn("scan")
+	if err != nil { return nil, fmt.Errorf("Scan: %v", err) }
+	defer c.closeEventConn(conn)
+
+	if err := c.TriggerScan(w, ssids...); err != nil { return nil, fmt.Errorf("Scan: %w", err) }
+
+	if err := conn.SetReadDeadline(time.Now().Add(scanTimeout)); err != nil { return nil, fmt.Errorf("Scan: %v", err) }
+	if err := waitForScan(conn, w); err != nil { return nil, fmt.Errorf("Scan: %w", err) }
+
+	bsss, err := c.ScanResults(w)
+	if err != nil { return bsss, fmt.Errorf("Scan: %w", err) }
+	return bsss, nil
+}
+
+// ScanWithRetry scans like Scan, but when the interface is already scanning,
+// for instance on behalf of NetworkManager, it backs off and retries up to
+// retries times before giving up. The delay starts at 250ms and doubles with
+// every retry, up to 4s.
+func (c *Client) ScanWithRetry(w *WifiInterface, retries int, ssids ...string) ([]*BSS, error) {
+	delay := scanRetryDelay
+	for attempt := 0; ; attempt++ {
+		bsss, err := c.Scan(w, ssids...)
+		if err == nil || !errors.Is(err, unix.EBUSY) || attempt >= retries {
+			return bsss, err
+		}
+
+		time.Sleep(delay)
+		delay = nextScanRetryDelay(delay)
+	}
+}
+
+// WaitForScanResults triggers a scan like TriggerScan and blocks until the
+// kernel reports its end on the given interface, or until ctx is done, and
+// returns the scan results. Like Scan, it joins the "scan" group before
+// triggering, so the end of the scan can't be missed. If the scan is
+// aborted, the error wraps ErrScanAborted.
+func (c *Client) WaitForScanResults(ctx context.Context, w *WifiInterface, ssids ...string) ([]*BSS, error) {
+	conn, err := c.eventConn("scan")
+	if err != nil { return nil, fmt.Errorf("WaitForScanResults: %v", err) }
+
+	// Closing the connection is the only way to interrupt Receive.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		c.closeEventConn(conn)
+	}()
+
+	if err := c.TriggerScan(w, ssids...); err != nil { return nil, fmt.Errorf("WaitForScanResults: %w", err) }
+
+	if err := waitForScan(conn, w); err != nil {
+		if ctx.Err() != nil { return nil, fmt.Errorf("WaitForScanResults: %w", ctx.Err()) }
+		return nil, fmt.Errorf("WaitForScanResults: %w", err)
+	}
+
+	bsss, err := c.ScanResults(w)
+	if err != nil { return bsss, fmt.Errorf("WaitForScanResults: %w", err) }
+	return bsss, nil
+}
+
+// nextScanRetryDelay returns the delay to wait before the retry following
+// one that waited d.
+func nextScanRetryDelay(d time.Duration) time.Duration {
+	d *= 2
+	if d > scanRetryMaxDelay {
+		return scanRetryMaxDelay
+	}
+	return d
+}
+
+// waitForScan waits for the NL80211_CMD_NEW_SCAN_RESULTS or
+// NL80211_CMD_SCAN_ABORTED event ending a scan on the given interface.
+func waitForScan(conn eventReceiver, w *WifiInterface) error {
+	for {
+		msgs, _, err := conn.Receive()
+		if err != nil { return err }
+
+		for _, m := range msgs {
+			cmd := m.Header.Command
+			if cmd != unix.NL80211_CMD_NEW_SCAN_RESULTS && cmd != unix.NL80211_CMD_SCAN_ABORTED {
+				continue
+			}
+			if scanEventInterface(m) != w.Index { continue }
+
+			if cmd == unix.NL80211_CMD_SCAN_ABORTED { return ErrScanAborted }
+			return nil
+		}
+	}
+}
+
+// scanEventInterface returns the interface index a scan event refers to.
+func scanEventInterface(m genetlink.Message) uint32 {
+	attrs, err := netlink.UnmarshalAttributes(m.Data)
+	if err != nil { return 0 }
+
+	for _, a := range attrs {
+		if a.Type == unix.NL80211_ATTR_IFINDEX {
+			return nlenc.Uint32(a.Data)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,69 @@
+package wifi_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bryancoxwell/wifi"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// TestNextScanRetryDelay tests the exponential backoff between scan
+// retries and its cap.
+func TestNextScanRetryDelay(t *testing.T) {
+	expected := []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second}
+
+	d := 250 * time.Millisecond
+	for i, e := range expected {
+		d = wifi.NextScanRetryDelay(d)
+		if d != e {
+			t.Errorf("retry %d: got delay %v, expected %v", i+1, d, e)
+		}
+	}
+}
+
+// TestWaitForScan tests that the end of a scan is picked out of the
+// notifications of other interfaces, and that aborted scans are reported.
+func TestWaitForScan(t *testing.T) {
+	w := &wifi.WifiInterface{Index: 3, Name: "wlan0"}
+	ifindex := func(i uint32) netlink.Attribute {
+		return netlink.Attribute{Type: unix.NL80211_ATTR_IFINDEX, Data: nlenc.Uint32Bytes(i)}
+	}
+
+	r := &eventReceiver{
+		{event(t, unix.NL80211_CMD_TRIGGER_SCAN, ifindex(3))},
+		{event(t, unix.NL80211_CMD_NEW_SCAN_RESULTS, ifindex(4))},
+		{event(t, unix.NL80211_CMD_NEW_SCAN_RESULTS, ifindex(3))},
+	}
+	if err := wifi.WaitForScan(r, w); err != nil {
+		t.Errorf("WaitForScan: %v", err)
+	}
+	if len(*r) != 0 {
+		t.Errorf("WaitForScan returned with %d batches of notifications left", len(*r))
+	}
+
+	r = &eventReceiver{
+		[]genetlink.Message{
+			event(t, unix.NL80211_CMD_SCAN_ABORTED, ifindex(4)),
+			event(t, unix.NL80211_CMD_SCAN_ABORTED, ifindex(3)),
+		},
+	}
+	if err := wifi.WaitForScan(r, w); !errors.Is(err, wifi.ErrScanAborted) {
+		t.Errorf("got error %v, expected ErrScanAborted", err)
+	}
+}
+
+// TestWaitForScanResultsJoinsFirst tests that WaitForScanResults joins the
+// "scan" group before triggering the scan, failing without a request when
+// the group can't be joined.
+func TestWaitForScanResultsJoinsFirst(t *testing.T) {
+	w := &wifi.WifiInterface{Index: 3, Name: "wlan0"}
+	if _, err := (&wifi.Client{}).WaitForScanResults(context.Background(), w); err == nil {
+		t.Fatal("expected an error without the scan group")
+	}
+}
@@ -0,0 +1,382 @@
+package wifi
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// StationInfo contains statistics about a station associated with an interface.
+type StationInfo struct {
+	// The hardware address of the station.
+	HardwareAddr net.HardwareAddr
+
+	// The time since the station last connected.
+	Connected time.Duration
+
+	// The time since wireless activity last occurred.
+	Inactive time.Duration
+
+	// The number of bytes received by this station.
+	ReceivedBytes int
+
+	// The number of bytes transmitted by this station.
+	TransmittedBytes int
+
+	// The number of packets received by this station.
+	ReceivedPackets int
+
+	// The number of packets transmitted by this station.
+	TransmittedPackets int
+
+	// The most recent receive and transmit rates used by this station.
+	ReceiveBitrate  RateInfo
+	TransmitBitrate RateInfo
+
+	// The signal strength of the last received PPDU, in dBm.
+	Signal int
+
+	// The average signal strength of received PPDUs, in dBm.
+	SignalAvg int
+
+	// The per-antenna signal strength of the last received PPDU, in dBm.
+	ChainSignal []int
+
+	// The throughput the kernel rate control algorithm expects to achieve
+	// with this station, in kbit/s.
+	ExpectedThroughput int
+
+	// The number of times the station has had to retry while sending a packet.
+	TransmitRetries int
+
+	// The number of times a packet transmission failed.
+	TransmitFailed int
+
+	// The number of times a beacon loss was detected.
+	BeaconLoss int
+
+	// The average signal strength of beacons received from this station,
+	// in dBm. It is a steadier measure of link quality than SignalAvg.
+	BeaconSignalAvg int
+
+	// The total time spent receiving from this station.
+	ReceiveDuration time.Duration
+
+	// The total time spent transmitting to this station.
+	TransmitDuration time.Duration
+
+	// The weight of this station in airtime fairness scheduling, or 0 if
+	// the driver doesn't report one.
+	AirtimeWeight int
+}
+
+// StationFlags is a set of station flags, with bit n holding the
+// NL80211_STA_FLAG_* flag n.
+type StationFlags uint32
+
+const (
+	StationFlagAuthorized    StationFlags = 1 << unix.NL80211_STA_FLAG_AUTHORIZED
+	StationFlagShortPreamble StationFlags = 1 << unix.NL80211_STA_FLAG_SHORT_PREAMBLE
+	StationFlagWME           StationFlags = 1 << unix.NL80211_STA_FLAG_WME
+	StationFlagMFP           StationFlags = 1 << unix.NL80211_STA_FLAG_MFP
+	StationFlagAuthenticated StationFlags = 1 << unix.NL80211_STA_FLAG_AUTHENTICATED
+	StationFlagTDLSPeer      StationFlags = 1 << unix.NL80211_STA_FLAG_TDLS_PEER
+	StationFlagAssociated    StationFlags = 1 << unix.NL80211_STA_FLAG_ASSOCIATED
+)
+
+var stationFlagNames = []struct {
+	flag StationFlags
+	name string
+}{
+	{StationFlagAuthorized, "authorized"},
+	{StationFlagShortPreamble, "short-preamble"},
+	{StationFlagWME, "WME"},
+	{StationFlagMFP, "MFP"},
+	{StationFlagAuthenticated, "authenticated"},
+	{StationFlagTDLSPeer, "TDLS-peer"},
+	{StationFlagAssociated, "associated"},
+}
+
+// String returns the names of the flags in f, separated by "|".
+func (f StationFlags) String() string {
+	var names []string
+	for _, n := range stationFlagNames {
+		if f&n.flag != 0 {
+			names = append(names, n.name)
+			f &^= n.flag
+		}
+	}
+	if f != 0 {
+		names = append(names, fmt.Sprintf("unknown(%#x)", uint32(f)))
+	}
+	return strings.Join(names, "|")
+}
+
+// String returns a one line summary of the station for logging.
+func (info *StationInfo) String() string {
+	return fmt.Sprintf("Station: HardwareAddr=%v, Signal=%ddBm, Connected=%v, Inactive=%v, RxBytes=%d, TxBytes=%d, RxBitrate=%v, TxBitrate=%v",
+		info.HardwareAddr, info.Signal, info.Connected, info.Inactive,
+		info.ReceivedBytes, info.TransmittedBytes, info.ReceiveBitrate, info.TransmitBitrate)
+}
+
+// RateInfo describes the rate at which frames were sent to or received
+// from a station.
+type RateInfo struct {
+	// Bitrate is the total bitrate in bits per second. Drivers that only
+	// report the MCS of HT, VHT or HE rates get an estimate computed from
+	// the MCS, spatial streams, channel width and guard interval.
+	Bitrate int
+
+	// MCS is the HT MCS index, or -1 if the rate is not an HT rate.
+	MCS int
+
+	// VHTMCS and VHTNSS are the VHT MCS index and number of spatial
+	// streams, or -1 if the rate is not a VHT rate.
+	VHTMCS int
+	VHTNSS int
+
+	ShortGI bool
+
+	// HEMCS and HENSS are the HE MCS index and number of spatial
+	// streams, or -1 if the rate is not an HE rate.
+	HEMCS int
+	HENSS int
+
+	// HEGI is the guard interval of an HE rate.
+	HEGI HEGuardInterval
+
+	// HEDCM is set when an HE rate uses dual carrier modulation.
+	HEDCM bool
+
+	// Width is the channel width in MHz. 80+80 MHz rates report 160.
+	Width int
+}
+
+// String returns a compact representation of a RateInfo, for example
+// "1200.9 Mbit/s 80MHz HE-MCS 11 NSS 2 GI 0.8us".
+func (r RateInfo) String() string {
+	s := fmt.Sprintf("%.1f Mbit/s %dMHz", float64(r.Bitrate)/1e6, r.Width)
+
+	switch {
+	case r.HEMCS >= 0:
+		s += fmt.Sprintf(" HE-MCS %d NSS %d GI %v", r.HEMCS, r.HENSS, r.HEGI)
+		if r.HEDCM {
+			s += " DCM"
+		}
+		return s
+	case r.VHTMCS >= 0:
+		s += fmt.Sprintf(" VHT-MCS %d NSS %d", r.VHTMCS, r.VHTNSS)
+	case r.MCS >= 0:
+		s += fmt.Sprintf(" MCS %d", r.MCS)
+	}
+
+	if r.ShortGI {
+		s += " short GI"
+	}
+	return s
+}
+
+// An HEGuardInterval is the guard interval used by an HE rate.
+type HEGuardInterval int
+
+const (
+	HEGuardInterval0_8 HEGuardInterval = unix.NL80211_RATE_INFO_HE_GI_0_8
+	HEGuardInterval1_6 HEGuardInterval = unix.NL80211_RATE_INFO_HE_GI_1_6
+	HEGuardInterval3_2 HEGuardInterval = unix.NL80211_RATE_INFO_HE_GI_3_2
+)
+
+// String returns the string representation of an HEGuardInterval.
+func (gi HEGuardInterval) String() string {
+	switch gi {
+	case HEGuardInterval0_8:
+		return "0.8us"
+	case HEGuardInterval1_6:
+		return "1.6us"
+	case HEGuardInterval3_2:
+		return "3.2us"
+	default:
+		return fmt.Sprintf("unknown(%d)", gi)
+	}
+}
+
+// parseAttributes parses the nested NL80211_ATTR_STA_INFO attributes
+// of a NL80211_CMD_GET_STATION response into a StationInfo.
+func (info *StationInfo) parseAttributes(attrs []netlink.Attribute) error {
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_STA_INFO_CONNECTED_TIME:
+			// Though nl80211 does not specify, this value appears to be in seconds:
+			// https://lists.linuxfoundation.org/pipermail/bridge/2011-May/007605.html
+			info.Connected = time.Duration(nlenc.Uint32(a.Data)) * time.Second
+		case unix.NL80211_STA_INFO_INACTIVE_TIME:
+			info.Inactive = time.Duration(nlenc.Uint32(a.Data)) * time.Millisecond
+		case unix.NL80211_STA_INFO_RX_BYTES64:
+			info.ReceivedBytes = int(nlenc.Uint64(a.Data))
+		case unix.NL80211_STA_INFO_TX_BYTES64:
+			info.TransmittedBytes = int(nlenc.Uint64(a.Data))
+		case unix.NL80211_STA_INFO_SIGNAL:
+			if len(a.Data) >= 1 { info.Signal = int(int8(a.Data[0])) }
+		case unix.NL80211_STA_INFO_SIGNAL_AVG:
+			if len(a.Data) >= 1 { info.SignalAvg = int(int8(a.Data[0])) }
+		case unix.NL80211_STA_INFO_CHAIN_SIGNAL:
+			chains, err := netlink.UnmarshalAttributes(a.Data)
+			if err != nil { return err }
+
+			info.ChainSignal = make([]int, 0, len(chains))
+			for _, c := range chains {
+				if len(c.Data) < 1 { continue }
+				info.ChainSignal = append(info.ChainSignal, int(int8(c.Data[0])))
+			}
+		case unix.NL80211_STA_INFO_RX_PACKETS:
+			info.ReceivedPackets = int(nlenc.Uint32(a.Data))
+		case unix.NL80211_STA_INFO_TX_PACKETS:
+			info.TransmittedPackets = int(nlenc.Uint32(a.Data))
+		case unix.NL80211_STA_INFO_EXPECTED_THROUGHPUT:
+			info.ExpectedThroughput = int(nlenc.Uint32(a.Data))
+		case unix.NL80211_STA_INFO_TX_RETRIES:
+			info.TransmitRetries = int(nlenc.Uint32(a.Data))
+		case unix.NL80211_STA_INFO_TX_FAILED:
+			info.TransmitFailed = int(nlenc.Uint32(a.Data))
+		case unix.NL80211_STA_INFO_BEACON_LOSS:
+			info.BeaconLoss = int(nlenc.Uint32(a.Data))
+		case unix.NL80211_STA_INFO_BEACON_SIGNAL_AVG:
+			if len(a.Data) >= 1 { info.BeaconSignalAvg = int(int8(a.Data[0])) }
+		case unix.NL80211_STA_INFO_RX_DURATION:
+			info.ReceiveDuration = time.Duration(nlenc.Uint64(a.Data)) * time.Microsecond
+		case unix.NL80211_STA_INFO_TX_DURATION:
+			info.TransmitDuration = time.Duration(nlenc.Uint64(a.Data)) * time.Microsecond
+		case unix.NL80211_STA_INFO_AIRTIME_WEIGHT:
+			info.AirtimeWeight = int(nlenc.Uint16(a.Data))
+		case unix.NL80211_STA_INFO_RX_BITRATE, unix.NL80211_STA_INFO_TX_BITRATE:
+			rate, err := parseRateInfo(a.Data)
+			if err != nil { return err }
+
+			if a.Type == unix.NL80211_STA_INFO_RX_BITRATE {
+				info.ReceiveBitrate = *rate
+			} else {
+				info.TransmitBitrate = *rate
+			}
+		}
+	}
+	return nil
+}
+
+// parseRateInfo parses a nested NL80211_RATE_INFO attribute.
+func parseRateInfo(b []byte) (*RateInfo, error) {
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil { return nil, fmt.Errorf("parseRateInfo: %v", err) }
+
+	rate := &RateInfo{
+		MCS:    -1,
+		VHTMCS: -1,
+		VHTNSS: -1,
+		HEMCS:  -1,
+		HENSS:  -1,
+		Width:  20,
+	}
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_RATE_INFO_BITRATE32:
+			rate.Bitrate = int(nlenc.Uint32(a.Data))
+		case unix.NL80211_RATE_INFO_MCS:
+			if len(a.Data) >= 1 { rate.MCS = int(a.Data[0]) }
+		case unix.NL80211_RATE_INFO_VHT_MCS:
+			if len(a.Data) >= 1 { rate.VHTMCS = int(a.Data[0]) }
+		case unix.NL80211_RATE_INFO_VHT_NSS:
+			if len(a.Data) >= 1 { rate.VHTNSS = int(a.Data[0]) }
+		case unix.NL80211_RATE_INFO_SHORT_GI:
+			rate.ShortGI = true
+		case unix.NL80211_RATE_INFO_HE_MCS:
+			if len(a.Data) >= 1 { rate.HEMCS = int(a.Data[0]) }
+		case unix.NL80211_RATE_INFO_HE_NSS:
+			if len(a.Data) >= 1 { rate.HENSS = int(a.Data[0]) }
+		case unix.NL80211_RATE_INFO_HE_GI:
+			if len(a.Data) >= 1 { rate.HEGI = HEGuardInterval(a.Data[0]) }
+		case unix.NL80211_RATE_INFO_HE_DCM:
+			if len(a.Data) >= 1 { rate.HEDCM = a.Data[0] != 0 }
+		case unix.NL80211_RATE_INFO_40_MHZ_WIDTH:
+			rate.Width = 40
+		case unix.NL80211_RATE_INFO_80_MHZ_WIDTH:
+			rate.Width = 80
+		case unix.NL80211_RATE_INFO_80P80_MHZ_WIDTH, unix.NL80211_RATE_INFO_160_MHZ_WIDTH:
+			rate.Width = 160
+		}
+
+		// Only use 16-bit counters if the 32-bit counters are not present.
+		// If the 32-bit counters appear later in the slice, they will overwrite
+		// these values.
+		if rate.Bitrate == 0 && a.Type == unix.NL80211_RATE_INFO_BITRATE {
+			rate.Bitrate = int(nlenc.Uint16(a.Data))
+		}
+	}
+
+	// Scale bitrate to bits/second as base unit instead of 100kbits/second.
+	// * @NL80211_RATE_INFO_BITRATE: total bitrate (u16, 100kbit/s)
+	// * @NL80211_RATE_INFO_BITRATE32: total bitrate (u32, 100kbit/s)
+	rate.Bitrate *= 100 * 1000
+	if rate.Bitrate == 0 {
+		rate.Bitrate = rate.estimateBitrate()
+	}
+	return rate, nil
+}
+
+// mcsBitsPerSubcarrier holds the data bits carried per subcarrier and
+// symbol by each MCS index, the bits of its modulation times its coding
+// rate. HT MCS indices repeat these for each spatial stream.
+var mcsBitsPerSubcarrier = []float64{
+	0.5,       // BPSK 1/2
+	1,         // QPSK 1/2
+	1.5,       // QPSK 3/4
+	2,         // 16-QAM 1/2
+	3,         // 16-QAM 3/4
+	4,         // 64-QAM 2/3
+	4.5,       // 64-QAM 3/4
+	5,         // 64-QAM 5/6
+	6,         // 256-QAM 3/4
+	20.0 / 3,  // 256-QAM 5/6
+	7.5,       // 1024-QAM 3/4
+	25.0 / 3,  // 1024-QAM 5/6
+}
+
+// estimateBitrate computes the bitrate in bits per second of an HT, VHT or
+// HE rate from its MCS, returning 0 for rates it doesn't know.
+func (r *RateInfo) estimateBitrate() int {
+	var mcs, nss int
+	var subcarriers, symbol float64
+	switch {
+	case r.HEMCS >= 0:
+		mcs, nss = r.HEMCS, r.HENSS
+		// HE data subcarriers per width, and a 12.8us symbol plus guard
+		// interval.
+		subcarriers = map[int]float64{20: 234, 40: 468, 80: 980, 160: 1960}[r.Width]
+		symbol = 12.8 + map[HEGuardInterval]float64{HEGuardInterval0_8: 0.8, HEGuardInterval1_6: 1.6, HEGuardInterval3_2: 3.2}[r.HEGI]
+	case r.VHTMCS >= 0:
+		mcs, nss = r.VHTMCS, r.VHTNSS
+	case r.MCS >= 0 && r.MCS < 32:
+		mcs, nss = r.MCS%8, r.MCS/8+1
+	default:
+		return 0
+	}
+	if r.HEMCS < 0 {
+		// HT and VHT data subcarriers per width, and a 3.2us symbol plus
+		// guard interval.
+		subcarriers = map[int]float64{20: 52, 40: 108, 80: 234, 160: 468}[r.Width]
+		symbol = 4
+		if r.ShortGI {
+			symbol = 3.6
+		}
+	}
+	if mcs >= len(mcsBitsPerSubcarrier) || nss < 1 || subcarriers == 0 { return 0 }
+
+	bits := subcarriers * mcsBitsPerSubcarrier[mcs] * float64(nss)
+	if r.HEMCS >= 0 && r.HEDCM {
+		bits /= 2
+	}
+	// Bits per microsecond are Mbit/s.
+	return int(bits / symbol * 1e6)
+}
@@ -0,0 +1,295 @@
+package wifi_test
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bryancoxwell/wifi"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// TestParseRateInfo tests the parsing of legacy, HT and VHT rates from a
+// nested NL80211_RATE_INFO attribute.
+func TestParseRateInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		attrs   []netlink.Attribute
+		bitrate int
+		mcs     int
+		vhtMCS  int
+		vhtNSS  int
+		shortGI bool
+		width   int
+	}{
+		{
+			name:    "legacy 16-bit",
+			attrs:   []netlink.Attribute{{Type: unix.NL80211_RATE_INFO_BITRATE, Data: nlenc.Uint16Bytes(540)}},
+			bitrate: 54000000, mcs: -1, vhtMCS: -1, vhtNSS: -1, width: 20,
+		},
+		{
+			name:    "HT 40MHz short GI",
+			attrs:   []netlink.Attribute{{Type: unix.NL80211_RATE_INFO_BITRATE32, Data: nlenc.Uint32Bytes(1500)}, {Type: unix.NL80211_RATE_INFO_MCS, Data: []byte{7}}, {Type: unix.NL80211_RATE_INFO_40_MHZ_WIDTH}, {Type: unix.NL80211_RATE_INFO_SHORT_GI}},
+			bitrate: 150000000, mcs: 7, vhtMCS: -1, vhtNSS: -1, shortGI: true, width: 40,
+		},
+		{
+			name:    "VHT 80MHz",
+			attrs:   []netlink.Attribute{{Type: unix.NL80211_RATE_INFO_BITRATE32, Data: nlenc.Uint32Bytes(8667)}, {Type: unix.NL80211_RATE_INFO_VHT_MCS, Data: []byte{9}}, {Type: unix.NL80211_RATE_INFO_VHT_NSS, Data: []byte{2}}, {Type: unix.NL80211_RATE_INFO_80_MHZ_WIDTH}},
+			bitrate: 866700000, mcs: -1, vhtMCS: 9, vhtNSS: 2, width: 80,
+		},
+		{
+			name:    "32-bit bitrate after 16-bit",
+			attrs:   []netlink.Attribute{{Type: unix.NL80211_RATE_INFO_BITRATE, Data: nlenc.Uint16Bytes(0xffff)}, {Type: unix.NL80211_RATE_INFO_BITRATE32, Data: nlenc.Uint32Bytes(70000)}, {Type: unix.NL80211_RATE_INFO_160_MHZ_WIDTH}},
+			bitrate: 7000000000, mcs: -1, vhtMCS: -1, vhtNSS: -1, width: 160,
+		},
+	}
+	for _, tt := range tests {
+		b, err := netlink.MarshalAttributes(tt.attrs)
+		if err != nil {
+			t.Fatalf("%s: failed to marshal attributes: %v", tt.name, err)
+		}
+		rate, err := wifi.ParseRateInfo(b)
+		if err != nil {
+			t.Fatalf("%s: ParseRateInfo: %v", tt.name, err)
+		}
+		if rate.Bitrate != tt.bitrate || rate.MCS != tt.mcs || rate.VHTMCS != tt.vhtMCS || rate.VHTNSS != tt.vhtNSS || rate.ShortGI != tt.shortGI || rate.Width != tt.width {
+			t.Errorf("%s: unexpected rate %+v", tt.name, *rate)
+		}
+	}
+}
+
+// TestStationInfoChainSignal tests the parsing of the per-chain signal
+// strengths, nested as one s8 attribute per chain.
+func TestStationInfoChainSignal(t *testing.T) {
+	chains, err := netlink.MarshalAttributes([]netlink.Attribute{
+		{Type: 0, Data: []byte{0xd3}},
+		{Type: 1, Data: []byte{0xce}},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal chain attributes: %v", err)
+	}
+	attrs := []netlink.Attribute{
+		{Type: unix.NL80211_STA_INFO_CHAIN_SIGNAL, Data: chains},
+	}
+
+	info := &wifi.StationInfo{}
+	if err := info.ParseAttributes(attrs); err != nil {
+		t.Fatalf("ParseAttributes: %v", err)
+	}
+	if !reflect.DeepEqual(info.ChainSignal, []int{-45, -50}) {
+		t.Errorf("unexpected ChainSignal: %v", info.ChainSignal)
+	}
+}
+
+// TestStationInfoSignalAvg tests the parsing of the average signal, which
+// is kept apart from the signal of the last PPDU.
+func TestStationInfoSignalAvg(t *testing.T) {
+	attrs := []netlink.Attribute{
+		{Type: unix.NL80211_STA_INFO_SIGNAL, Data: []byte{0xc0}},
+		{Type: unix.NL80211_STA_INFO_SIGNAL_AVG, Data: []byte{0xc3}},
+	}
+
+	info := &wifi.StationInfo{}
+	if err := info.ParseAttributes(attrs); err != nil {
+		t.Fatalf("ParseAttributes: %v", err)
+	}
+	if info.SignalAvg != -61 {
+		t.Errorf("unexpected SignalAvg: %d", info.SignalAvg)
+	}
+	if info.Signal != -64 {
+		t.Errorf("unexpected Signal: %d", info.Signal)
+	}
+}
+
+// TestStationInfoExpectedThroughput tests that the expected throughput is
+// kept in the kbit/s the kernel reports it in.
+func TestStationInfoExpectedThroughput(t *testing.T) {
+	attrs := []netlink.Attribute{
+		{Type: unix.NL80211_STA_INFO_EXPECTED_THROUGHPUT, Data: nlenc.Uint32Bytes(433300)},
+	}
+
+	info := &wifi.StationInfo{}
+	if err := info.ParseAttributes(attrs); err != nil {
+		t.Fatalf("ParseAttributes: %v", err)
+	}
+	if info.ExpectedThroughput != 433300 {
+		t.Errorf("got ExpectedThroughput %d kbit/s, expected 433300 kbit/s", info.ExpectedThroughput)
+	}
+}
+
+// TestRateInfoString tests the String method of the RateInfo type for
+// legacy, HT, VHT and HE rates.
+func TestRateInfoString(t *testing.T) {
+	tests := []struct {
+		rate     wifi.RateInfo
+		expected string
+	}{
+		{
+			rate:     wifi.RateInfo{Bitrate: 54000000, MCS: -1, VHTMCS: -1, HEMCS: -1, Width: 20},
+			expected: "54.0 Mbit/s 20MHz",
+		},
+		{
+			rate:     wifi.RateInfo{Bitrate: 150000000, MCS: 7, VHTMCS: -1, HEMCS: -1, ShortGI: true, Width: 40},
+			expected: "150.0 Mbit/s 40MHz MCS 7 short GI",
+		},
+		{
+			rate:     wifi.RateInfo{Bitrate: 866700000, MCS: -1, VHTMCS: 9, VHTNSS: 2, HEMCS: -1, ShortGI: true, Width: 80},
+			expected: "866.7 Mbit/s 80MHz VHT-MCS 9 NSS 2 short GI",
+		},
+		{
+			rate:     wifi.RateInfo{Bitrate: 1080600000, MCS: -1, VHTMCS: -1, HEMCS: 9, HENSS: 2, HEGI: wifi.HEGuardInterval0_8, Width: 80},
+			expected: "1080.6 Mbit/s 80MHz HE-MCS 9 NSS 2 GI 0.8us",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.rate.String(); got != tt.expected {
+			t.Errorf("RateInfo.String mismatch.\nExpected: \t%v\nGot:\t\t%v\n", tt.expected, got)
+		}
+	}
+}
+
+// TestStationInfoString tests the one line summary of a StationInfo.
+func TestStationInfoString(t *testing.T) {
+	info := &wifi.StationInfo{
+		HardwareAddr:     net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x01, 0x00},
+		Signal:           -48,
+		Connected:        90 * time.Second,
+		Inactive:         20 * time.Millisecond,
+		ReceivedBytes:    1024,
+		TransmittedBytes: 2048,
+		ReceiveBitrate:   wifi.RateInfo{Bitrate: 54000000, MCS: -1, VHTMCS: -1, HEMCS: -1, Width: 20},
+		TransmitBitrate:  wifi.RateInfo{Bitrate: 150000000, MCS: 7, VHTMCS: -1, HEMCS: -1, ShortGI: true, Width: 40},
+	}
+	expected := "Station: HardwareAddr=02:00:00:00:01:00, Signal=-48dBm, Connected=1m30s, Inactive=20ms, " +
+		"RxBytes=1024, TxBytes=2048, RxBitrate=54.0 Mbit/s 20MHz, TxBitrate=150.0 Mbit/s 40MHz MCS 7 short GI"
+	if got := info.String(); got != expected {
+		t.Errorf("String mismatch.\nExpected: \t%s\nGot:\t\t%s\n", expected, got)
+	}
+}
+
+// TestParseRateInfoEstimate tests that rates reported without a bitrate
+// get one estimated from their MCS.
+func TestParseRateInfoEstimate(t *testing.T) {
+	tests := []struct {
+		name     string
+		attrs    []netlink.Attribute
+		expected int
+	}{
+		{
+			name:     "reported",
+			attrs:    []netlink.Attribute{{Type: unix.NL80211_RATE_INFO_BITRATE32, Data: nlenc.Uint32Bytes(540)}, {Type: unix.NL80211_RATE_INFO_MCS, Data: []byte{7}}},
+			expected: 54000000,
+		},
+		{
+			name:     "HT MCS 7",
+			attrs:    []netlink.Attribute{{Type: unix.NL80211_RATE_INFO_MCS, Data: []byte{7}}},
+			expected: 65000000,
+		},
+		{
+			name:     "HT MCS 15 40MHz short GI",
+			attrs:    []netlink.Attribute{{Type: unix.NL80211_RATE_INFO_MCS, Data: []byte{15}}, {Type: unix.NL80211_RATE_INFO_40_MHZ_WIDTH}, {Type: unix.NL80211_RATE_INFO_SHORT_GI}},
+			expected: 300000000,
+		},
+		{
+			name:     "VHT MCS 9 80MHz short GI",
+			attrs:    []netlink.Attribute{{Type: unix.NL80211_RATE_INFO_VHT_MCS, Data: []byte{9}}, {Type: unix.NL80211_RATE_INFO_VHT_NSS, Data: []byte{1}}, {Type: unix.NL80211_RATE_INFO_80_MHZ_WIDTH}, {Type: unix.NL80211_RATE_INFO_SHORT_GI}},
+			expected: 433333333,
+		},
+		{
+			name:     "HE MCS 11 80MHz",
+			attrs:    []netlink.Attribute{{Type: unix.NL80211_RATE_INFO_HE_MCS, Data: []byte{11}}, {Type: unix.NL80211_RATE_INFO_HE_NSS, Data: []byte{2}}, {Type: unix.NL80211_RATE_INFO_HE_GI, Data: []byte{unix.NL80211_RATE_INFO_HE_GI_0_8}}, {Type: unix.NL80211_RATE_INFO_80_MHZ_WIDTH}},
+			expected: 1200980392,
+		},
+	}
+	for _, tt := range tests {
+		rate, err := wifi.ParseRateInfo(mustMarshalAttributes(t, tt.attrs))
+		if err != nil {
+			t.Fatalf("%s: ParseRateInfo: %v", tt.name, err)
+		}
+		if rate.Bitrate != tt.expected {
+			t.Errorf("%s: got bitrate %d, expected %d", tt.name, rate.Bitrate, tt.expected)
+		}
+	}
+}
+
+// TestStationInfoAirtime tests the parsing of the airtime statistics of a
+// station.
+func TestStationInfoAirtime(t *testing.T) {
+	attrs := []netlink.Attribute{
+		{Type: unix.NL80211_STA_INFO_TX_DURATION, Data: nlenc.Uint64Bytes(1500000)},
+		{Type: unix.NL80211_STA_INFO_AIRTIME_WEIGHT, Data: nlenc.Uint16Bytes(256)},
+	}
+
+	info := &wifi.StationInfo{}
+	if err := info.ParseAttributes(attrs); err != nil {
+		t.Fatalf("ParseAttributes: %v", err)
+	}
+	if info.TransmitDuration != 1500*time.Millisecond {
+		t.Errorf("unexpected TransmitDuration: %v", info.TransmitDuration)
+	}
+	if info.AirtimeWeight != 256 {
+		t.Errorf("unexpected AirtimeWeight: %d", info.AirtimeWeight)
+	}
+}
+
+// TestStationInfoBeaconSignal tests the parsing of the beacon signal and
+// receive airtime of a station.
+func TestStationInfoBeaconSignal(t *testing.T) {
+	attrs := []netlink.Attribute{
+		{Type: unix.NL80211_STA_INFO_BEACON_SIGNAL_AVG, Data: []byte{0xc4}},
+		{Type: unix.NL80211_STA_INFO_RX_DURATION, Data: nlenc.Uint64Bytes(250000)},
+	}
+
+	info := &wifi.StationInfo{}
+	if err := info.ParseAttributes(attrs); err != nil {
+		t.Fatalf("ParseAttributes: %v", err)
+	}
+	if info.BeaconSignalAvg != -60 {
+		t.Errorf("unexpected BeaconSignalAvg: %d", info.BeaconSignalAvg)
+	}
+	if info.ReceiveDuration != 250*time.Millisecond {
+		t.Errorf("unexpected ReceiveDuration: %v", info.ReceiveDuration)
+	}
+}
+
+// TestStationInfoTruncated tests that zero-length s8 and u8 attributes are
+// ignored rather than read past their end.
+func TestStationInfoTruncated(t *testing.T) {
+	attrs := []netlink.Attribute{
+		{Type: unix.NL80211_STA_INFO_SIGNAL},
+		{Type: unix.NL80211_STA_INFO_SIGNAL_AVG},
+		{Type: unix.NL80211_STA_INFO_BEACON_SIGNAL_AVG},
+		{Type: unix.NL80211_STA_INFO_CHAIN_SIGNAL, Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: 0},
+			{Type: 1, Data: []byte{0xce}},
+		})},
+		{Type: unix.NL80211_STA_INFO_TX_BITRATE, Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_RATE_INFO_MCS},
+			{Type: unix.NL80211_RATE_INFO_VHT_MCS},
+			{Type: unix.NL80211_RATE_INFO_VHT_NSS},
+			{Type: unix.NL80211_RATE_INFO_HE_MCS},
+			{Type: unix.NL80211_RATE_INFO_HE_NSS},
+			{Type: unix.NL80211_RATE_INFO_HE_GI},
+			{Type: unix.NL80211_RATE_INFO_HE_DCM},
+		})},
+	}
+
+	info := &wifi.StationInfo{}
+	if err := info.ParseAttributes(attrs); err != nil {
+		t.Fatalf("ParseAttributes: %v", err)
+	}
+	if info.Signal != 0 || info.SignalAvg != 0 || info.BeaconSignalAvg != 0 {
+		t.Errorf("unexpected signals %d, %d, %d", info.Signal, info.SignalAvg, info.BeaconSignalAvg)
+	}
+	if !reflect.DeepEqual(info.ChainSignal, []int{-50}) {
+		t.Errorf("unexpected ChainSignal: %v", info.ChainSignal)
+	}
+	rate := info.TransmitBitrate
+	if rate.MCS != -1 || rate.VHTMCS != -1 || rate.VHTNSS != -1 || rate.HEMCS != -1 || rate.HENSS != -1 || rate.HEDCM {
+		t.Errorf("unexpected TransmitBitrate: %+v", rate)
+	}
+}
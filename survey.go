@@ -0,0 +1,132 @@
+//go:build linux
+// +build linux
+
+package wifi
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// SurveyInfo holds the noise floor and channel utilization a driver
+// measured on one channel.
+type SurveyInfo struct {
+	// Frequency is the frequency of the channel in MHz.
+	Frequency uint32
+
+	// Noise is the noise floor in dBm, and HasNoise reports whether the
+	// driver measured it.
+	Noise    int
+	HasNoise bool
+
+	// InUse is set for the channel the interface currently operates on.
+	InUse bool
+
+	// ActiveTime is how long the radio was on the channel, BusyTime how
+	// long it sensed the channel busy, and ExtensionBusyTime how long it
+	// sensed the secondary channel busy. ReceiveTime and TransmitTime are
+	// the time spent receiving and transmitting, ScanTime the time spent
+	// scanning, and BSSReceiveTime the time spent receiving frames
+	// addressed to the BSS of the interface.
+	ActiveTime        time.Duration
+	BusyTime          time.Duration
+	ExtensionBusyTime time.Duration
+	ReceiveTime       time.Duration
+	TransmitTime      time.Duration
+	ScanTime          time.Duration
+	BSSReceiveTime    time.Duration
+}
+
+// BusyPercent returns the share of ActiveTime the channel was busy, or 0 if
+// the driver reports no active time.
+func (s *SurveyInfo) BusyPercent() float64 {
+	if s.ActiveTime == 0 { return 0 }
+	return float64(s.BusyTime) * 100 / float64(s.ActiveTime)
+}
+
+// SurveyDump returns the survey data the driver of the given interface
+// holds for each channel, which is typically gathered while scanning.
+func (c *Client) SurveyDump(w *WifiInterface) ([]*SurveyInfo, error) {
+	attrs := []AttributeEncoder{
+		InterfaceIndexAttribute(w.Index),
+	}
+	msg, err := NewNl80211Message(unix.NL80211_CMD_GET_SURVEY, attrs)
+	if err != nil { return nil, fmt.Errorf("SurveyDump: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Dump,
+	}
+	response, err := request.Response(c)
+	if err != nil { return nil, fmt.Errorf("SurveyDump: %w", err) }
+
+	surveys, err := parseGetSurveyResponse(response)
+	if err != nil { return nil, fmt.Errorf("SurveyDump: %v", err) }
+	return surveys, nil
+}
+
+// parseGetSurveyResponse parses the responses to a NL80211_CMD_GET_SURVEY
+// request.
+func parseGetSurveyResponse(msgs []genetlink.Message) ([]*SurveyInfo, error) {
+	surveys := make([]*SurveyInfo, 0, len(msgs))
+	for _, m := range msgs {
+		attrs, err := netlink.UnmarshalAttributes(m.Data)
+		if err != nil { return nil, err }
+
+		for _, a := range attrs {
+			if a.Type&^unix.NLA_F_NESTED != unix.NL80211_ATTR_SURVEY_INFO { continue }
+
+			info, err := parseSurveyInfo(a.Data)
+			if err != nil { return nil, err }
+			surveys = append(surveys, info)
+		}
+	}
+	return surveys, nil
+}
+
+// parseSurveyInfo parses the nested NL80211_ATTR_SURVEY_INFO attribute.
+// The older NL80211_SURVEY_INFO_CHANNEL_TIME* attributes share their values
+// with the NL80211_SURVEY_INFO_TIME* ones, so both are handled here; all
+// times are in milliseconds.
+func parseSurveyInfo(b []byte) (*SurveyInfo, error) {
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil { return nil, err }
+
+	ms := func(a netlink.Attribute) time.Duration {
+		return time.Duration(nlenc.Uint64(a.Data)) * time.Millisecond
+	}
+
+	info := &SurveyInfo{}
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_SURVEY_INFO_FREQUENCY:
+			info.Frequency = nlenc.Uint32(a.Data)
+		case unix.NL80211_SURVEY_INFO_NOISE:
+			if len(a.Data) < 1 { continue }
+			info.Noise = int(int8(a.Data[0]))
+			info.HasNoise = true
+		case unix.NL80211_SURVEY_INFO_IN_USE:
+			info.InUse = true
+		case unix.NL80211_SURVEY_INFO_TIME:
+			info.ActiveTime = ms(a)
+		case unix.NL80211_SURVEY_INFO_TIME_BUSY:
+			info.BusyTime = ms(a)
+		case unix.NL80211_SURVEY_INFO_TIME_EXT_BUSY:
+			info.ExtensionBusyTime = ms(a)
+		case unix.NL80211_SURVEY_INFO_TIME_RX:
+			info.ReceiveTime = ms(a)
+		case unix.NL80211_SURVEY_INFO_TIME_TX:
+			info.TransmitTime = ms(a)
+		case unix.NL80211_SURVEY_INFO_TIME_SCAN:
+			info.ScanTime = ms(a)
+		case unix.NL80211_SURVEY_INFO_TIME_BSS_RX:
+			info.BSSReceiveTime = ms(a)
+		}
+	}
+	return info, nil
+}
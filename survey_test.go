@@ -0,0 +1,83 @@
+package wifi_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bryancoxwell/wifi"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// TestParseGetSurveyResponse tests the parsing of survey data, with times
+// reported through the older NL80211_SURVEY_INFO_CHANNEL_TIME* attributes
+// and the newer NL80211_SURVEY_INFO_TIME* ones.
+func TestParseGetSurveyResponse(t *testing.T) {
+	survey := func(freq uint32, info []netlink.Attribute) genetlink.Message {
+		return genetlink.Message{
+			Data: mustMarshalAttributes(t, []netlink.Attribute{
+				{Type: unix.NL80211_ATTR_IFINDEX, Data: nlenc.Uint32Bytes(3)},
+				{Type: unix.NL80211_ATTR_SURVEY_INFO, Data: mustMarshalAttributes(t, append([]netlink.Attribute{
+					{Type: unix.NL80211_SURVEY_INFO_FREQUENCY, Data: nlenc.Uint32Bytes(freq)},
+				}, info...))},
+			}),
+		}
+	}
+	msgs := []genetlink.Message{
+		survey(2412, []netlink.Attribute{
+			{Type: unix.NL80211_SURVEY_INFO_NOISE, Data: []byte{0xa1}},
+			{Type: unix.NL80211_SURVEY_INFO_IN_USE},
+			{Type: unix.NL80211_SURVEY_INFO_CHANNEL_TIME, Data: nlenc.Uint64Bytes(1000)},
+			{Type: unix.NL80211_SURVEY_INFO_CHANNEL_TIME_BUSY, Data: nlenc.Uint64Bytes(250)},
+		}),
+		survey(5180, []netlink.Attribute{
+			{Type: unix.NL80211_SURVEY_INFO_TIME, Data: nlenc.Uint64Bytes(400)},
+			{Type: unix.NL80211_SURVEY_INFO_TIME_BUSY, Data: nlenc.Uint64Bytes(20)},
+			{Type: unix.NL80211_SURVEY_INFO_TIME_RX, Data: nlenc.Uint64Bytes(15)},
+			{Type: unix.NL80211_SURVEY_INFO_TIME_TX, Data: nlenc.Uint64Bytes(5)},
+		}),
+	}
+	surveys, err := wifi.ParseGetSurveyResponse(msgs)
+	if err != nil {
+		t.Fatalf("ParseGetSurveyResponse: %v", err)
+	}
+
+	expected := []*wifi.SurveyInfo{
+		{Frequency: 2412, Noise: -95, HasNoise: true, InUse: true, ActiveTime: time.Second, BusyTime: 250 * time.Millisecond},
+		{Frequency: 5180, ActiveTime: 400 * time.Millisecond, BusyTime: 20 * time.Millisecond, ReceiveTime: 15 * time.Millisecond, TransmitTime: 5 * time.Millisecond},
+	}
+	if !reflect.DeepEqual(expected, surveys) {
+		t.Fatalf("ParseGetSurveyResponse mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", expected, surveys)
+	}
+	if got := surveys[0].BusyPercent(); got != 25 {
+		t.Errorf("BusyPercent() = %g, expected 25", got)
+	}
+	if got := (&wifi.SurveyInfo{}).BusyPercent(); got != 0 {
+		t.Errorf("BusyPercent() = %g without an active time, expected 0", got)
+	}
+}
+
+// TestParseGetSurveyResponseTruncatedNoise tests that a zero-length
+// NL80211_SURVEY_INFO_NOISE attribute is ignored rather than read past its
+// end.
+func TestParseGetSurveyResponseTruncatedNoise(t *testing.T) {
+	msg := genetlink.Message{
+		Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_ATTR_SURVEY_INFO, Data: mustMarshalAttributes(t, []netlink.Attribute{
+				{Type: unix.NL80211_SURVEY_INFO_FREQUENCY, Data: nlenc.Uint32Bytes(2412)},
+				{Type: unix.NL80211_SURVEY_INFO_NOISE},
+			})},
+		}),
+	}
+	surveys, err := wifi.ParseGetSurveyResponse([]genetlink.Message{msg})
+	if err != nil {
+		t.Fatalf("ParseGetSurveyResponse: %v", err)
+	}
+	expected := []*wifi.SurveyInfo{{Frequency: 2412}}
+	if !reflect.DeepEqual(expected, surveys) {
+		t.Errorf("ParseGetSurveyResponse mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", expected, surveys)
+	}
+}
@@ -0,0 +1,197 @@
+package wifi
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// A VHTMCSMap is a VHT-MCS map as carried in the VHT Capabilities and
+// VHT Operation elements. It holds 2 bits per spatial stream, for up
+// to 8 spatial streams.
+type VHTMCSMap uint16
+
+// MaxMCS returns the highest VHT MCS index supported for the given number
+// of spatial streams (1-8), or -1 if that number of streams is not supported.
+func (m VHTMCSMap) MaxMCS(nss int) int {
+	if nss < 1 || nss > 8 { return -1 }
+
+	switch (m >> (2 * (nss - 1))) & 0x3 {
+	case 0:
+		return 7
+	case 1:
+		return 8
+	case 2:
+		return 9
+	default:
+		return -1
+	}
+}
+
+// VHTCapabilities describes the contents of a VHT Capabilities element (IE 191).
+type VHTCapabilities struct {
+	// Info is the raw VHT Capabilities Information field.
+	Info uint32
+
+	// MaxMPDULength is the maximum MPDU length the station can receive,
+	// in bytes.
+	MaxMPDULength int
+
+	Supports160      bool
+	Supports80Plus80 bool
+	ShortGI80        bool
+	ShortGI160       bool
+
+	// MaxAMPDULength is the maximum A-MPDU length the station can receive,
+	// in bytes.
+	MaxAMPDULength int
+
+	RxMCSMap VHTMCSMap
+	TxMCSMap VHTMCSMap
+
+	// RxHighestRate and TxHighestRate are the highest supported long
+	// GI data rates in Mbps, or 0 if unspecified.
+	RxHighestRate int
+	TxHighestRate int
+}
+
+// VHTOperation describes the contents of a VHT Operation element (IE 192).
+type VHTOperation struct {
+	// ChannelWidth is the raw Channel Width field: 0 for 20 or 40 MHz,
+	// 1 for 80, 160 or 80+80 MHz, and the deprecated values 2 for 160 MHz
+	// and 3 for 80+80 MHz.
+	ChannelWidth int
+
+	// CenterFrequencySegment0 and CenterFrequencySegment1 are the channel
+	// numbers of the center frequency segments of the BSS.
+	CenterFrequencySegment0 int
+	CenterFrequencySegment1 int
+
+	// BasicMCS is the VHT-MCS map every station in the BSS must support.
+	BasicMCS VHTMCSMap
+}
+
+// OperatingChannel describes the channel layout a BSS actually operates
+// on, derived from its HT and VHT Operation elements.
+type OperatingChannel struct {
+	// Width is the total channel width in MHz.
+	Width int
+
+	// CenterFrequency1 is the center frequency of the channel in MHz.
+	CenterFrequency1 uint32
+
+	// CenterFrequency2 is the center frequency of the second segment
+	// of an 80+80 MHz channel, which reports a Width of 160, or 0 for
+	// contiguous channels.
+	CenterFrequency2 uint32
+}
+
+// parseVHTCapabilities parses the body of a VHT Capabilities element.
+func parseVHTCapabilities(b []byte) (*VHTCapabilities, error) {
+	if len(b) < 12 { return nil, fmt.Errorf("parseVHTCapabilities: %v", errInvalidIE) }
+
+	info := binary.LittleEndian.Uint32(b[0:4])
+
+	var mpdu int
+	switch info & 0x3 {
+	case 0:
+		mpdu = 3895
+	case 1:
+		mpdu = 7991
+	default:
+		mpdu = 11454
+	}
+
+	width := (info >> 2) & 0x3
+	return &VHTCapabilities{
+		Info:             info,
+		MaxMPDULength:    mpdu,
+		Supports160:      width == 1 || width == 2,
+		Supports80Plus80: width == 2,
+		ShortGI80:        info&(1<<5) != 0,
+		ShortGI160:       info&(1<<6) != 0,
+		MaxAMPDULength:   (1 << (13 + int((info>>23)&0x7))) - 1,
+		RxMCSMap:         VHTMCSMap(binary.LittleEndian.Uint16(b[4:6])),
+		RxHighestRate:    int(binary.LittleEndian.Uint16(b[6:8]) & 0x1fff),
+		TxMCSMap:         VHTMCSMap(binary.LittleEndian.Uint16(b[8:10])),
+		TxHighestRate:    int(binary.LittleEndian.Uint16(b[10:12]) & 0x1fff),
+	}, nil
+}
+
+// parseVHTOperation parses the body of a VHT Operation element.
+func parseVHTOperation(b []byte) (*VHTOperation, error) {
+	if len(b) < 5 { return nil, fmt.Errorf("parseVHTOperation: %v", errInvalidIE) }
+
+	return &VHTOperation{
+		ChannelWidth:            int(b[0]),
+		CenterFrequencySegment0: int(b[1]),
+		CenterFrequencySegment1: int(b[2]),
+		BasicMCS:                VHTMCSMap(binary.LittleEndian.Uint16(b[3:5])),
+	}, nil
+}
+
+// deriveOperatingChannel works out the operating channel of a BSS heard on
+// freq from its HT and VHT Operation elements, including the HT CCFS2
+// signaling some 160 MHz BSSs use in place of VHT CCFS1. vht may be nil;
+// ht may only be nil if vht is nil too.
+func deriveOperatingChannel(freq uint32, ht *HTOperation, vht *VHTOperation) *OperatingChannel {
+	base := uint32(5000)
+	if freq < 3000 {
+		base = 2407
+	}
+	channelFreq := func(ch int) uint32 { return base + 5*uint32(ch) }
+
+	if ht == nil {
+		return &OperatingChannel{Width: 20, CenterFrequency1: freq}
+	}
+	primary := channelFreq(ht.PrimaryChannel)
+
+	if vht == nil || vht.ChannelWidth == 0 {
+		if !ht.AnyChannelWidth {
+			return &OperatingChannel{Width: 20, CenterFrequency1: primary}
+		}
+		switch ht.SecondaryChannelOffset {
+		case SecondaryChannelAbove:
+			return &OperatingChannel{Width: 40, CenterFrequency1: primary + 10}
+		case SecondaryChannelBelow:
+			return &OperatingChannel{Width: 40, CenterFrequency1: primary - 10}
+		default:
+			return &OperatingChannel{Width: 20, CenterFrequency1: primary}
+		}
+	}
+
+	ccfs0 := vht.CenterFrequencySegment0
+	ccfs1 := vht.CenterFrequencySegment1
+	if ccfs1 == 0 {
+		ccfs1 = ht.CenterFrequencySegment2
+	}
+
+	switch vht.ChannelWidth {
+	case 2:
+		return &OperatingChannel{Width: 160, CenterFrequency1: channelFreq(ccfs0)}
+	case 3:
+		return &OperatingChannel{
+			Width:            160,
+			CenterFrequency1: channelFreq(ccfs0),
+			CenterFrequency2: channelFreq(ccfs1),
+		}
+	}
+
+	diff := ccfs1 - ccfs0
+	if diff < 0 {
+		diff = -diff
+	}
+	switch {
+	case ccfs1 == 0:
+		return &OperatingChannel{Width: 80, CenterFrequency1: channelFreq(ccfs0)}
+	case diff == 8:
+		return &OperatingChannel{Width: 160, CenterFrequency1: channelFreq(ccfs1)}
+	case diff > 16:
+		return &OperatingChannel{
+			Width:            160,
+			CenterFrequency1: channelFreq(ccfs0),
+			CenterFrequency2: channelFreq(ccfs1),
+		}
+	default:
+		return &OperatingChannel{Width: 80, CenterFrequency1: channelFreq(ccfs0)}
+	}
+}
@@ -13,10 +13,38 @@ type WifiInterface struct {
 	Type InterfaceType
 	Device uint64
 	Frequency uint32
+
+	// ChannelWidth is the width of the channel the interface operates on.
+	ChannelWidth ChannelWidth
+
+	// TxPower is the transmit power of the interface in dBm. The kernel
+	// reports it in units of 0.01 dBm, so it isn't rounded to whole dBm.
+	TxPower float64
+
+	// FourAddr reports whether the interface uses the 4-address (WDS)
+	// frame format.
+	FourAddr bool
 }
 
 func (c *WifiInterface) String() string {
-	return fmt.Sprintf("<InterfaceWlanConfig: Index=%v, Name=%v, HardwareAddr=%v, Phy=%v, Type=%v, Device=%v, Frequency=%v", c.Index, c.Name, c.HardwareAddr, c.Phy, c.Type, c.Device, c.Frequency)
+	return fmt.Sprintf("<WifiInterface: Index=%v, Name=%v, HardwareAddr=%v, Phy=%v, Type=%v, Device=%v, Frequency=%v>", c.Index, c.Name, c.HardwareAddr, c.Phy, c.Type, c.Device, c.Frequency)
+}
+
+// Channel returns the channel number the interface operates on, or 0 if its
+// frequency is unknown.
+func (c *WifiInterface) Channel() int {
+	ch, _, err := FrequencyToChannel(int(c.Frequency))
+	if err != nil { return 0 }
+	return ch
+}
+
+// IsUp reports whether the interface is administratively up. nl80211 doesn't
+// report link state, so it is looked up each time rather than when the
+// interface is read, returning an error if the lookup fails.
+func (c *WifiInterface) IsUp() (bool, error) {
+	iface, err := net.InterfaceByIndex(int(c.Index))
+	if err != nil { return false, fmt.Errorf("IsUp: %w", err) }
+	return iface.Flags&net.FlagUp != 0, nil
 }
 
 // An InterfaceType is the operating mode of an Interface.
@@ -70,6 +98,10 @@ func (t InterfaceType) String() string {
 	}
 }
 
+// WifiChannel maps 2.4 and 5 GHz channel numbers to their frequencies in MHz.
+//
+// Deprecated: use ChannelToFrequency and FrequencyToChannel, which also
+// cover 6 GHz channels.
 var WifiChannel = map[int]uint32 {
 	1: 2412,
     2: 2417,
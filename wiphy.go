@@ -0,0 +1,559 @@
+//go:build linux
+// +build linux
+
+package wifi
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// A Wiphy is a physical wireless device and the capabilities it reports.
+type Wiphy struct {
+	Index uint32
+	Name  string
+
+	// SupportedInterfaceTypes lists the interface types the device can
+	// operate in.
+	SupportedInterfaceTypes []InterfaceType
+
+	// MaxScanSSIDs is the number of SSIDs the device can probe for in
+	// a single scan, and MaxScanIELength the maximum length in bytes of
+	// the extra IEs that can be added to its probe requests.
+	MaxScanSSIDs    int
+	MaxScanIELength int
+
+	// RTSThreshold and FragmentationThreshold are in bytes, with
+	// ThresholdOff meaning disabled.
+	RTSThreshold           Threshold
+	FragmentationThreshold Threshold
+
+	RetryShort int
+	RetryLong  int
+
+	// Bands lists the frequency bands the device supports.
+	Bands []Band
+
+	// InterfaceCombinations lists the ways in which the device can run
+	// several interfaces at once. Interfaces of the SoftwareInterfaceTypes,
+	// such as monitor interfaces, are not limited by them.
+	InterfaceCombinations  []InterfaceCombination
+	SoftwareInterfaceTypes []InterfaceType
+
+	// CipherSuites lists the cipher suites the device supports.
+	CipherSuites []CipherSuite
+
+	// Features holds the NL80211_FEATURE_* flags of the device, and
+	// ExtendedFeatures the NL80211_ATTR_EXT_FEATURES bitmap, indexed by
+	// the NL80211_EXT_FEATURE_* constants.
+	Features         uint32
+	ExtendedFeatures []byte
+
+	// AntennaTX and AntennaRX are the bitmaps of the antennas currently
+	// used to transmit and receive, and AvailableAntennasTX and
+	// AvailableAntennasRX those that can be configured with SetAntenna.
+	AntennaTX           uint32
+	AntennaRX           uint32
+	AvailableAntennasTX uint32
+	AvailableAntennasRX uint32
+
+	// MaxACLEntries is the number of addresses a MAC access control list
+	// installed with SetMACACL may hold, or 0 if the device doesn't
+	// support them.
+	MaxACLEntries int
+}
+
+// A Threshold is a frame size in bytes above which the device protects
+// frames with RTS/CTS or fragments them.
+type Threshold uint32
+
+// ThresholdOff disables RTS/CTS or fragmentation. It is untyped so that it
+// can be passed to SetRTSThreshold, which takes a plain uint32.
+const ThresholdOff = 0xffffffff
+
+// minFragmentationThreshold is the smallest fragmentation threshold the
+// kernel accepts.
+const minFragmentationThreshold = 256
+
+// An InterfaceCombination is a set of interfaces that a device can run
+// concurrently.
+type InterfaceCombination struct {
+	Limits []InterfaceLimit
+
+	// MaxInterfaces is the total number of interfaces allowed, and
+	// NumChannels the number of different channels they may use.
+	MaxInterfaces int
+	NumChannels   int
+}
+
+// An InterfaceLimit is the maximum number of interfaces of the given types
+// within an InterfaceCombination.
+type InterfaceLimit struct {
+	Types []InterfaceType
+	Max   int
+}
+
+// A Band is a frequency band supported by a Wiphy, with the frequencies
+// and legacy bitrates the device can use in it.
+type Band struct {
+	Type        BandType
+	Frequencies []BandFrequency
+
+	// Bitrates lists the supported legacy bitrates in Mbps.
+	Bitrates []float64
+}
+
+// A BandFrequency is a frequency within a Band and the regulatory
+// restrictions that currently apply to it.
+type BandFrequency struct {
+	// Frequency is the center frequency in MHz.
+	Frequency uint32
+
+	// Disabled is set when the frequency may not be used at all, and
+	// NoIR when the device may not initiate radiation on it, e.g. by
+	// sending probe requests or beaconing.
+	Disabled bool
+	NoIR     bool
+
+	// Radar is set when radar detection (DFS) is required.
+	Radar bool
+
+	// MaxTxPower is the maximum transmit power in dBm.
+	MaxTxPower float64
+
+	// DFSState is the DFS state of a Radar frequency, DFSTime how long it
+	// has been in that state, and CACTime how long channel availability
+	// checks on it take.
+	DFSState DFSState
+	DFSTime  time.Duration
+	CACTime  time.Duration
+}
+
+// A DFSState is the DFS state of a frequency that requires radar detection.
+type DFSState int
+
+const (
+	// DFSUsable frequencies may be used once a channel availability check
+	// has found no radar.
+	DFSUsable DFSState = unix.NL80211_DFS_USABLE
+
+	// DFSUnavailable frequencies had radar detected on them and may not
+	// be used until their non-occupancy period is over.
+	DFSUnavailable DFSState = unix.NL80211_DFS_UNAVAILABLE
+
+	// DFSAvailable frequencies passed a channel availability check and
+	// may be used right away.
+	DFSAvailable DFSState = unix.NL80211_DFS_AVAILABLE
+)
+
+// String returns the string representation of a DFSState.
+func (s DFSState) String() string {
+	switch s {
+	case DFSUsable:
+		return "usable"
+	case DFSUnavailable:
+		return "unavailable"
+	case DFSAvailable:
+		return "available"
+	default:
+		return fmt.Sprintf("unknown(%d)", s)
+	}
+}
+
+// ChannelInfo describes a frequency of a Wiphy along with the band and
+// channel it belongs to.
+type ChannelInfo struct {
+	BandFrequency
+	Band    BandType
+	Channel int
+}
+
+// Channels returns the frequencies of the band as channels, in the order
+// the device reports them, including those that are disabled.
+func (b *Band) Channels() []ChannelInfo {
+	channels := make([]ChannelInfo, 0, len(b.Frequencies))
+	for _, f := range b.Frequencies {
+		channels = append(channels, b.channelInfo(f))
+	}
+	return channels
+}
+
+// UsableChannels returns the channels of the band that aren't disabled.
+func (b *Band) UsableChannels() []ChannelInfo {
+	var channels []ChannelInfo
+	for _, f := range b.Frequencies {
+		if f.Disabled { continue }
+		channels = append(channels, b.channelInfo(f))
+	}
+	return channels
+}
+
+func (b *Band) channelInfo(f BandFrequency) ChannelInfo {
+	ci := ChannelInfo{BandFrequency: f, Band: b.Type}
+	ci.Channel, _, _ = FrequencyToChannel(int(f.Frequency))
+	return ci
+}
+
+// RequiresDFS reports whether radar detection is needed before the channel
+// can be used to transmit.
+func (ci *ChannelInfo) RequiresDFS() bool {
+	return ci.Radar && ci.DFSState != DFSAvailable
+}
+
+// PassiveOnly reports whether the device may only listen on the channel,
+// without initiating transmission.
+func (ci *ChannelInfo) PassiveOnly() bool {
+	return ci.NoIR
+}
+
+// SupportsInterfaceType reports whether the device can operate in the
+// given interface type.
+func (w *Wiphy) SupportsInterfaceType(iftype InterfaceType) bool {
+	return containsInterfaceType(w.SupportedInterfaceTypes, iftype)
+}
+
+// SupportsCipher reports whether the device supports the given cipher suite.
+func (w *Wiphy) SupportsCipher(cipher CipherSuite) bool {
+	for _, c := range w.CipherSuites {
+		if c == cipher {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsFeature reports whether the device advertises all of the given
+// NL80211_FEATURE_* flags.
+func (w *Wiphy) SupportsFeature(features uint32) bool {
+	return w.Features&features == features
+}
+
+// SupportsExtendedFeature reports whether the device advertises the given
+// NL80211_EXT_FEATURE_* flag.
+func (w *Wiphy) SupportsExtendedFeature(feature int) bool {
+	i := feature / 8
+	if feature < 0 || i >= len(w.ExtendedFeatures) { return false }
+	return w.ExtendedFeatures[i]&(1<<(feature%8)) != 0
+}
+
+// SupportsPSKOffload reports whether the driver can run the WPA 4-way
+// handshake itself in station mode, as SecurityPSK connections require.
+func (w *Wiphy) SupportsPSKOffload() bool {
+	return w.SupportsExtendedFeature(unix.NL80211_EXT_FEATURE_4WAY_HANDSHAKE_STA_PSK)
+}
+
+// SupportsSAEOffload reports whether the driver can run SAE authentication
+// itself in station mode, as SecurityWPA3SAE connections require.
+func (w *Wiphy) SupportsSAEOffload() bool {
+	return w.SupportsExtendedFeature(unix.NL80211_EXT_FEATURE_SAE_OFFLOAD)
+}
+
+// SupportsCQMRSSIList reports whether the driver can monitor several RSSI
+// thresholds at once, as SetCQMRSSIThresholds requires.
+func (w *Wiphy) SupportsCQMRSSIList() bool {
+	return w.SupportsExtendedFeature(unix.NL80211_EXT_FEATURE_CQM_RSSI_LIST)
+}
+
+// Frequency returns the entry for the given frequency in MHz from the
+// device's bands, reporting false if no band contains it.
+func (w *Wiphy) Frequency(freq uint32) (BandFrequency, bool) {
+	for _, b := range w.Bands {
+		for _, f := range b.Frequencies {
+			if f.Frequency == freq {
+				return f, true
+			}
+		}
+	}
+	return BandFrequency{}, false
+}
+
+// ChannelInfo returns the regulatory and DFS flags of the given frequency
+// in MHz, as reported by the device's bands.
+func (w *Wiphy) ChannelInfo(freq int) (*ChannelInfo, error) {
+	for _, b := range w.Bands {
+		for _, f := range b.Frequencies {
+			if int(f.Frequency) != freq { continue }
+
+			ci := b.channelInfo(f)
+			return &ci, nil
+		}
+	}
+	return nil, fmt.Errorf("ChannelInfo: frequency %d MHz not supported on phy %d", freq, w.Index)
+}
+
+// checkChannel reports an error if the given channel, at frequency freq,
+// can't be used on the device.
+func (w *Wiphy) checkChannel(channel int, freq uint32) error {
+	return w.checkFrequencyAs(freq, fmt.Sprintf("channel %d", channel))
+}
+
+// checkFrequency reports an error if the given frequency in MHz can't be
+// used on the device.
+func (w *Wiphy) checkFrequency(freq uint32) error {
+	return w.checkFrequencyAs(freq, fmt.Sprintf("frequency %d MHz", freq))
+}
+
+// checkFrequencyAs implements checkChannel and checkFrequency, naming the
+// frequency as what in errors.
+func (w *Wiphy) checkFrequencyAs(freq uint32, what string) error {
+	f, ok := w.Frequency(freq)
+	if !ok { return fmt.Errorf("%s not supported on phy %d", what, w.Index) }
+	if f.Disabled { return fmt.Errorf("%s is disabled on phy %d", what, w.Index) }
+	return nil
+}
+
+// SupportsCombination reports whether the device can run interfaces of the
+// given types at the same time, one per type given. A device that
+// advertises no combinations can only run a single interface.
+func (w *Wiphy) SupportsCombination(types ...InterfaceType) bool {
+	limited := make([]InterfaceType, 0, len(types))
+	for _, t := range types {
+		if !w.SupportsInterfaceType(t) { return false }
+		if !containsInterfaceType(w.SoftwareInterfaceTypes, t) {
+			limited = append(limited, t)
+		}
+	}
+
+	if len(w.InterfaceCombinations) == 0 {
+		return len(limited) <= 1
+	}
+	for _, comb := range w.InterfaceCombinations {
+		if comb.allows(limited) { return true }
+	}
+	return false
+}
+
+// allows reports whether the combination permits interfaces of the given
+// types. The kernel guarantees that a type appears in at most one limit
+// of a combination, so each interface counts against a single limit.
+func (comb *InterfaceCombination) allows(types []InterfaceType) bool {
+	if len(types) > comb.MaxInterfaces { return false }
+
+	counts := make([]int, len(comb.Limits))
+	for _, t := range types {
+		found := false
+		for i, limit := range comb.Limits {
+			if containsInterfaceType(limit.Types, t) {
+				counts[i]++
+				if counts[i] > limit.Max { return false }
+				found = true
+				break
+			}
+		}
+		if !found { return false }
+	}
+	return true
+}
+
+// containsInterfaceType reports whether types contains t.
+func containsInterfaceType(types []InterfaceType, t InterfaceType) bool {
+	for _, x := range types {
+		if x == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Wiphy returns the physical device underlying the given interface.
+func (c *Client) Wiphy(w *WifiInterface) (*Wiphy, error) {
+	wiphy, err := c.wiphyByIndex(w.Phy)
+	if err != nil { return nil, fmt.Errorf("Wiphy: %v", err)}
+	return wiphy, nil
+}
+
+// wiphyByIndex returns the wiphy with the given index.
+func (c *Client) wiphyByIndex(phy uint32) (*Wiphy, error) {
+	wiphys, err := c.dumpWiphys(WiphyAttribute(phy))
+	if err != nil { return nil, err }
+
+	if len(wiphys) == 0 {
+		return nil, fmt.Errorf("found no wiphy with index %d", phy)
+	}
+	return wiphys[0], nil
+}
+
+// DumpWiphys returns every physical wireless device present on the system.
+func (c *Client) DumpWiphys() ([]*Wiphy, error) {
+	wiphys, err := c.dumpWiphys()
+	if err != nil { return nil, fmt.Errorf("DumpWiphys: %v", err)}
+	return wiphys, nil
+}
+
+// dumpWiphys sends a NL80211_CMD_GET_WIPHY dump request with the given
+// attributes. Without NL80211_ATTR_SPLIT_WIPHY_DUMP the kernel truncates
+// the capabilities of devices that do not fit in a single message, so it
+// is set whenever the kernel supports it. A split dump describes each
+// wiphy over several messages, which are merged again by
+// parseGetWiphyResponse.
+func (c *Client) dumpWiphys(attrs ...AttributeEncoder) ([]*Wiphy, error) {
+	features, err := c.ProtocolFeatures()
+	if err != nil { return nil, err }
+	if features&unix.NL80211_PROTOCOL_FEATURE_SPLIT_WIPHY_DUMP != 0 {
+		attrs = append(attrs, SplitWiphyDumpAttribute(true))
+	}
+
+	msg, err := NewNl80211Message(unix.NL80211_CMD_GET_WIPHY, attrs)
+	if err != nil { return nil, err }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Dump,
+	}
+
+	response, err := request.Response(c)
+	if err != nil { return nil, err }
+
+	return parseGetWiphyResponse(response)
+}
+
+// SetRTSThreshold sets the size in bytes above which frames sent by the
+// wiphy with index phy are protected by RTS/CTS, or turns RTS/CTS off with
+// ThresholdOff. The threshold applies to every interface on the wiphy.
+func (c *Client) SetRTSThreshold(phy int, threshold uint32) error {
+	err := c.setWiphyIndex(uint32(phy), RTSThresholdAttribute(threshold))
+	if err != nil { return fmt.Errorf("SetRTSThreshold: %w", err) }
+	return nil
+}
+
+// SetInterfaceRTSThreshold is like SetRTSThreshold for the wiphy of the
+// given interface.
+func (c *Client) SetInterfaceRTSThreshold(w *WifiInterface, threshold uint32) error {
+	err := c.setWiphyIndex(w.Phy, RTSThresholdAttribute(threshold))
+	if err != nil { return fmt.Errorf("SetInterfaceRTSThreshold: %w", err) }
+	return nil
+}
+
+// SetFragmentationThreshold sets the size in bytes above which frames sent
+// by the wiphy with index phy are fragmented, or turns fragmentation off
+// with ThresholdOff. The kernel requires at least 256 bytes and rounds odd
+// sizes down. The threshold applies to every interface on the wiphy.
+func (c *Client) SetFragmentationThreshold(phy int, threshold Threshold) error {
+	if err := c.setFragmentationThreshold(uint32(phy), threshold); err != nil { return fmt.Errorf("SetFragmentationThreshold: %w", err) }
+	return nil
+}
+
+// SetInterfaceFragmentationThreshold is like SetFragmentationThreshold for
+// the wiphy of the given interface.
+func (c *Client) SetInterfaceFragmentationThreshold(w *WifiInterface, threshold Threshold) error {
+	if err := c.setFragmentationThreshold(w.Phy, threshold); err != nil { return fmt.Errorf("SetInterfaceFragmentationThreshold: %w", err) }
+	return nil
+}
+
+func (c *Client) setFragmentationThreshold(phy uint32, threshold Threshold) error {
+	if threshold != ThresholdOff && threshold < minFragmentationThreshold {
+		return fmt.Errorf("threshold %d is below %d bytes", threshold, minFragmentationThreshold)
+	}
+	return c.setWiphyIndex(phy, FragmentationThresholdAttribute(uint32(threshold)))
+}
+
+// SetRetryLimits sets how many times the wiphy with index phy retries a
+// frame before giving up: short applies to frames sent without RTS/CTS
+// protection, and long to those protected by it. Both must be at least 1.
+// The limits apply to every interface on the wiphy.
+func (c *Client) SetRetryLimits(phy int, short, long uint8) error {
+	attrs, err := retryLimitsAttrs(short, long)
+	if err != nil { return fmt.Errorf("SetRetryLimits: %v", err) }
+
+	if err := c.setWiphyIndex(uint32(phy), attrs...); err != nil { return fmt.Errorf("SetRetryLimits: %w", err) }
+	return nil
+}
+
+// retryLimitsAttrs returns the NL80211_CMD_SET_WIPHY attributes setting the
+// short and long retry limits.
+func retryLimitsAttrs(short, long uint8) ([]AttributeEncoder, error) {
+	if short == 0 || long == 0 { return nil, fmt.Errorf("invalid retry limits %d and %d", short, long) }
+	return []AttributeEncoder{RetryShortAttribute(short), RetryLongAttribute(long)}, nil
+}
+
+// GetAntenna returns the bitmaps of the antennas the wiphy with index phy
+// uses to transmit and receive.
+func (c *Client) GetAntenna(phy int) (txMask, rxMask uint32, err error) {
+	wiphy, err := c.wiphyByIndex(uint32(phy))
+	if err != nil { return 0, 0, fmt.Errorf("GetAntenna: %v", err) }
+	return wiphy.AntennaTX, wiphy.AntennaRX, nil
+}
+
+// SetAntenna sets the bitmaps of the antennas the wiphy with index phy uses
+// to transmit and receive, which must be among those it reports as
+// available. Some drivers only accept the change while all of its
+// interfaces are down.
+func (c *Client) SetAntenna(phy int, tx, rx uint32) error {
+	wiphy, err := c.wiphyByIndex(uint32(phy))
+	if err != nil { return fmt.Errorf("SetAntenna: %v", err) }
+	if err := wiphy.checkAntennas(tx, rx); err != nil { return fmt.Errorf("SetAntenna: %v", err) }
+
+	err = c.setWiphyIndex(uint32(phy),
+		NewAttributeFactory[uint32](unix.NL80211_ATTR_WIPHY_ANTENNA_TX)(tx),
+		NewAttributeFactory[uint32](unix.NL80211_ATTR_WIPHY_ANTENNA_RX)(rx),
+	)
+	if err != nil { return fmt.Errorf("SetAntenna: %w", err) }
+	return nil
+}
+
+// checkAntennas checks that the antenna bitmaps tx and rx can be
+// configured on the wiphy.
+func (w *Wiphy) checkAntennas(tx, rx uint32) error {
+	if w.AvailableAntennasTX == 0 && w.AvailableAntennasRX == 0 { return errors.New("driver does not support antenna configuration") }
+	if tx == 0 || tx&^w.AvailableAntennasTX != 0 { return fmt.Errorf("invalid TX antenna mask %#x, available %#x", tx, w.AvailableAntennasTX) }
+	if rx == 0 || rx&^w.AvailableAntennasRX != 0 { return fmt.Errorf("invalid RX antenna mask %#x, available %#x", rx, w.AvailableAntennasRX) }
+	return nil
+}
+
+// setWiphyIndex sends a NL80211_CMD_SET_WIPHY request for the wiphy with
+// the given index with the given attributes.
+func (c *Client) setWiphyIndex(phy uint32, attrs ...AttributeEncoder) error {
+	attrs = append([]AttributeEncoder{WiphyAttribute(phy)}, attrs...)
+
+	msg, err := NewNl80211Message(unix.NL80211_CMD_SET_WIPHY, attrs)
+	if err != nil { return err }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	_, err = request.Response(c)
+	return err
+}
+
+// ProtocolFeatures returns the NL80211_PROTOCOL_FEATURE_* flags supported
+// by the kernel, such as NL80211_PROTOCOL_FEATURE_SPLIT_WIPHY_DUMP, which
+// DumpWiphys and Wiphy check before requesting a split dump.
+func (c *Client) ProtocolFeatures() (uint32, error) {
+	msg, err := NewNl80211Message(unix.NL80211_CMD_GET_PROTOCOL_FEATURES, nil)
+	if err != nil { return 0, fmt.Errorf("ProtocolFeatures: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request,
+	}
+
+	response, err := request.Response(c)
+	if err != nil { return 0, fmt.Errorf("ProtocolFeatures: %w", err) }
+
+	features, err := parseProtocolFeatures(response)
+	if err != nil { return 0, fmt.Errorf("ProtocolFeatures: %v", err) }
+	return features, nil
+}
+
+// parseProtocolFeatures finds NL80211_ATTR_PROTOCOL_FEATURES in a
+// NL80211_CMD_GET_PROTOCOL_FEATURES response. Kernels that don't report it
+// support no optional features.
+func parseProtocolFeatures(msgs []genetlink.Message) (uint32, error) {
+	for _, m := range msgs {
+		attrs, err := netlink.UnmarshalAttributes(m.Data)
+		if err != nil { return 0, err }
+		for _, a := range attrs {
+			if a.Type == unix.NL80211_ATTR_PROTOCOL_FEATURES {
+				return nlenc.Uint32(a.Data), nil
+			}
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,306 @@
+//go:build linux
+// +build linux
+
+package wifi
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// parseGetWiphyResponse parses the responses to a NL80211_CMD_GET_WIPHY request.
+// Split dumps describe each wiphy over several messages, which may be
+// interleaved with those of other wiphys, so messages are merged by their
+// NL80211_ATTR_WIPHY index.
+func parseGetWiphyResponse(msgs []genetlink.Message) ([]*Wiphy, error) {
+	wiphys := make([]*Wiphy, 0, len(msgs))
+	byIndex := make(map[uint32]*Wiphy)
+	for _, m := range msgs {
+		attrs, err := netlink.UnmarshalAttributes(m.Data)
+		if err != nil {
+			return nil, fmt.Errorf("parseGetWiphyResponse: failed to unpack attributes: %v", err)
+		}
+
+		var wiphy *Wiphy
+		for _, a := range attrs {
+			if a.Type == unix.NL80211_ATTR_WIPHY {
+				wiphy = byIndex[nlenc.Uint32(a.Data)]
+				break
+			}
+		}
+		if wiphy == nil {
+			wiphy = &Wiphy{}
+		}
+
+		if err := wiphy.parseAttributes(attrs); err != nil {
+			return nil, fmt.Errorf("parseGetWiphyResponse: %v", err)
+		}
+		if _, ok := byIndex[wiphy.Index]; !ok {
+			byIndex[wiphy.Index] = wiphy
+			wiphys = append(wiphys, wiphy)
+		}
+	}
+	return wiphys, nil
+}
+
+// parseAttributes parses the attributes of a NL80211_CMD_GET_WIPHY response
+// into a Wiphy.
+func (w *Wiphy) parseAttributes(attrs []netlink.Attribute) error {
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_ATTR_WIPHY:
+			w.Index = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_WIPHY_NAME:
+			w.Name = nlenc.String(a.Data)
+		case unix.NL80211_ATTR_SUPPORTED_IFTYPES:
+			iftypes, err := parseInterfaceTypes(a.Data)
+			if err != nil { return err }
+			w.SupportedInterfaceTypes = iftypes
+		case unix.NL80211_ATTR_MAX_NUM_SCAN_SSIDS:
+			w.MaxScanSSIDs = int(nlenc.Uint8(a.Data))
+		case unix.NL80211_ATTR_MAX_SCAN_IE_LEN:
+			w.MaxScanIELength = int(nlenc.Uint16(a.Data))
+		case unix.NL80211_ATTR_WIPHY_RTS_THRESHOLD:
+			w.RTSThreshold = Threshold(nlenc.Uint32(a.Data))
+		case unix.NL80211_ATTR_WIPHY_FRAG_THRESHOLD:
+			w.FragmentationThreshold = Threshold(nlenc.Uint32(a.Data))
+		case unix.NL80211_ATTR_WIPHY_RETRY_SHORT:
+			w.RetryShort = int(nlenc.Uint8(a.Data))
+		case unix.NL80211_ATTR_WIPHY_RETRY_LONG:
+			w.RetryLong = int(nlenc.Uint8(a.Data))
+		case unix.NL80211_ATTR_WIPHY_BANDS:
+			bands, err := parseBands(a.Data)
+			if err != nil { return err }
+			w.mergeBands(bands)
+		case unix.NL80211_ATTR_INTERFACE_COMBINATIONS:
+			combs, err := parseInterfaceCombinations(a.Data)
+			if err != nil { return err }
+			w.InterfaceCombinations = combs
+		case unix.NL80211_ATTR_SOFTWARE_IFTYPES:
+			iftypes, err := parseInterfaceTypes(a.Data)
+			if err != nil { return err }
+			w.SoftwareInterfaceTypes = iftypes
+		case unix.NL80211_ATTR_CIPHER_SUITES:
+			w.CipherSuites = parseCipherSuites(a.Data)
+		case unix.NL80211_ATTR_FEATURE_FLAGS:
+			w.Features = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_EXT_FEATURES:
+			w.ExtendedFeatures = append([]byte(nil), a.Data...)
+		case unix.NL80211_ATTR_WIPHY_ANTENNA_TX:
+			w.AntennaTX = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_WIPHY_ANTENNA_RX:
+			w.AntennaRX = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_WIPHY_ANTENNA_AVAIL_TX:
+			w.AvailableAntennasTX = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_WIPHY_ANTENNA_AVAIL_RX:
+			w.AvailableAntennasRX = nlenc.Uint32(a.Data)
+		case unix.NL80211_ATTR_MAC_ACL_MAX:
+			w.MaxACLEntries = int(nlenc.Uint32(a.Data))
+		}
+	}
+	return nil
+}
+
+// parseInterfaceTypes parses a nested list of interface types, such as
+// NL80211_ATTR_SUPPORTED_IFTYPES, where each interface type is encoded as
+// a flag attribute whose type is the interface type.
+func parseInterfaceTypes(b []byte) ([]InterfaceType, error) {
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil { return nil, fmt.Errorf("parseInterfaceTypes: %v", err) }
+
+	iftypes := make([]InterfaceType, 0, len(attrs))
+	for _, a := range attrs {
+		iftypes = append(iftypes, InterfaceType(a.Type))
+	}
+	return iftypes, nil
+}
+
+// parseCipherSuites parses NL80211_ATTR_CIPHER_SUITES, an array of u32
+// cipher suite selectors.
+func parseCipherSuites(b []byte) []CipherSuite {
+	suites := make([]CipherSuite, 0, len(b)/4)
+	for i := 0; i+4 <= len(b); i += 4 {
+		suites = append(suites, CipherSuite(nlenc.Uint32(b[i:i+4])))
+	}
+	return suites
+}
+
+// parseInterfaceCombinations parses the nested
+// NL80211_ATTR_INTERFACE_COMBINATIONS attribute: a list of combinations,
+// each holding a list of limits, each of which holds a list of interface
+// types.
+func parseInterfaceCombinations(b []byte) ([]InterfaceCombination, error) {
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil { return nil, fmt.Errorf("parseInterfaceCombinations: %v", err) }
+
+	combs := make([]InterfaceCombination, 0, len(attrs))
+	for _, a := range attrs {
+		cattrs, err := netlink.UnmarshalAttributes(a.Data)
+		if err != nil { return nil, fmt.Errorf("parseInterfaceCombinations: %v", err) }
+
+		var comb InterfaceCombination
+		for _, ca := range cattrs {
+			switch ca.Type {
+			case unix.NL80211_IFACE_COMB_LIMITS:
+				limits, err := parseInterfaceLimits(ca.Data)
+				if err != nil { return nil, fmt.Errorf("parseInterfaceCombinations: %v", err) }
+				comb.Limits = limits
+			case unix.NL80211_IFACE_COMB_MAXNUM:
+				comb.MaxInterfaces = int(nlenc.Uint32(ca.Data))
+			case unix.NL80211_IFACE_COMB_NUM_CHANNELS:
+				comb.NumChannels = int(nlenc.Uint32(ca.Data))
+			}
+		}
+		combs = append(combs, comb)
+	}
+	return combs, nil
+}
+
+// parseInterfaceLimits parses the nested NL80211_IFACE_COMB_LIMITS attribute
+// of an interface combination.
+func parseInterfaceLimits(b []byte) ([]InterfaceLimit, error) {
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil { return nil, err }
+
+	limits := make([]InterfaceLimit, 0, len(attrs))
+	for _, a := range attrs {
+		lattrs, err := netlink.UnmarshalAttributes(a.Data)
+		if err != nil { return nil, err }
+
+		var limit InterfaceLimit
+		for _, la := range lattrs {
+			switch la.Type {
+			case unix.NL80211_IFACE_LIMIT_MAX:
+				limit.Max = int(nlenc.Uint32(la.Data))
+			case unix.NL80211_IFACE_LIMIT_TYPES:
+				iftypes, err := parseInterfaceTypes(la.Data)
+				if err != nil { return nil, err }
+				limit.Types = iftypes
+			}
+		}
+		limits = append(limits, limit)
+	}
+	return limits, nil
+}
+
+// mergeBands adds bands to the Wiphy. A split dump may describe one band
+// over several messages, so the frequencies and bitrates of a band that is
+// already known are appended to it.
+func (w *Wiphy) mergeBands(bands []Band) {
+	for _, b := range bands {
+		merged := false
+		for i := range w.Bands {
+			if w.Bands[i].Type == b.Type {
+				w.Bands[i].Frequencies = append(w.Bands[i].Frequencies, b.Frequencies...)
+				w.Bands[i].Bitrates = append(w.Bands[i].Bitrates, b.Bitrates...)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			w.Bands = append(w.Bands, b)
+		}
+	}
+}
+
+// parseBands parses the nested NL80211_ATTR_WIPHY_BANDS attribute, in which
+// the type of each band attribute is its BandType.
+func parseBands(b []byte) ([]Band, error) {
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil { return nil, fmt.Errorf("parseBands: %v", err) }
+
+	bands := make([]Band, 0, len(attrs))
+	for _, a := range attrs {
+		band := Band{Type: BandType(a.Type)}
+
+		battrs, err := netlink.UnmarshalAttributes(a.Data)
+		if err != nil { return nil, fmt.Errorf("parseBands: %v", err) }
+
+		for _, ba := range battrs {
+			switch ba.Type {
+			case unix.NL80211_BAND_ATTR_FREQS:
+				freqs, err := parseBandFrequencies(ba.Data)
+				if err != nil { return nil, fmt.Errorf("parseBands: %v", err) }
+				band.Frequencies = freqs
+			case unix.NL80211_BAND_ATTR_RATES:
+				rates, err := parseBitrates(ba.Data)
+				if err != nil { return nil, fmt.Errorf("parseBands: %v", err) }
+				band.Bitrates = rates
+			}
+		}
+		bands = append(bands, band)
+	}
+	return bands, nil
+}
+
+// parseBandFrequencies parses the nested NL80211_BAND_ATTR_FREQS attribute
+// of a band into a list of frequencies.
+func parseBandFrequencies(b []byte) ([]BandFrequency, error) {
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil { return nil, err }
+
+	freqs := make([]BandFrequency, 0, len(attrs))
+	for _, a := range attrs {
+		freq, err := parseBandFrequency(a.Data)
+		if err != nil { return nil, err }
+		freqs = append(freqs, freq)
+	}
+	return freqs, nil
+}
+
+// parseBandFrequency parses the NL80211_FREQUENCY_ATTR_* attributes
+// describing a single frequency.
+func parseBandFrequency(b []byte) (BandFrequency, error) {
+	var freq BandFrequency
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil { return freq, err }
+
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_FREQUENCY_ATTR_FREQ:
+			freq.Frequency = nlenc.Uint32(a.Data)
+		case unix.NL80211_FREQUENCY_ATTR_DISABLED:
+			freq.Disabled = true
+		case unix.NL80211_FREQUENCY_ATTR_NO_IR:
+			freq.NoIR = true
+		case unix.NL80211_FREQUENCY_ATTR_RADAR:
+			freq.Radar = true
+		case unix.NL80211_FREQUENCY_ATTR_MAX_TX_POWER:
+			// Reported in mBm.
+			freq.MaxTxPower = float64(nlenc.Uint32(a.Data)) / 100
+		case unix.NL80211_FREQUENCY_ATTR_DFS_STATE:
+			freq.DFSState = DFSState(nlenc.Uint32(a.Data))
+		case unix.NL80211_FREQUENCY_ATTR_DFS_TIME:
+			freq.DFSTime = time.Duration(nlenc.Uint32(a.Data)) * time.Millisecond
+		case unix.NL80211_FREQUENCY_ATTR_DFS_CAC_TIME:
+			freq.CACTime = time.Duration(nlenc.Uint32(a.Data)) * time.Millisecond
+		}
+	}
+	return freq, nil
+}
+
+// parseBitrates parses the nested NL80211_BAND_ATTR_RATES attribute of a
+// band into a list of bitrates in Mbps.
+func parseBitrates(b []byte) ([]float64, error) {
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil { return nil, err }
+
+	rates := make([]float64, 0, len(attrs))
+	for _, a := range attrs {
+		rattrs, err := netlink.UnmarshalAttributes(a.Data)
+		if err != nil { return nil, err }
+
+		for _, ra := range rattrs {
+			if ra.Type == unix.NL80211_BITRATE_ATTR_RATE {
+				// Reported in units of 100 kbit/s.
+				rates = append(rates, float64(nlenc.Uint32(ra.Data))/10)
+			}
+		}
+	}
+	return rates, nil
+}
@@ -0,0 +1,653 @@
+package wifi_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bryancoxwell/wifi"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// mustMarshalAttributes encodes attrs, failing the test on error.
+func mustMarshalAttributes(t *testing.T, attrs []netlink.Attribute) []byte {
+	t.Helper()
+	b, err := netlink.MarshalAttributes(attrs)
+	if err != nil {
+		t.Fatalf("failed to marshal attributes: %v", err)
+	}
+	return b
+}
+
+// TestParseGetWiphyResponse tests the parsing of a NL80211_CMD_GET_WIPHY
+// response into a Wiphy.
+func TestParseGetWiphyResponse(t *testing.T) {
+	iftypes := mustMarshalAttributes(t, []netlink.Attribute{
+		{Type: unix.NL80211_IFTYPE_STATION},
+		{Type: unix.NL80211_IFTYPE_AP},
+		{Type: unix.NL80211_IFTYPE_MONITOR},
+	})
+	msg := genetlink.Message{
+		Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_ATTR_WIPHY, Data: nlenc.Uint32Bytes(1)},
+			{Type: unix.NL80211_ATTR_WIPHY_NAME, Data: nlenc.Bytes("phy1")},
+			{Type: unix.NL80211_ATTR_SUPPORTED_IFTYPES, Data: iftypes},
+			{Type: unix.NL80211_ATTR_MAX_NUM_SCAN_SSIDS, Data: []byte{20}},
+			{Type: unix.NL80211_ATTR_MAX_SCAN_IE_LEN, Data: nlenc.Uint16Bytes(365)},
+			{Type: unix.NL80211_ATTR_WIPHY_RTS_THRESHOLD, Data: nlenc.Uint32Bytes(0xffffffff)},
+			{Type: unix.NL80211_ATTR_WIPHY_FRAG_THRESHOLD, Data: nlenc.Uint32Bytes(2346)},
+			{Type: unix.NL80211_ATTR_WIPHY_RETRY_SHORT, Data: []byte{7}},
+			{Type: unix.NL80211_ATTR_WIPHY_RETRY_LONG, Data: []byte{4}},
+		}),
+	}
+
+	wiphys, err := wifi.ParseGetWiphyResponse([]genetlink.Message{msg})
+	if err != nil {
+		t.Fatalf("ParseGetWiphyResponse: %v", err)
+	}
+	expected := &wifi.Wiphy{
+		Index: 1,
+		Name:  "phy1",
+		SupportedInterfaceTypes: []wifi.InterfaceType{
+			wifi.InterfaceTypeStation,
+			wifi.InterfaceTypeAP,
+			wifi.InterfaceTypeMonitor,
+		},
+		MaxScanSSIDs:           20,
+		MaxScanIELength:        365,
+		RTSThreshold:           wifi.ThresholdOff,
+		FragmentationThreshold: 2346,
+		RetryShort:             7,
+		RetryLong:              4,
+	}
+	if len(wiphys) != 1 || !reflect.DeepEqual(expected, wiphys[0]) {
+		t.Fatalf("ParseGetWiphyResponse mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", expected, wiphys)
+	}
+
+	if !wiphys[0].SupportsInterfaceType(wifi.InterfaceTypeAP) || wiphys[0].SupportsInterfaceType(wifi.InterfaceTypeMeshPoint) {
+		t.Errorf("SupportsInterfaceType: unexpected result for %v", wiphys[0].SupportedInterfaceTypes)
+	}
+}
+
+// TestParseGetWiphyResponseBands tests the parsing of the nested band,
+// frequency and bitrate attributes of a dual-band device.
+func TestParseGetWiphyResponseBands(t *testing.T) {
+	freq := func(mhz, mbm uint32, flags ...uint16) []byte {
+		attrs := []netlink.Attribute{
+			{Type: unix.NL80211_FREQUENCY_ATTR_FREQ, Data: nlenc.Uint32Bytes(mhz)},
+			{Type: unix.NL80211_FREQUENCY_ATTR_MAX_TX_POWER, Data: nlenc.Uint32Bytes(mbm)},
+		}
+		for _, f := range flags {
+			attrs = append(attrs, netlink.Attribute{Type: f})
+		}
+		return mustMarshalAttributes(t, attrs)
+	}
+	rates := func(rates ...uint32) []byte {
+		attrs := make([]netlink.Attribute, 0, len(rates))
+		for i, r := range rates {
+			attrs = append(attrs, netlink.Attribute{
+				Type: uint16(i),
+				Data: mustMarshalAttributes(t, []netlink.Attribute{
+					{Type: unix.NL80211_BITRATE_ATTR_RATE, Data: nlenc.Uint32Bytes(r)},
+				}),
+			})
+		}
+		return mustMarshalAttributes(t, attrs)
+	}
+	band := func(freqs [][]byte, bitrates []byte) []byte {
+		fattrs := make([]netlink.Attribute, 0, len(freqs))
+		for i, f := range freqs {
+			fattrs = append(fattrs, netlink.Attribute{Type: uint16(i), Data: f})
+		}
+		return mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_BAND_ATTR_FREQS, Data: mustMarshalAttributes(t, fattrs)},
+			{Type: unix.NL80211_BAND_ATTR_RATES, Data: bitrates},
+		})
+	}
+
+	bands := mustMarshalAttributes(t, []netlink.Attribute{
+		{Type: unix.NL80211_BAND_2GHZ, Data: band(
+			[][]byte{
+				freq(2412, 2000),
+				freq(2467, 2000, unix.NL80211_FREQUENCY_ATTR_NO_IR),
+				freq(2484, 2000, unix.NL80211_FREQUENCY_ATTR_DISABLED),
+			},
+			rates(10, 20, 55, 110, 60, 540),
+		)},
+		{Type: unix.NL80211_BAND_5GHZ, Data: band(
+			[][]byte{
+				freq(5180, 2300),
+				freq(5260, 2300, unix.NL80211_FREQUENCY_ATTR_NO_IR, unix.NL80211_FREQUENCY_ATTR_RADAR),
+			},
+			rates(60, 90, 540),
+		)},
+	})
+	msg := genetlink.Message{
+		Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_ATTR_WIPHY, Data: nlenc.Uint32Bytes(0)},
+			{Type: unix.NL80211_ATTR_WIPHY_BANDS, Data: bands},
+		}),
+	}
+
+	wiphys, err := wifi.ParseGetWiphyResponse([]genetlink.Message{msg})
+	if err != nil {
+		t.Fatalf("ParseGetWiphyResponse: %v", err)
+	}
+	expected := []wifi.Band{
+		{
+			Type: wifi.Band2GHz,
+			Frequencies: []wifi.BandFrequency{
+				{Frequency: 2412, MaxTxPower: 20},
+				{Frequency: 2467, NoIR: true, MaxTxPower: 20},
+				{Frequency: 2484, Disabled: true, MaxTxPower: 20},
+			},
+			Bitrates: []float64{1, 2, 5.5, 11, 6, 54},
+		},
+		{
+			Type: wifi.Band5GHz,
+			Frequencies: []wifi.BandFrequency{
+				{Frequency: 5180, MaxTxPower: 23},
+				{Frequency: 5260, NoIR: true, Radar: true, MaxTxPower: 23},
+			},
+			Bitrates: []float64{6, 9, 54},
+		},
+	}
+	if len(wiphys) != 1 || !reflect.DeepEqual(expected, wiphys[0].Bands) {
+		t.Fatalf("Bands mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", expected, wiphys[0].Bands)
+	}
+}
+
+// TestParseGetWiphyResponseSplit tests that the messages of an interleaved
+// split dump are merged per wiphy.
+func TestParseGetWiphyResponseSplit(t *testing.T) {
+	freqs := func(mhz ...uint32) []byte {
+		attrs := make([]netlink.Attribute, 0, len(mhz))
+		for i, f := range mhz {
+			attrs = append(attrs, netlink.Attribute{
+				Type: uint16(i),
+				Data: mustMarshalAttributes(t, []netlink.Attribute{
+					{Type: unix.NL80211_FREQUENCY_ATTR_FREQ, Data: nlenc.Uint32Bytes(f)},
+				}),
+			})
+		}
+		return mustMarshalAttributes(t, attrs)
+	}
+	bands := func(band uint16, mhz ...uint32) []byte {
+		return mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: band, Data: mustMarshalAttributes(t, []netlink.Attribute{
+				{Type: unix.NL80211_BAND_ATTR_FREQS, Data: freqs(mhz...)},
+			})},
+		})
+	}
+	message := func(attrs ...netlink.Attribute) genetlink.Message {
+		return genetlink.Message{Data: mustMarshalAttributes(t, attrs)}
+	}
+
+	msgs := []genetlink.Message{
+		message(
+			netlink.Attribute{Type: unix.NL80211_ATTR_WIPHY, Data: nlenc.Uint32Bytes(0)},
+			netlink.Attribute{Type: unix.NL80211_ATTR_WIPHY_NAME, Data: nlenc.Bytes("phy0")},
+		),
+		message(
+			netlink.Attribute{Type: unix.NL80211_ATTR_WIPHY, Data: nlenc.Uint32Bytes(1)},
+			netlink.Attribute{Type: unix.NL80211_ATTR_WIPHY_NAME, Data: nlenc.Bytes("phy1")},
+			netlink.Attribute{Type: unix.NL80211_ATTR_WIPHY_BANDS, Data: bands(unix.NL80211_BAND_2GHZ, 2412)},
+		),
+		message(
+			netlink.Attribute{Type: unix.NL80211_ATTR_WIPHY, Data: nlenc.Uint32Bytes(0)},
+			netlink.Attribute{Type: unix.NL80211_ATTR_WIPHY_BANDS, Data: bands(unix.NL80211_BAND_5GHZ, 5180, 5200)},
+		),
+		message(
+			netlink.Attribute{Type: unix.NL80211_ATTR_WIPHY, Data: nlenc.Uint32Bytes(0)},
+			netlink.Attribute{Type: unix.NL80211_ATTR_WIPHY_BANDS, Data: bands(unix.NL80211_BAND_5GHZ, 5220)},
+		),
+		message(
+			netlink.Attribute{Type: unix.NL80211_ATTR_WIPHY, Data: nlenc.Uint32Bytes(1)},
+			netlink.Attribute{Type: unix.NL80211_ATTR_MAX_NUM_SCAN_SSIDS, Data: []byte{4}},
+		),
+	}
+
+	wiphys, err := wifi.ParseGetWiphyResponse(msgs)
+	if err != nil {
+		t.Fatalf("ParseGetWiphyResponse: %v", err)
+	}
+	expected := []*wifi.Wiphy{
+		{
+			Index: 0,
+			Name:  "phy0",
+			Bands: []wifi.Band{{
+				Type: wifi.Band5GHz,
+				Frequencies: []wifi.BandFrequency{
+					{Frequency: 5180}, {Frequency: 5200}, {Frequency: 5220},
+				},
+			}},
+		},
+		{
+			Index:        1,
+			Name:         "phy1",
+			MaxScanSSIDs: 4,
+			Bands: []wifi.Band{{
+				Type:        wifi.Band2GHz,
+				Frequencies: []wifi.BandFrequency{{Frequency: 2412}},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(expected, wiphys) {
+		t.Fatalf("ParseGetWiphyResponse mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", expected, wiphys)
+	}
+}
+
+// TestParseGetWiphyResponseInterfaceCombinations tests the parsing of the
+// nested interface combination attributes and their evaluation by
+// SupportsCombination.
+func TestParseGetWiphyResponseInterfaceCombinations(t *testing.T) {
+	iftypes := func(types ...uint16) []byte {
+		attrs := make([]netlink.Attribute, 0, len(types))
+		for _, typ := range types {
+			attrs = append(attrs, netlink.Attribute{Type: typ})
+		}
+		return mustMarshalAttributes(t, attrs)
+	}
+	limit := func(index uint16, max uint32, types ...uint16) netlink.Attribute {
+		return netlink.Attribute{
+			Type: index,
+			Data: mustMarshalAttributes(t, []netlink.Attribute{
+				{Type: unix.NL80211_IFACE_LIMIT_MAX, Data: nlenc.Uint32Bytes(max)},
+				{Type: unix.NL80211_IFACE_LIMIT_TYPES, Data: iftypes(types...)},
+			}),
+		}
+	}
+
+	// A single combination as advertised by iwlwifi: one station, plus
+	// one AP or P2P interface, on up to two channels.
+	combs := mustMarshalAttributes(t, []netlink.Attribute{
+		{Type: 1, Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_IFACE_COMB_LIMITS, Data: mustMarshalAttributes(t, []netlink.Attribute{
+				limit(1, 1, unix.NL80211_IFTYPE_STATION),
+				limit(2, 1, unix.NL80211_IFTYPE_AP, unix.NL80211_IFTYPE_P2P_CLIENT, unix.NL80211_IFTYPE_P2P_GO),
+			})},
+			{Type: unix.NL80211_IFACE_COMB_MAXNUM, Data: nlenc.Uint32Bytes(2)},
+			{Type: unix.NL80211_IFACE_COMB_NUM_CHANNELS, Data: nlenc.Uint32Bytes(2)},
+		})},
+	})
+	msg := genetlink.Message{
+		Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_ATTR_WIPHY, Data: nlenc.Uint32Bytes(0)},
+			{Type: unix.NL80211_ATTR_SUPPORTED_IFTYPES, Data: iftypes(
+				unix.NL80211_IFTYPE_STATION, unix.NL80211_IFTYPE_AP, unix.NL80211_IFTYPE_MONITOR,
+				unix.NL80211_IFTYPE_P2P_CLIENT, unix.NL80211_IFTYPE_P2P_GO, unix.NL80211_IFTYPE_ADHOC,
+			)},
+			{Type: unix.NL80211_ATTR_SOFTWARE_IFTYPES, Data: iftypes(unix.NL80211_IFTYPE_MONITOR)},
+			{Type: unix.NL80211_ATTR_INTERFACE_COMBINATIONS, Data: combs},
+		}),
+	}
+
+	wiphys, err := wifi.ParseGetWiphyResponse([]genetlink.Message{msg})
+	if err != nil {
+		t.Fatalf("ParseGetWiphyResponse: %v", err)
+	}
+	wiphy := wiphys[0]
+
+	expected := []wifi.InterfaceCombination{{
+		Limits: []wifi.InterfaceLimit{
+			{Types: []wifi.InterfaceType{wifi.InterfaceTypeStation}, Max: 1},
+			{Types: []wifi.InterfaceType{wifi.InterfaceTypeAP, wifi.InterfaceTypeP2PClient, wifi.InterfaceTypeP2PGroupOwner}, Max: 1},
+		},
+		MaxInterfaces: 2,
+		NumChannels:   2,
+	}}
+	if !reflect.DeepEqual(expected, wiphy.InterfaceCombinations) {
+		t.Fatalf("InterfaceCombinations mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", expected, wiphy.InterfaceCombinations)
+	}
+
+	tests := []struct {
+		types    []wifi.InterfaceType
+		expected bool
+	}{
+		{[]wifi.InterfaceType{wifi.InterfaceTypeStation}, true},
+		{[]wifi.InterfaceType{wifi.InterfaceTypeStation, wifi.InterfaceTypeAP}, true},
+		{[]wifi.InterfaceType{wifi.InterfaceTypeStation, wifi.InterfaceTypeAP, wifi.InterfaceTypeMonitor}, true},
+		{[]wifi.InterfaceType{wifi.InterfaceTypeStation, wifi.InterfaceTypeStation}, false},
+		{[]wifi.InterfaceType{wifi.InterfaceTypeAP, wifi.InterfaceTypeP2PGroupOwner}, false},
+		{[]wifi.InterfaceType{wifi.InterfaceTypeStation, wifi.InterfaceTypeAdHoc}, false},
+		{[]wifi.InterfaceType{wifi.InterfaceTypeMeshPoint}, false},
+	}
+	for _, tt := range tests {
+		if got := wiphy.SupportsCombination(tt.types...); got != tt.expected {
+			t.Errorf("SupportsCombination(%v) = %v, expected %v", tt.types, got, tt.expected)
+		}
+	}
+}
+
+// TestWiphySecurityCapabilities tests the parsing of the supported cipher
+// suites and extended features, and the validation of connection options
+// against them.
+func TestWiphySecurityCapabilities(t *testing.T) {
+	var ciphers []byte
+	for _, c := range []uint32{0x000fac01, 0x000fac05, 0x000fac02, 0x000fac04} {
+		ciphers = append(ciphers, nlenc.Uint32Bytes(c)...)
+	}
+	// Only NL80211_EXT_FEATURE_4WAY_HANDSHAKE_STA_PSK (15) is set.
+	features := make([]byte, 8)
+	features[1] = 0x80
+
+	msg := genetlink.Message{
+		Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_ATTR_WIPHY, Data: nlenc.Uint32Bytes(0)},
+			{Type: unix.NL80211_ATTR_CIPHER_SUITES, Data: ciphers},
+			{Type: unix.NL80211_ATTR_EXT_FEATURES, Data: features},
+		}),
+	}
+	wiphys, err := wifi.ParseGetWiphyResponse([]genetlink.Message{msg})
+	if err != nil {
+		t.Fatalf("ParseGetWiphyResponse: %v", err)
+	}
+	wiphy := wiphys[0]
+
+	expected := []wifi.CipherSuite{wifi.CipherWEP40, wifi.CipherWEP104, wifi.CipherTKIP, wifi.CipherCCMP}
+	if !reflect.DeepEqual(expected, wiphy.CipherSuites) {
+		t.Fatalf("CipherSuites mismatch.\nExpected: \t%v\nGot:\t\t%v\n", expected, wiphy.CipherSuites)
+	}
+	if !wiphy.SupportsCipher(wifi.CipherCCMP) || wiphy.SupportsCipher(wifi.CipherCCMP256) {
+		t.Errorf("SupportsCipher: unexpected result for %v", wiphy.CipherSuites)
+	}
+	if !wiphy.SupportsPSKOffload() || wiphy.SupportsSAEOffload() || wiphy.SupportsExtendedFeature(1000) {
+		t.Errorf("unexpected extended features %x", wiphy.ExtendedFeatures)
+	}
+
+	tests := []struct {
+		opts wifi.ConnectOptions
+		err  string
+	}{
+		{wifi.ConnectOptions{Security: wifi.SecurityOpen}, ""},
+		{wifi.ConnectOptions{Security: wifi.SecurityPSK}, ""},
+		{wifi.ConnectOptions{Security: wifi.SecurityPSK, PairwiseCiphers: []wifi.CipherSuite{wifi.CipherCCMP256}}, "driver does not support CCMP-256"},
+		{wifi.ConnectOptions{Security: wifi.SecurityWPA3SAE}, "driver does not support SAE offload"},
+	}
+	for _, tt := range tests {
+		err := wiphy.CheckConnectOptions(&tt.opts)
+		if (err == nil && tt.err != "") || (err != nil && err.Error() != tt.err) {
+			t.Errorf("CheckConnectOptions(%+v) = %v, expected %q", tt.opts, err, tt.err)
+		}
+	}
+}
+
+// TestWiphyCheckMonitorFlags tests that active monitoring requires the
+// NL80211_FEATURE_ACTIVE_MONITOR feature flag.
+func TestWiphyCheckMonitorFlags(t *testing.T) {
+	msg := genetlink.Message{
+		Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_ATTR_WIPHY, Data: nlenc.Uint32Bytes(0)},
+			{Type: unix.NL80211_ATTR_FEATURE_FLAGS, Data: nlenc.Uint32Bytes(unix.NL80211_FEATURE_ACTIVE_MONITOR)},
+		}),
+	}
+	wiphys, err := wifi.ParseGetWiphyResponse([]genetlink.Message{msg})
+	if err != nil {
+		t.Fatalf("ParseGetWiphyResponse: %v", err)
+	}
+	active := wiphys[0]
+	passive := &wifi.Wiphy{}
+
+	flags := wifi.MonitorFCSFail | wifi.MonitorOtherBSS
+	if err := passive.CheckMonitorFlags(flags); err != nil {
+		t.Errorf("CheckMonitorFlags(%#x): unexpected error: %v", flags, err)
+	}
+	if err := active.CheckMonitorFlags(flags | wifi.MonitorActive); err != nil {
+		t.Errorf("CheckMonitorFlags(%#x): unexpected error: %v", flags|wifi.MonitorActive, err)
+	}
+	if err := passive.CheckMonitorFlags(flags | wifi.MonitorActive); err == nil {
+		t.Errorf("CheckMonitorFlags(%#x): expected an error without active monitor support", flags|wifi.MonitorActive)
+	}
+}
+
+// TestWiphyCheckChannel tests the validation of channels against the
+// frequencies of a 2.4 GHz only device.
+func TestWiphyCheckChannel(t *testing.T) {
+	wiphy := &wifi.Wiphy{
+		Index: 0,
+		Bands: []wifi.Band{{
+			Type: wifi.Band2GHz,
+			Frequencies: []wifi.BandFrequency{
+				{Frequency: 2412},
+				{Frequency: 2467, NoIR: true},
+				{Frequency: 2484, Disabled: true},
+			},
+		}},
+	}
+
+	tests := []struct {
+		channel int
+		freq    uint32
+		err     string
+	}{
+		{1, 2412, ""},
+		{12, 2467, ""},
+		{14, 2484, "channel 14 is disabled on phy 0"},
+		{149, 5745, "channel 149 not supported on phy 0"},
+	}
+	for _, tt := range tests {
+		err := wiphy.CheckChannel(tt.channel, tt.freq)
+		if (err == nil && tt.err != "") || (err != nil && err.Error() != tt.err) {
+			t.Errorf("CheckChannel(%d) = %v, expected %q", tt.channel, err, tt.err)
+		}
+	}
+
+	if err := wiphy.CheckFrequency(2412); err != nil {
+		t.Errorf("CheckFrequency(2412): %v", err)
+	}
+	expected := "frequency 5955 MHz not supported on phy 0"
+	if err := wiphy.CheckFrequency(5955); err == nil || err.Error() != expected {
+		t.Errorf("CheckFrequency(5955) = %v, expected %q", err, expected)
+	}
+}
+
+// TestWiphyChannelInfo tests the parsing of the DFS attributes of a
+// frequency and their reporting by ChannelInfo.
+func TestWiphyChannelInfo(t *testing.T) {
+	freq := func(attrs ...netlink.Attribute) netlink.Attribute {
+		return netlink.Attribute{Data: mustMarshalAttributes(t, attrs)}
+	}
+	freqs := []netlink.Attribute{
+		freq(
+			netlink.Attribute{Type: unix.NL80211_FREQUENCY_ATTR_FREQ, Data: nlenc.Uint32Bytes(5180)},
+		),
+		freq(
+			netlink.Attribute{Type: unix.NL80211_FREQUENCY_ATTR_FREQ, Data: nlenc.Uint32Bytes(5260)},
+			netlink.Attribute{Type: unix.NL80211_FREQUENCY_ATTR_NO_IR},
+			netlink.Attribute{Type: unix.NL80211_FREQUENCY_ATTR_RADAR},
+			netlink.Attribute{Type: unix.NL80211_FREQUENCY_ATTR_DFS_STATE, Data: nlenc.Uint32Bytes(unix.NL80211_DFS_UNAVAILABLE)},
+			netlink.Attribute{Type: unix.NL80211_FREQUENCY_ATTR_DFS_TIME, Data: nlenc.Uint32Bytes(1500)},
+			netlink.Attribute{Type: unix.NL80211_FREQUENCY_ATTR_DFS_CAC_TIME, Data: nlenc.Uint32Bytes(60000)},
+		),
+		freq(
+			netlink.Attribute{Type: unix.NL80211_FREQUENCY_ATTR_FREQ, Data: nlenc.Uint32Bytes(5500)},
+			netlink.Attribute{Type: unix.NL80211_FREQUENCY_ATTR_RADAR},
+			netlink.Attribute{Type: unix.NL80211_FREQUENCY_ATTR_DFS_STATE, Data: nlenc.Uint32Bytes(unix.NL80211_DFS_AVAILABLE)},
+		),
+	}
+	for i := range freqs {
+		freqs[i].Type = uint16(i)
+	}
+	bands := mustMarshalAttributes(t, []netlink.Attribute{
+		{Type: unix.NL80211_BAND_5GHZ, Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_BAND_ATTR_FREQS, Data: mustMarshalAttributes(t, freqs)},
+		})},
+	})
+	msg := genetlink.Message{
+		Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_ATTR_WIPHY, Data: nlenc.Uint32Bytes(0)},
+			{Type: unix.NL80211_ATTR_WIPHY_BANDS, Data: bands},
+		}),
+	}
+	wiphys, err := wifi.ParseGetWiphyResponse([]genetlink.Message{msg})
+	if err != nil {
+		t.Fatalf("ParseGetWiphyResponse: %v", err)
+	}
+	wiphy := wiphys[0]
+
+	ci, err := wiphy.ChannelInfo(5260)
+	if err != nil {
+		t.Fatalf("ChannelInfo: %v", err)
+	}
+	expected := &wifi.ChannelInfo{
+		BandFrequency: wifi.BandFrequency{
+			Frequency: 5260,
+			NoIR:      true,
+			Radar:     true,
+			DFSState:  wifi.DFSUnavailable,
+			DFSTime:   1500 * time.Millisecond,
+			CACTime:   time.Minute,
+		},
+		Band:    wifi.Band5GHz,
+		Channel: 52,
+	}
+	if !reflect.DeepEqual(expected, ci) {
+		t.Fatalf("ChannelInfo mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", expected, ci)
+	}
+	if !ci.RequiresDFS() || !ci.PassiveOnly() {
+		t.Errorf("unexpected flags for %+v", ci)
+	}
+
+	for freq, dfs := range map[int]bool{5180: false, 5500: false} {
+		ci, err := wiphy.ChannelInfo(freq)
+		if err != nil {
+			t.Fatalf("ChannelInfo(%d): %v", freq, err)
+		}
+		if ci.RequiresDFS() != dfs || ci.PassiveOnly() {
+			t.Errorf("ChannelInfo(%d): unexpected flags %+v", freq, ci)
+		}
+	}
+
+	if _, err := wiphy.ChannelInfo(2412); err == nil {
+		t.Errorf("ChannelInfo(2412): expected error")
+	}
+}
+
+// TestSetFragmentationThresholdInvalid tests that thresholds the kernel
+// would reject are refused before a request is sent.
+func TestSetFragmentationThresholdInvalid(t *testing.T) {
+	if err := (&wifi.Client{}).SetFragmentationThreshold(0, 255); err == nil {
+		t.Error("expected an error for a threshold below 256 bytes")
+	}
+	w := &wifi.WifiInterface{Index: 3, Name: "wlan0", Phy: 1}
+	if err := (&wifi.Client{}).SetInterfaceFragmentationThreshold(w, 255); err == nil {
+		t.Error("expected an error for a threshold below 256 bytes on an interface")
+	}
+}
+
+// TestBandChannels tests the listing of the channels of a band.
+func TestBandChannels(t *testing.T) {
+	band := &wifi.Band{
+		Type: wifi.Band5GHz,
+		Frequencies: []wifi.BandFrequency{
+			{Frequency: 5180},
+			{Frequency: 5260, NoIR: true, Radar: true},
+			{Frequency: 5720, Disabled: true},
+		},
+	}
+
+	var channels []int
+	for _, ci := range band.Channels() {
+		if ci.Band != wifi.Band5GHz {
+			t.Errorf("channel %d: got band %v, expected 5 GHz", ci.Channel, ci.Band)
+		}
+		channels = append(channels, ci.Channel)
+	}
+	if expected := []int{36, 52, 144}; !reflect.DeepEqual(expected, channels) {
+		t.Errorf("Channels: got %v, expected %v", channels, expected)
+	}
+
+	usable := band.UsableChannels()
+	if len(usable) != 2 || !usable[1].RequiresDFS() || !usable[1].PassiveOnly() {
+		t.Errorf("UsableChannels: got %+v", usable)
+	}
+}
+
+// TestParseProtocolFeatures tests finding the protocol features in a
+// NL80211_CMD_GET_PROTOCOL_FEATURES response.
+func TestParseProtocolFeatures(t *testing.T) {
+	msgs := []genetlink.Message{{
+		Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_ATTR_PROTOCOL_FEATURES, Data: nlenc.Uint32Bytes(unix.NL80211_PROTOCOL_FEATURE_SPLIT_WIPHY_DUMP)},
+		}),
+	}}
+	features, err := wifi.ParseProtocolFeatures(msgs)
+	if err != nil {
+		t.Fatalf("ParseProtocolFeatures: %v", err)
+	}
+	if features&unix.NL80211_PROTOCOL_FEATURE_SPLIT_WIPHY_DUMP == 0 {
+		t.Errorf("got features %#x, expected split wiphy dump support", features)
+	}
+
+	if features, err := wifi.ParseProtocolFeatures(nil); err != nil || features != 0 {
+		t.Errorf("got features %#x and error %v for an empty response, expected none", features, err)
+	}
+}
+
+// TestWiphyAntennas tests the parsing of the antenna configuration and the
+// validation of new antenna masks against the available antennas.
+func TestWiphyAntennas(t *testing.T) {
+	msg := genetlink.Message{
+		Data: mustMarshalAttributes(t, []netlink.Attribute{
+			{Type: unix.NL80211_ATTR_WIPHY, Data: nlenc.Uint32Bytes(0)},
+			{Type: unix.NL80211_ATTR_WIPHY_ANTENNA_AVAIL_TX, Data: nlenc.Uint32Bytes(0x3)},
+			{Type: unix.NL80211_ATTR_WIPHY_ANTENNA_AVAIL_RX, Data: nlenc.Uint32Bytes(0x3)},
+			{Type: unix.NL80211_ATTR_WIPHY_ANTENNA_TX, Data: nlenc.Uint32Bytes(0x3)},
+			{Type: unix.NL80211_ATTR_WIPHY_ANTENNA_RX, Data: nlenc.Uint32Bytes(0x1)},
+		}),
+	}
+	wiphys, err := wifi.ParseGetWiphyResponse([]genetlink.Message{msg})
+	if err != nil {
+		t.Fatalf("ParseGetWiphyResponse: %v", err)
+	}
+	wiphy := wiphys[0]
+	if wiphy.AntennaTX != 0x3 || wiphy.AntennaRX != 0x1 || wiphy.AvailableAntennasTX != 0x3 || wiphy.AvailableAntennasRX != 0x3 {
+		t.Errorf("unexpected antennas: %+v", wiphy)
+	}
+
+	if err := wiphy.CheckAntennas(0x1, 0x1); err != nil {
+		t.Errorf("CheckAntennas: unexpected error: %v", err)
+	}
+	if err := wiphy.CheckAntennas(0x4, 0x1); err == nil {
+		t.Error("CheckAntennas: expected an error for an unavailable antenna")
+	}
+	if err := wiphy.CheckAntennas(0x1, 0); err == nil {
+		t.Error("CheckAntennas: expected an error for an empty mask")
+	}
+	if err := (&wifi.Wiphy{}).CheckAntennas(0x1, 0x1); err == nil {
+		t.Error("CheckAntennas: expected an error for a driver without antenna configuration")
+	}
+}
+
+// TestSetRetryLimitsInvalid tests that retry limits the kernel would reject
+// are refused before a request is sent.
+func TestSetRetryLimitsInvalid(t *testing.T) {
+	if err := (&wifi.Client{}).SetRetryLimits(0, 0, 4); err == nil {
+		t.Error("expected an error for a short retry limit of 0")
+	}
+	if err := (&wifi.Client{}).SetRetryLimits(0, 7, 0); err == nil {
+		t.Error("expected an error for a long retry limit of 0")
+	}
+}
+
+// TestRetryLimitsAttrs tests the encoding of the short and long retry
+// limits.
+func TestRetryLimitsAttrs(t *testing.T) {
+	encoders, err := wifi.RetryLimitsAttrs(7, 4)
+	if err != nil {
+		t.Fatalf("RetryLimitsAttrs: %v", err)
+	}
+	attrs := encodeAttributes(t, encoders)
+	if got := attrs[unix.NL80211_ATTR_WIPHY_RETRY_SHORT]; !reflect.DeepEqual(got, []byte{7}) {
+		t.Errorf("got NL80211_ATTR_WIPHY_RETRY_SHORT %v, expected [7]", got)
+	}
+	if got := attrs[unix.NL80211_ATTR_WIPHY_RETRY_LONG]; !reflect.DeepEqual(got, []byte{4}) {
+		t.Errorf("got NL80211_ATTR_WIPHY_RETRY_LONG %v, expected [4]", got)
+	}
+	if len(attrs) != 2 {
+		t.Errorf("got %d attributes, expected 2", len(attrs))
+	}
+}
@@ -0,0 +1,186 @@
+//go:build linux
+// +build linux
+
+package wifi
+
+import (
+	"fmt"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+)
+
+// WoWLANConfig selects the events that wake the system while it sleeps
+// (Wake-on-WLAN). The zero value disables Wake-on-WLAN.
+type WoWLANConfig struct {
+	// Any wakes the system on any activity, for devices that can't
+	// tell triggers apart.
+	Any bool
+
+	// Disconnect wakes the system when the connection is lost.
+	Disconnect bool
+
+	// MagicPacket wakes the system on a magic packet.
+	MagicPacket bool
+
+	// Patterns wake the system on packets matching any of them.
+	Patterns []WoWLANPattern
+}
+
+// A WoWLANPattern matches packets whose bytes from Offset on equal those of
+// Pattern. Mask selects which bytes are compared: bit i, counting from the
+// least significant bit of the first byte, is set to compare byte i of
+// Pattern. It must hold a bit for every byte of Pattern.
+type WoWLANPattern struct {
+	Offset  uint32
+	Pattern []byte
+	Mask    []byte
+}
+
+// enabled reports whether cfg has any trigger set.
+func (cfg *WoWLANConfig) enabled() bool {
+	return cfg.Any || cfg.Disconnect || cfg.MagicPacket || len(cfg.Patterns) > 0
+}
+
+// attributes returns the NL80211_ATTR_WOWLAN_TRIGGERS attribute encoding
+// cfg, or none if it has no trigger set.
+func (cfg *WoWLANConfig) attributes() ([]AttributeEncoder, error) {
+	if !cfg.enabled() { return nil, nil }
+
+	var triggers []AttributeEncoder
+	flags := []struct {
+		typ uint16
+		set bool
+	}{
+		{unix.NL80211_WOWLAN_TRIG_ANY, cfg.Any},
+		{unix.NL80211_WOWLAN_TRIG_DISCONNECT, cfg.Disconnect},
+		{unix.NL80211_WOWLAN_TRIG_MAGIC_PKT, cfg.MagicPacket},
+	}
+	for _, f := range flags {
+		if f.set {
+			triggers = append(triggers, NewAttributeFactory[bool](f.typ)(true))
+		}
+	}
+
+	if len(cfg.Patterns) > 0 {
+		patterns := make([]AttributeEncoder, 0, len(cfg.Patterns))
+		for i, p := range cfg.Patterns {
+			if len(p.Pattern) == 0 || len(p.Mask) != (len(p.Pattern)+7)/8 {
+				return nil, fmt.Errorf("pattern %d: mask of %d bytes doesn't fit a pattern of %d bytes", i, len(p.Mask), len(p.Pattern))
+			}
+			// Patterns are numbered from 1.
+			patterns = append(patterns, NestedAttribute(uint16(i+1),
+				NewAttributeFactory[[]byte](unix.NL80211_PKTPAT_MASK)(p.Mask),
+				NewAttributeFactory[[]byte](unix.NL80211_PKTPAT_PATTERN)(p.Pattern),
+				NewAttributeFactory[uint32](unix.NL80211_PKTPAT_OFFSET)(p.Offset),
+			))
+		}
+		triggers = append(triggers, NestedAttribute(unix.NL80211_WOWLAN_TRIG_PKT_PATTERN, patterns...))
+	}
+	return []AttributeEncoder{NestedAttribute(unix.NL80211_ATTR_WOWLAN_TRIGGERS, triggers...)}, nil
+}
+
+// SetWoWLAN configures Wake-on-WLAN on the wiphy with the given index. A
+// zero cfg disables it.
+func (c *Client) SetWoWLAN(phy int, cfg WoWLANConfig) error {
+	triggers, err := cfg.attributes()
+	if err != nil { return fmt.Errorf("SetWoWLAN: %v", err) }
+
+	attrs := append([]AttributeEncoder{WiphyAttribute(uint32(phy))}, triggers...)
+	msg, err := NewNl80211Message(unix.NL80211_CMD_SET_WOWLAN, attrs)
+	if err != nil { return fmt.Errorf("SetWoWLAN: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request | netlink.Acknowledge,
+	}
+	_, err = request.Response(c)
+	if err != nil { return fmt.Errorf("SetWoWLAN: %w", err) }
+	return nil
+}
+
+// WoWLAN returns the Wake-on-WLAN configuration of the wiphy with the given
+// index.
+func (c *Client) WoWLAN(phy int) (*WoWLANConfig, error) {
+	attrs := []AttributeEncoder{
+		WiphyAttribute(uint32(phy)),
+	}
+	msg, err := NewNl80211Message(unix.NL80211_CMD_GET_WOWLAN, attrs)
+	if err != nil { return nil, fmt.Errorf("WoWLAN: %v", err) }
+
+	request := &Nl80211Request{
+		RequestMessage: msg,
+		Flags: netlink.Request,
+	}
+	response, err := request.Response(c)
+	if err != nil { return nil, fmt.Errorf("WoWLAN: %w", err) }
+
+	cfg, err := parseGetWoWLANResponse(response)
+	if err != nil { return nil, fmt.Errorf("WoWLAN: %v", err) }
+	return cfg, nil
+}
+
+// parseGetWoWLANResponse parses the responses to a NL80211_CMD_GET_WOWLAN
+// request. Without a NL80211_ATTR_WOWLAN_TRIGGERS attribute Wake-on-WLAN is
+// disabled.
+func parseGetWoWLANResponse(msgs []genetlink.Message) (*WoWLANConfig, error) {
+	cfg := &WoWLANConfig{}
+	for _, m := range msgs {
+		attrs, err := netlink.UnmarshalAttributes(m.Data)
+		if err != nil { return nil, err }
+
+		for _, a := range attrs {
+			if a.Type&^unix.NLA_F_NESTED != unix.NL80211_ATTR_WOWLAN_TRIGGERS { continue }
+			if err := cfg.parseTriggers(a.Data); err != nil { return nil, err }
+		}
+	}
+	return cfg, nil
+}
+
+// parseTriggers parses the nested NL80211_ATTR_WOWLAN_TRIGGERS attribute.
+func (cfg *WoWLANConfig) parseTriggers(b []byte) error {
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil { return err }
+
+	for _, a := range attrs {
+		switch a.Type &^ unix.NLA_F_NESTED {
+		case unix.NL80211_WOWLAN_TRIG_ANY:
+			cfg.Any = true
+		case unix.NL80211_WOWLAN_TRIG_DISCONNECT:
+			cfg.Disconnect = true
+		case unix.NL80211_WOWLAN_TRIG_MAGIC_PKT:
+			cfg.MagicPacket = true
+		case unix.NL80211_WOWLAN_TRIG_PKT_PATTERN:
+			patterns, err := netlink.UnmarshalAttributes(a.Data)
+			if err != nil { return err }
+
+			for _, pa := range patterns {
+				p, err := parseWoWLANPattern(pa.Data)
+				if err != nil { return err }
+				cfg.Patterns = append(cfg.Patterns, p)
+			}
+		}
+	}
+	return nil
+}
+
+// parseWoWLANPattern parses a nested NL80211_PKTPAT_* pattern.
+func parseWoWLANPattern(b []byte) (WoWLANPattern, error) {
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil { return WoWLANPattern{}, err }
+
+	var p WoWLANPattern
+	for _, a := range attrs {
+		switch a.Type {
+		case unix.NL80211_PKTPAT_MASK:
+			p.Mask = a.Data
+		case unix.NL80211_PKTPAT_PATTERN:
+			p.Pattern = a.Data
+		case unix.NL80211_PKTPAT_OFFSET:
+			p.Offset = nlenc.Uint32(a.Data)
+		}
+	}
+	return p, nil
+}
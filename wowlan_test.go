@@ -0,0 +1,74 @@
+package wifi_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bryancoxwell/wifi"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+)
+
+// encodeWoWLANResponse encodes cfg into a NL80211_CMD_GET_WOWLAN response.
+func encodeWoWLANResponse(t *testing.T, cfg *wifi.WoWLANConfig) []genetlink.Message {
+	t.Helper()
+	encoders, err := cfg.Attributes()
+	if err != nil {
+		t.Fatalf("Attributes: %v", err)
+	}
+	ae := netlink.NewAttributeEncoder()
+	for _, e := range encoders {
+		e.EncodeAttribute(ae)
+	}
+	b, err := ae.Encode()
+	if err != nil {
+		t.Fatalf("failed to encode attributes: %v", err)
+	}
+	return []genetlink.Message{{Data: b}}
+}
+
+// TestWoWLANRoundTrip tests that Wake-on-WLAN configurations, including
+// packet patterns, survive encoding and parsing.
+func TestWoWLANRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  wifi.WoWLANConfig
+	}{
+		{name: "disabled"},
+		{name: "flags", cfg: wifi.WoWLANConfig{Disconnect: true, MagicPacket: true}},
+		{
+			name: "patterns",
+			cfg: wifi.WoWLANConfig{
+				Any: true,
+				Patterns: []wifi.WoWLANPattern{
+					// Any frame sent to 02:00:00:00:01:00.
+					{Pattern: []byte{0x02, 0x00, 0x00, 0x00, 0x01, 0x00}, Mask: []byte{0x3f}},
+					// UDP port 9, skipping the bytes between.
+					{Offset: 12, Pattern: []byte{0x08, 0x00, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x11}, Mask: []byte{0x03, 0x08}},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := wifi.ParseGetWoWLANResponse(encodeWoWLANResponse(t, &tt.cfg))
+			if err != nil {
+				t.Fatalf("ParseGetWoWLANResponse: %v", err)
+			}
+			if !reflect.DeepEqual(&tt.cfg, got) {
+				t.Errorf("round trip mismatch.\nExpected: \t%+v\nGot:\t\t%+v\n", tt.cfg, *got)
+			}
+		})
+	}
+}
+
+// TestWoWLANInvalidPattern tests that masks that don't cover their pattern
+// are rejected.
+func TestWoWLANInvalidPattern(t *testing.T) {
+	cfg := &wifi.WoWLANConfig{
+		Patterns: []wifi.WoWLANPattern{{Pattern: make([]byte, 9), Mask: []byte{0xff}}},
+	}
+	if _, err := cfg.Attributes(); err == nil {
+		t.Error("expected an error for a mask too short for its pattern")
+	}
+}
@@ -0,0 +1,109 @@
+package wifi
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// WPS attribute types used by parseWPS.
+const (
+	wpsAttrConfigMethods     = 0x1008
+	wpsAttrDeviceName        = 0x1011
+	wpsAttrManufacturer      = 0x1021
+	wpsAttrModelName         = 0x1023
+	wpsAttrModelNumber       = 0x1024
+	wpsAttrSelectedRegistrar = 0x1041
+	wpsAttrSerialNumber      = 0x1042
+	wpsAttrState             = 0x1044
+	wpsAttrVersion           = 0x104a
+)
+
+var errInvalidWPS = errors.New("invalid WPS attribute")
+
+// A WPSState is the Wi-Fi Protected Setup state advertised by an AP.
+type WPSState int
+
+const (
+	WPSStateUnknown       WPSState = 0
+	WPSStateNotConfigured WPSState = 1
+	WPSStateConfigured    WPSState = 2
+)
+
+// String returns the string representation of a WPSState.
+func (s WPSState) String() string {
+	switch s {
+	case WPSStateUnknown:
+		return "unknown"
+	case WPSStateNotConfigured:
+		return "not configured"
+	case WPSStateConfigured:
+		return "configured"
+	default:
+		return fmt.Sprintf("unknown(%d)", s)
+	}
+}
+
+// WPSInfo describes the Wi-Fi Protected Setup attributes advertised in the
+// WPS vendor-specific element (OUI 00:50:F2, type 4).
+type WPSInfo struct {
+	// Version is the raw WPS version byte, for example 0x10 for 1.0.
+	Version int
+
+	State             WPSState
+	SelectedRegistrar bool
+
+	// ConfigMethods is the raw Config Methods bitmask.
+	ConfigMethods uint16
+
+	DeviceName   string
+	Manufacturer string
+	Model        string
+	ModelNumber  string
+	SerialNumber string
+}
+
+// parseWPS parses the attributes of a WPS element. b is the element body
+// following the OUI and type bytes. WPS attributes use a big-endian
+// 2-byte type, 2-byte length format rather than the 802.11 IE format.
+func parseWPS(b []byte) (*WPSInfo, error) {
+	wps := &WPSInfo{}
+	for len(b) > 0 {
+		if len(b) < 4 { return nil, fmt.Errorf("parseWPS: %v", errInvalidWPS) }
+		typ := binary.BigEndian.Uint16(b[0:2])
+		l := int(binary.BigEndian.Uint16(b[2:4]))
+		if len(b[4:]) < l { return nil, fmt.Errorf("parseWPS: %v", errInvalidWPS) }
+		v := b[4 : 4+l]
+		b = b[4+l:]
+
+		switch typ {
+		case wpsAttrVersion:
+			if l == 1 {
+				wps.Version = int(v[0])
+			}
+		case wpsAttrState:
+			if l == 1 {
+				wps.State = WPSState(v[0])
+			}
+		case wpsAttrSelectedRegistrar:
+			if l == 1 {
+				wps.SelectedRegistrar = v[0] != 0
+			}
+		case wpsAttrConfigMethods:
+			if l == 2 {
+				wps.ConfigMethods = binary.BigEndian.Uint16(v)
+			}
+		case wpsAttrDeviceName:
+			wps.DeviceName = string(v)
+		case wpsAttrManufacturer:
+			wps.Manufacturer = string(v)
+		case wpsAttrModelName:
+			wps.Model = string(v)
+		case wpsAttrModelNumber:
+			wps.ModelNumber = string(v)
+		case wpsAttrSerialNumber:
+			wps.SerialNumber = string(v)
+		}
+	}
+	return wps, nil
+}